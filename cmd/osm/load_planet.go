@@ -0,0 +1,58 @@
+// +build !js
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+import (
+	"github.com/colinmarc/hdfs"
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+import (
+	"github.com/spatialcurrent/go-osm/osm"
+)
+
+// load_planet reads every input of the config into the planet, in order, closing each input after it is read.
+// Connects to S3 and the HDFS name nodes used by the config first.  Exits the process on error.
+func load_planet(config *osm.Config, read_buffer_size int, planet *osm.Planet) {
+
+	var s3_client *s3.S3
+	if config.HasResourceType("s3") {
+		s3_client = s3.New(connect_to_aws(os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), os.Getenv("AWS_SESSION_TOKEN"), os.Getenv("AWS_DEFAULT_REGION")))
+	}
+
+	hdfs_clients := map[string]*hdfs.Client{}
+	for _, nameNode := range config.GetNameNodes() {
+		hdfs_client, err := hdfs.New(nameNode)
+		if err != nil {
+			fmt.Println(errors.Wrap(err, "Could not connect to HDFS name node with domain "+nameNode+"."))
+			os.Exit(1)
+		}
+		hdfs_clients[nameNode] = hdfs_client
+	}
+
+	for _, input := range config.Inputs {
+		err := input.Open(read_buffer_size, s3_client, hdfs_clients)
+		if err != nil {
+			fmt.Println(errors.Wrap(err, "Error opening input file at "+input.Uri))
+			os.Exit(1)
+		}
+		err = osm.UnmarshalPlanet(planet, input, nil)
+		if err != nil {
+			fmt.Println(errors.Wrap(err, "Error importing data from planet file at "+input.Uri))
+			os.Exit(1)
+		}
+		err = input.Close()
+		if err != nil {
+			fmt.Println("Error closing input at uri " + input.Uri)
+			os.Exit(1)
+		}
+	}
+}
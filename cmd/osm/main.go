@@ -26,6 +26,7 @@ import (
 	"github.com/pkg/errors"
 	//"gopkg.in/ini.v1"
 	"github.com/colinmarc/hdfs"
+	_ "github.com/mattn/go-sqlite3"
 )
 
 import (
@@ -54,8 +55,6 @@ var GO_OSM_VERSION = "0.0.3"
 var XML_PRETTY_PREFIX = ""
 var XML_PRETTY_INDENT = "    "
 
-var GDAL_INI_KEYS = []string{"osm_version", "osm_changeset", "osm_timestamp", "osm_id", "osm_user", "osm_attributes"}
-
 type Message struct {
 	Message string
 	Fields  map[string]interface{}
@@ -72,8 +71,43 @@ func connect_to_aws(aws_access_key_id string, aws_secret_access_key string, aws_
 	return aws_session
 }
 
+func parse_change_configs(change_uri_text string, change_uri_separator string, drop_nodes bool, drop_ways bool, drop_relations bool, filter *osm.Filter) []osm.InputConfig {
+	change_configs := make([]osm.InputConfig, 0)
+	if len(change_uri_text) == 0 {
+		return change_configs
+	}
+	change_uris := []string{change_uri_text}
+	if len(change_uri_separator) > 0 {
+		change_uris = strings.Split(change_uri_text, change_uri_separator)
+	}
+	for _, change_uri := range change_uris {
+		change_configs = append(change_configs, osm.NewInputConfig(change_uri, drop_nodes, drop_ways, drop_relations, filter))
+	}
+	return change_configs
+}
+
 func main() {
 
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		run_diff(os.Args[2:])
+		os.Exit(0)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		run_serve(os.Args[2:])
+		os.Exit(0)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "graph" {
+		run_graph(os.Args[2:])
+		os.Exit(0)
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "route" {
+		run_route(os.Args[2:])
+		os.Exit(0)
+	}
+
 	runtime.GOMAXPROCS(runtime.NumCPU())
 
 	start := time.Now()
@@ -88,6 +122,9 @@ func main() {
 
 	var input_uri_text string
 	var input_uri_separator string
+	var change_uri_text string
+	var snapshot_at_text string
+	var merge_policy string
 
 	var gdal_ini_uri string
 	var gdal_ini_section string
@@ -101,6 +138,8 @@ func main() {
 	var ways_to_nodes bool
 
 	var bbox_text string
+	var boundary_uri string
+	var extract_strategy string
 
 	// ---------------------------------------------------------
 	// Output flags
@@ -120,6 +159,16 @@ func main() {
 	var drop_author bool
 	var output_keys_keep_text string
 	var output_keys_drop_text string
+	var relation_types_text string
+	var min_zoom int
+	var max_zoom int
+	var tile_layer string
+	var tile_buffer int
+	var simplify float64
+	var csv_columns_text string
+	var sql_srid int
+	var sql_tags string
+	var sql_prefix string
 	// ---------------------------------------------------------
 
 	var summarize bool
@@ -127,6 +176,8 @@ func main() {
 
 	var pretty bool
 
+	var stream bool
+
 	var read_buffer_size int
 
 	var profile bool
@@ -147,9 +198,14 @@ func main() {
 	// Input Flags
 	flag.StringVar(&input_uri_text, "input_uri", "", "A single or separated list of input uris.  Supports wildcards.  \"stdin\" or uri to input file.")
 	flag.StringVar(&input_uri_separator, "input_uri_separator", "", "Separator for splitting input_uri into multiple, e.g., :.  By default nothing.")
+	flag.StringVar(&change_uri_text, "change_uri", "", "A single or separated list of uris to osmChange (.osc, .osc.gz, .osc.bz2) or .o5c files applied in order to the planet after the inputs are read.  Created and modified elements are filtered like the inputs.  Split with input_uri_separator.")
+
+	flag.StringVar(&snapshot_at_text, "snapshot_at", "", "Read the inputs as they existed at this RFC 3339 timestamp, e.g., 2018-01-01T00:00:00Z.  Requires full-history inputs (.osh, .osh.gz, .osh.bz2, .osh.pbf) with timestamps.")
 
-	flag.StringVar(&gdal_ini_uri, "gdal_ini_uri", "", "Uri to GDAL ini file for convience.  See http://www.gdal.org/drv_osm.html.")
-	flag.StringVar(&gdal_ini_section, "gdal_ini_section", "points", "Section to parse in GDAL in file.  See http://www.gdal.org/drv_osm.html.")
+	flag.StringVar(&merge_policy, "merge_policy", "", "Policy for resolving elements present in multiple inputs, e.g., overlapping extracts: "+strings.Join(osm.MERGE_POLICIES, ", ")+".  Defaults to first.")
+
+	flag.StringVar(&gdal_ini_uri, "gdal_ini_uri", "", "Uri to a GDAL osmconf.ini file.  Writes the outputs as the layers of the GDAL OSM driver, the same as ogr2ogr.  See https://gdal.org/drivers/vector/osm.html.")
+	flag.StringVar(&gdal_ini_section, "gdal_ini_section", "", "Comma-separated list of GDAL layers to write: points, lines, multipolygons, multilinestrings, other_relations.  Defaults to every layer if the output uri contains {layer}, otherwise points.  Uses the osmconf.ini of GDAL if -gdal_ini_uri is not set.")
 
 	// Filter Flags
 	flag.StringVar(&filter_keys_keep_text, "filter_keys_keep", "", "Only keep nodes or ways that have a key in the provided comma-separated list of keys")
@@ -159,6 +215,8 @@ func main() {
 	flag.StringVar(&filter_dfl_exp_text, "filter_dfl_exp", "", "DFL filter expression")
 
 	flag.StringVar(&bbox_text, "bbox", "", "Filter by bounding box (minx,miny,maxx,maxy)")
+	flag.StringVar(&boundary_uri, "boundary_uri", "", "Clip to the polygon in an Osmosis polygon filter file (.poly) or a GeoJSON Polygon or MultiPolygon file (.geojson, .json).")
+	flag.StringVar(&extract_strategy, "extract_strategy", osm.EXTRACT_COMPLETE_WAYS, "Strategy for ways and relations crossing the bbox or boundary: "+strings.Join(osm.EXTRACT_STRATEGIES, ", ")+".  simple keeps only the nodes inside, complete_ways keeps every node of crossing ways, and smart also completes multipolygon relations.")
 
 	flag.BoolVar(&ways_to_nodes, "ways_to_nodes", false, "Convert ways into nodes for output")
 
@@ -178,13 +236,25 @@ func main() {
 	// Output Flags
 	flag.StringVar(&output_uri_text, "output_uri", "", "A single or colon-separated list of uutput uris. \"stdout\", \"stderr\", or uri to output file.")
 	flag.StringVar(&output_uri_separator, "output_uri_separator", "", "Separator for splitting output_uri into multiple, e.g., :.  By default nothing.")
-	flag.StringVar(&output_format, "output_format", "osm", "The output format: osm, geojson, geojsonl")
+	flag.StringVar(&output_format, "output_format", "osm", "The output format: osm, osmjson, geojson, geojsonl, csv, tsv, parquet, sql")
 	flag.StringVar(&output_keys_keep_text, "output_keys_keep", "", "Comma-separated list of tag keys to keep in output.  Drop all other keys.")
 	flag.StringVar(&output_keys_drop_text, "output_keys_drop", "", "Comma-separated list of keys to drop in output.  Keep everything else.")
+	flag.StringVar(&relation_types_text, "relation_types", strings.Join(osm.DEFAULT_RELATION_TYPES, ","), "Comma-separated list of the types of relations converted into features for the geojson and geojsonl output formats: multipolygon, boundary, route, route_master")
+
+	flag.IntVar(&min_zoom, "min_zoom", 0, "Minimum zoom level of vector tiles written to .mbtiles or {z}/{x}/{y}.pbf outputs")
+	flag.IntVar(&max_zoom, "max_zoom", 14, "Maximum zoom level of vector tiles written to .mbtiles or {z}/{x}/{y}.pbf outputs")
+	flag.StringVar(&tile_layer, "tile_layer", osm.DEFAULT_TILE_LAYER, "Name of the layer in vector tiles")
+	flag.IntVar(&tile_buffer, "tile_buffer", 64, "Pixels around each vector tile that lines and polygons are clipped to, out of an extent of 4096")
+	flag.Float64Var(&simplify, "simplify", 1.0, "Tolerance in pixels for simplifying lines and polygons in vector tiles at each zoom level, out of an extent of 4096.  0 disables simplification.")
+	flag.StringVar(&csv_columns_text, "csv_columns", strings.Join(osm.DEFAULT_CSV_COLUMNS, ","), "Comma-separated list of the columns of csv and tsv outputs: id, type, lon, lat, wkt, other_tags, or a tag key.  lon and lat are the centroid of ways and relations.  Prefix a tag key with tag: if it is the same as a column, e.g., tag:type.")
+	flag.IntVar(&sql_srid, "sql_srid", osm.DEFAULT_SQL_SRID, "SRID of the geometries of sql outputs: 4326 or 3857")
+	flag.StringVar(&sql_tags, "sql_tags", osm.DEFAULT_SQL_TAGS, "Type of the tags column of sql outputs: hstore or jsonb")
+	flag.StringVar(&sql_prefix, "sql_prefix", osm.DEFAULT_SQL_PREFIX, "Prefix of the names of the tables of sql outputs, e.g., osm for osm_nodes, osm_ways, and osm_relations")
 
 	flag.BoolVar(&summarize, "summarize", false, "Print data summary to stdout (bounding box, number of nodes, number of ways, and number of relations)")
 	flag.StringVar(&summarize_keys_text, "summarize_keys", "", "Comma-separated list of keys to summarize")
 	flag.BoolVar(&pretty, "pretty", false, "Pretty output.  Adds indents.")
+	flag.BoolVar(&stream, "stream", false, "Stream elements from the inputs directly to the outputs without loading the planet into memory.  Only supports osm, osm.pbf, o5m, o5c, and osm.json outputs that need no lookups across elements, e.g., node-only filters, attribute drops, and tag key pruning.")
 
 	flag.IntVar(&read_buffer_size, "read_buffer_size", 4096, "Size of buffer when reading files from disk")
 
@@ -216,9 +286,13 @@ func main() {
 
 	if help {
 		fmt.Println("Usage: osm -input_uri INPUT[:INPUT_2][:INPUT_3] -output_uri OUTPUT [-verbose] [-dry_run] [-version] [-help] [A=1] [B=2]")
+		fmt.Println("       osm diff -old_uri OLD -new_uri NEW [-output_uri OUTPUT] [-summarize]")
+		fmt.Println("       osm serve -input_uri INPUT [-addr :8080]")
+		fmt.Println("       osm graph -input_uri INPUT -output_uri OUTPUT [-vertices_uri VERTICES] [-dfl FILTER]")
+		fmt.Println("       osm route -input_uri INPUT -from lon,lat -to lon,lat [-profile car|bike|foot]")
 		fmt.Println("Supported Schemes: " + strings.Join(osm.SUPPORTED_SCHEMES, ", "))
-		fmt.Println("Supported Input File Extensions: .osm, .osm.gz, .osm.bz2")
-		fmt.Println("Supported Output File Extensions: .osm, .osm.gz, .geojson, .geojsonl, .geojson.gz, .geojsonl.gz")
+		fmt.Println("Supported Input File Extensions: .osm, .osm.gz, .osm.bz2, .osm.pbf, .o5m, .o5c, .osc, .osc.gz, .osc.bz2, .osh, .osh.gz, .osh.bz2, .osh.pbf, .osm.json, .osm.json.gz, .geojson, .geojson.gz, .geojsonl, .geojsonl.gz")
+		fmt.Println("Supported Output File Extensions: .osm, .osm.gz, .osm.pbf, .o5m, .o5c, .osm.json, .osm.json.gz, .geojson, .geojsonl, .geojson.gz, .geojsonl.gz, .csv, .csv.gz, .tsv, .tsv.gz, .parquet, .sql, .sql.gz, .mbtiles, {z}/{x}/{y}.pbf")
 		fmt.Println("Options:")
 		flag.PrintDefaults()
 		os.Exit(0)
@@ -259,6 +333,42 @@ func main() {
 		os.Exit(1)
 	}
 
+	if stream && summarize {
+		fmt.Println("-stream and -summarize are mutually exclusive")
+		os.Exit(1)
+	}
+
+	if stream && len(change_uri_text) > 0 {
+		fmt.Println("-stream and -change_uri are mutually exclusive")
+		os.Exit(1)
+	}
+
+	if stream && len(snapshot_at_text) > 0 {
+		fmt.Println("-stream and -snapshot_at are mutually exclusive")
+		os.Exit(1)
+	}
+
+	if len(snapshot_at_text) > 0 {
+		_, err := time.Parse(time.RFC3339, snapshot_at_text)
+		if err != nil {
+			fmt.Println("Invalid snapshot_at " + snapshot_at_text + ".  Expecting RFC 3339 format, e.g., 2018-01-01T00:00:00Z.")
+			os.Exit(1)
+		}
+	}
+
+	if stream && output_format != "osm" && output_format != "osmjson" {
+		fmt.Println("-stream only supports the osm and osmjson output formats")
+		os.Exit(1)
+	}
+
+	relation_types := osm.ParseSliceString(relation_types_text)
+	for _, t := range relation_types {
+		if t != "multipolygon" && t != "boundary" && t != "route" && t != "route_master" {
+			fmt.Println("Relation type " + t + " is not supported by -relation_types")
+			os.Exit(1)
+		}
+	}
+
 	drop := osm.ParseSliceString(drop_text)
 	drop_nodes = drop_nodes || stringSliceContains(drop, "nodes")
 	drop_ways = drop_ways || stringSliceContains(drop, "ways")
@@ -315,12 +425,12 @@ func main() {
 						os.Exit(1)
 					}
 					for _, input_path := range input_paths {
-						input_filter := osm.NewFilter(filter_keys_keep, filter_keys_drop, "", true, []float64{})
+						input_filter := osm.NewFilter(filter_keys_keep, filter_keys_drop, "", true, []float64{}, "", "")
 						input_config := osm.NewInputConfig(input_path, drop_nodes, drop_ways, drop_relations, input_filter)
 						input_configs = append(input_configs, input_config)
 					}
 				} else {
-					input_filter := osm.NewFilter(filter_keys_keep, filter_keys_drop, "", true, []float64{})
+					input_filter := osm.NewFilter(filter_keys_keep, filter_keys_drop, "", true, []float64{}, "", "")
 					input_config := osm.NewInputConfig(input_uri, drop_nodes, drop_ways, drop_relations, input_filter)
 					input_configs = append(input_configs, input_config)
 				}
@@ -328,6 +438,11 @@ func main() {
 			c.InputConfigs = input_configs
 		}
 
+		if len(change_uri_text) > 0 {
+			change_filter := osm.NewFilter(filter_keys_keep, filter_keys_drop, "", true, []float64{}, "", "")
+			c.ChangeConfigs = parse_change_configs(change_uri_text, input_uri_separator, drop_nodes, drop_ways, drop_relations, change_filter)
+		}
+
 		config = c
 
 	} else {
@@ -343,7 +458,7 @@ func main() {
 			os.Exit(1)
 		}
 
-		input_filter := osm.NewFilter(filter_keys_keep, filter_keys_drop, filter_dfl_exp_text, filter_dfl_use_cache, bbox)
+		input_filter := osm.NewFilter(filter_keys_keep, filter_keys_drop, filter_dfl_exp_text, filter_dfl_use_cache, bbox, boundary_uri, extract_strategy)
 
 		input_configs := make([]osm.InputConfig, 0)
 		if len(input_uri_text) > 0 {
@@ -370,7 +485,7 @@ func main() {
 						os.Exit(1)
 					}
 					for _, input_path := range input_paths {
-						input_filter := osm.NewFilter(filter_keys_keep, filter_keys_drop, "", true, []float64{})
+						input_filter := osm.NewFilter(filter_keys_keep, filter_keys_drop, filter_dfl_exp_text, filter_dfl_use_cache, bbox, boundary_uri, extract_strategy)
 						input_config := osm.NewInputConfig(input_path, drop_nodes, drop_ways, drop_relations, input_filter)
 						input_configs = append(input_configs, input_config)
 					}
@@ -394,8 +509,8 @@ func main() {
 				output_configs = append(output_configs, osm.NewOutputConfig(
 					output_uri,
 					input_filter,
-					drop_ways,
 					drop_nodes,
+					drop_ways,
 					drop_relations,
 					drop_version,
 					drop_changeset,
@@ -404,36 +519,38 @@ func main() {
 					drop_user,
 					ways_to_nodes,
 					pretty,
+					relation_types,
 				))
 			}
 		}
 
-		if len(gdal_ini_uri) > 0 {
-			gdal_ini, err := osm.LoadIniSection(gdal_ini_uri, gdal_ini_section, GDAL_INI_KEYS)
-			if err != nil {
-				fmt.Println(gdal_ini)
-				os.Exit(1)
-			}
-			for _, outputConfig := range output_configs {
-				outputConfig.DropVersion = !osm.ParseBool(gdal_ini["osm_version"])
-				outputConfig.DropChangeset = !osm.ParseBool(gdal_ini["osm_changeset"])
-				outputConfig.DropTimestamp = !osm.ParseBool(gdal_ini["osm_timestamp"])
-				outputConfig.DropUserId = !osm.ParseBool(gdal_ini["osm_uid"])
-				outputConfig.DropUserName = !osm.ParseBool(gdal_ini["osm_user"])
-				outputConfig.KeysToKeep = osm.ParseSliceString(gdal_ini["attributes"])
+		for i := range output_configs {
+			output_configs[i].MinZoom = min_zoom
+			output_configs[i].MaxZoom = max_zoom
+			output_configs[i].Layer = tile_layer
+			output_configs[i].TileBuffer = tile_buffer
+			output_configs[i].Simplify = simplify
+			output_configs[i].Columns = osm.ParseSliceString(csv_columns_text)
+			output_configs[i].GDALIniUri = gdal_ini_uri
+			output_configs[i].GDALLayers = osm.ParseSliceString(gdal_ini_section)
+			output_configs[i].SQLSrid = sql_srid
+			output_configs[i].SQLTags = sql_tags
+			output_configs[i].SQLPrefix = sql_prefix
+			if output_format == "osmjson" || output_format == "csv" || output_format == "tsv" || output_format == "parquet" || output_format == "sql" || output_format == "geojson" || output_format == "geojsonl" {
+				output_configs[i].Format = output_format
 			}
 		}
 
-		for _, outputConfig := range output_configs {
+		for i := range output_configs {
 			// Parse Output Flags
 			if len(output_keys_keep_text) > 0 {
-				outputConfig.KeysToKeep = osm.ParseSliceString(output_keys_keep_text)
+				output_configs[i].KeysToKeep = osm.ParseSliceString(output_keys_keep_text)
 			}
 			if len(output_keys_drop_text) > 0 {
-				outputConfig.KeysToDrop = osm.ParseSliceString(output_keys_drop_text)
+				output_configs[i].KeysToDrop = osm.ParseSliceString(output_keys_drop_text)
 			}
 
-			if len(outputConfig.KeysToKeep) > 0 && len(outputConfig.KeysToDrop) > 0 {
+			if len(output_configs[i].KeysToKeep) > 0 && len(output_configs[i].KeysToDrop) > 0 {
 				fmt.Println("-output_keys_keep (" + output_keys_keep_text + ") and -output_keys_drop (" + output_keys_drop_text + ") are mutually exclusive")
 				os.Exit(1)
 			}
@@ -441,11 +558,22 @@ func main() {
 
 		config = &osm.Config{
 			InputConfigs:  input_configs,
+			ChangeConfigs: parse_change_configs(change_uri_text, input_uri_separator, drop_nodes, drop_ways, drop_relations, input_filter),
 			OutputConfigs: output_configs,
 		}
 
 	}
 
+	if len(merge_policy) > 0 {
+		config.MergePolicy = merge_policy
+	}
+
+	if len(snapshot_at_text) > 0 {
+		for i := range config.InputConfigs {
+			config.InputConfigs[i].SnapshotAt = snapshot_at_text
+		}
+	}
+
 	logger, err := compositelogger.NewDefaultLogger()
 	if err != nil {
 		fmt.Println("Error initializing composite logger.")
@@ -468,11 +596,13 @@ func main() {
 
 		logger.InfoWithFields("Config", map[string]interface{}{
 			"Inputs":           len(config.Inputs),
+			"Changes":          len(config.Changes),
 			"Outputs":          len(config.Outputs),
 			"AllWaysToNodes":   config.ConvertAllWaysToNodes,
 			"DropAllNodes":     config.DropAllNodes,
 			"DropAllWays":      config.DropAllWays,
 			"DropAllRelations": config.DropAllRelations,
+			"MergePolicy":      config.MergePolicy,
 		})
 
 		for i, input := range config.Inputs {
@@ -537,6 +667,25 @@ func main() {
 		}
 	}
 
+	for _, change := range config.Changes {
+
+		switch change.GetType() {
+		case "file":
+			change.Exists = change.FileExists()
+		case "web":
+			change.Exists = change.UrlExists()
+		case "hdfs":
+			_, err := hdfs_clients[change.NameNode].Stat(change.Path)
+			change.Exists = !os.IsNotExist(err)
+		case "s3":
+			change.Exists = s3util.ObjectExists(s3_client, change.Bucket, change.Key)
+		}
+
+		if !change.Exists {
+			fmt.Println("Change at uri " + change.Uri + " does not exist.")
+		}
+	}
+
 	for _, output := range config.Outputs {
 
 		switch output.GetType() {
@@ -601,7 +750,10 @@ func main() {
 	}
 
 	for _, output := range config.Outputs {
-		if output.IsType("file") && !output.Exists {
+		if output.IsType("file") && output.IsTileset() && !strings.HasSuffix(output.PathExpanded, ".mbtiles") {
+			// The directories of a tile tree are created as each tile is written.
+			continue
+		} else if output.IsType("file") && !output.Exists {
 			basepath := filepath.Dir(output.PathExpanded)
 			if _, err := os.Stat(basepath); os.IsNotExist(err) {
 				if verbose {
@@ -636,6 +788,49 @@ func main() {
 		}
 	}
 
+	if stream {
+
+		for _, input := range config.Inputs {
+			for _, output := range config.Outputs {
+				err := osm.ValidateStream(input, output)
+				if err != nil {
+					fmt.Println(err)
+					os.Exit(1)
+				}
+			}
+		}
+
+		for _, input := range config.Inputs {
+			err := input.Open(read_buffer_size, s3_client, hdfs_clients)
+			if err != nil {
+				fmt.Println(errors.Wrap(err, "Error opening input file at "+input.Uri))
+				os.Exit(1)
+			}
+		}
+
+		start_stream := time.Now()
+
+		err = osm.StreamPlanet(config.Inputs, config.Outputs, logger)
+		if err != nil {
+			logger.Warn(errors.Wrap(err, "Error streaming elements"))
+			os.Exit(1)
+		}
+
+		for _, input := range config.Inputs {
+			err := input.Close()
+			if err != nil {
+				fmt.Println("Error closing input at uri " + input.Uri)
+				os.Exit(1)
+			}
+		}
+
+		if profile {
+			logger.InfoWithFields("Finished streaming elements", map[string]interface{}{"duration": time.Since(start_stream).String()})
+		}
+
+		os.Exit(0)
+	}
+
 	planet := osm.NewPlanet()
 
 	err = planet.Init()
@@ -668,6 +863,7 @@ func main() {
 		}
 
 		start_unmarshal := time.Now()
+		conflicts := len(planet.Conflicts)
 
 		err = osm.UnmarshalPlanet(
 			planet,
@@ -684,11 +880,53 @@ func main() {
 			os.Exit(1)
 		}
 
+		if len(planet.Conflicts) > conflicts {
+			logger.Warn("Resolved " + strconv.Itoa(len(planet.Conflicts)-conflicts) + " duplicate elements from input " + input.Uri + " with merge policy " + config.MergePolicy + ".")
+			if verbose {
+				for _, c := range planet.Conflicts[conflicts:] {
+					logger.Info(c.String())
+				}
+			}
+		}
+
 		if profile {
 			logger.InfoWithFields("Finished importing data from planet file", map[string]interface{}{"uri": input.Uri, "duration": time.Since(start_unmarshal).String()})
 		}
 	}
 
+	for i, change := range config.Changes {
+
+		start_change := time.Now()
+
+		err := change.Open(read_buffer_size, s3_client, hdfs_clients)
+		if err != nil {
+			fmt.Println(errors.Wrap(err, "Error opening change file at "+change.Uri))
+			os.Exit(1)
+		}
+
+		c, err := osm.UnmarshalChange(change)
+		if err != nil {
+			logger.Warn(errors.Wrap(err, "Error reading change file at "+change.Uri))
+			os.Exit(1)
+		}
+
+		err = change.Close()
+		if err != nil {
+			fmt.Println("Error closing change at uri " + change.Uri)
+			os.Exit(1)
+		}
+
+		err = planet.ApplyChange(change, c)
+		if err != nil {
+			logger.Warn(errors.Wrap(err, "Error applying change file at "+change.Uri))
+			os.Exit(1)
+		}
+
+		if profile {
+			logger.InfoWithFields("Applied change "+strconv.Itoa(i), map[string]interface{}{"uri": change.Uri, "duration": time.Since(start_change).String()})
+		}
+	}
+
 	if summarize {
 		start_summarize := time.Now()
 		summary := planet.Summarize(summarize_keys)
@@ -704,7 +942,7 @@ func main() {
 		for msg := range ch {
 			switch msg.(type) {
 			case error:
-				logger.Warn(err)
+				logger.Warn(msg)
 			case Message:
 				logger.InfoWithFields(msg.(Message).Message, msg.(Message).Fields)
 			default:
@@ -720,31 +958,91 @@ func main() {
 
 			start_marshal := time.Now()
 
-			if len(output_format) == 0 {
-				if strings.HasSuffix(output.Path, ".geojson.gz") || strings.HasSuffix(output.Path, ".geojson") {
-					output_format = "geojson"
-				} else if strings.HasSuffix(output.Path, ".geojsonl.gz") || strings.HasSuffix(output.Path, ".geojsonl") {
-					output_format = "geojsonl"
-				} else {
-					output_format = "osm"
-				}
+			// The format is inferred from the extension of the output, unless the output sets it.
+			format := output.Format
+			if len(format) == 0 {
+				format = osm.InferFormat(output.Uri)
 			}
 
-			if output_format == "osm" {
-				err := osm.MarshalPlanet(output, config, planet)
+			if output.IsGDAL() {
+				incomplete_ways, incomplete, err := osm.MarshalGDAL(output, planet)
+				for _, iw := range incomplete_ways {
+					ch <- errors.Wrap(iw, "Output "+strconv.Itoa(output_id)+" | Skipped way")
+				}
+				for _, ir := range incomplete {
+					ch <- errors.Wrap(ir, "Output "+strconv.Itoa(output_id)+" | Skipped relation")
+				}
 				if err != nil {
-					ch <- errors.Wrap(err, "Output "+strconv.Itoa(output_id)+" | Error marshalling to "+output.Uri)
+					ch <- errors.Wrap(err, "Output "+strconv.Itoa(output_id)+" | Error writing GDAL layers to "+output.Uri)
+					wg.Done()
+					return
+				}
+			} else if output.IsTileset() {
+				incomplete_ways, incomplete, err := osm.MarshalTiles(output, planet)
+				for _, iw := range incomplete_ways {
+					ch <- errors.Wrap(iw, "Output "+strconv.Itoa(output_id)+" | Skipped way")
+				}
+				for _, ir := range incomplete {
+					ch <- errors.Wrap(ir, "Output "+strconv.Itoa(output_id)+" | Skipped relation")
+				}
+				if err != nil {
+					ch <- errors.Wrap(err, "Output "+strconv.Itoa(output_id)+" | Error writing tiles to "+output.Uri)
 					wg.Done()
 					return
 				}
-			} else if output_format == "geojson" {
+			} else if output.IsTable() {
+				incomplete_ways, incomplete, err := osm.MarshalCSV(output, planet)
+				for _, iw := range incomplete_ways {
+					ch <- errors.Wrap(iw, "Output "+strconv.Itoa(output_id)+" | Skipped way")
+				}
+				for _, ir := range incomplete {
+					ch <- errors.Wrap(ir, "Output "+strconv.Itoa(output_id)+" | Skipped relation")
+				}
+				if err != nil {
+					ch <- errors.Wrap(err, "Output "+strconv.Itoa(output_id)+" | Error writing table to "+output.Uri)
+					wg.Done()
+					return
+				}
+			} else if output.IsParquet() {
+				incomplete_ways, incomplete, err := osm.MarshalParquet(output, planet)
+				for _, iw := range incomplete_ways {
+					ch <- errors.Wrap(iw, "Output "+strconv.Itoa(output_id)+" | Way without geometry")
+				}
+				for _, ir := range incomplete {
+					ch <- errors.Wrap(ir, "Output "+strconv.Itoa(output_id)+" | Relation without geometry")
+				}
+				if err != nil {
+					ch <- errors.Wrap(err, "Output "+strconv.Itoa(output_id)+" | Error writing parquet to "+output.Uri)
+					wg.Done()
+					return
+				}
+			} else if output.IsSQL() {
+				incomplete_ways, incomplete, err := osm.MarshalSQL(output, planet)
+				for _, iw := range incomplete_ways {
+					ch <- errors.Wrap(iw, "Output "+strconv.Itoa(output_id)+" | Way without geometry")
+				}
+				for _, ir := range incomplete {
+					ch <- errors.Wrap(ir, "Output "+strconv.Itoa(output_id)+" | Relation without geometry")
+				}
+				if err != nil {
+					ch <- errors.Wrap(err, "Output "+strconv.Itoa(output_id)+" | Error writing sql to "+output.Uri)
+					wg.Done()
+					return
+				}
+			} else if format == "geojson" {
 
-				output_fc, err := planet.GetFeatureCollection(output)
+				output_fc, incomplete_ways, incomplete, err := planet.GetFeatureCollection(output)
 				if err != nil {
 					ch <- errors.Wrap(err, "Could not get feature collection from planet")
 					wg.Done()
 					return
 				}
+				for _, iw := range incomplete_ways {
+					ch <- errors.Wrap(iw, "Output "+strconv.Itoa(output_id)+" | Skipped way")
+				}
+				for _, ir := range incomplete {
+					ch <- errors.Wrap(ir, "Output "+strconv.Itoa(output_id)+" | Skipped relation")
+				}
 
 				output_bytes, err := json.Marshal(output_fc)
 				if err != nil {
@@ -798,14 +1096,20 @@ func main() {
 					}
 				}
 
-			} else if output_format == "geojsonl" {
+			} else if format == "geojsonl" {
 
-				output_features, err := planet.GetFeatures(output)
+				output_features, incomplete_ways, incomplete, err := planet.GetFeatures(output)
 				if err != nil {
 					ch <- errors.Wrap(err, "Could not get features from planet")
 					wg.Done()
 					return
 				}
+				for _, iw := range incomplete_ways {
+					ch <- errors.Wrap(iw, "Output "+strconv.Itoa(output_id)+" | Skipped way")
+				}
+				for _, ir := range incomplete {
+					ch <- errors.Wrap(ir, "Output "+strconv.Itoa(output_id)+" | Skipped relation")
+				}
 
 				if output.Uri == "stdout" {
 					for _, f := range output_features {
@@ -875,6 +1179,13 @@ func main() {
 						return
 					}
 				}
+			} else {
+				err := osm.MarshalPlanet(output, config, planet)
+				if err != nil {
+					ch <- errors.Wrap(err, "Output "+strconv.Itoa(output_id)+" | Error marshalling to "+output.Uri)
+					wg.Done()
+					return
+				}
 			}
 
 			if profile {
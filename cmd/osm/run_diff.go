@@ -0,0 +1,132 @@
+// +build !js
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+import (
+	"github.com/spatialcurrent/go-osm/osm"
+)
+
+// run_diff runs the "osm diff" mode, which compares an old and a new planet file by element id and version
+// and writes the differences as an osmChange (.osc) file.  Exits the process on error.
+func run_diff(args []string) {
+
+	var old_uri string
+	var new_uri string
+	var output_uri string
+	var summarize bool
+	var pretty bool
+	var overwrite bool
+	var read_buffer_size int
+
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	fs.StringVar(&old_uri, "old_uri", "", "Uri to the old planet file.")
+	fs.StringVar(&new_uri, "new_uri", "", "Uri to the new planet file.")
+	fs.StringVar(&output_uri, "output_uri", "", "Uri to the output osmChange file (.osc or .osc.gz), \"stdout\", or \"stderr\".  If blank, then only prints the summary.")
+	fs.BoolVar(&summarize, "summarize", false, "Print a summary of the changes to stdout, with counts by element type and by tag key.")
+	fs.BoolVar(&pretty, "pretty", false, "Pretty output.  Adds indents.")
+	fs.BoolVar(&overwrite, "overwrite", false, "Overwrite output file.")
+	fs.IntVar(&read_buffer_size, "read_buffer_size", 4096, "Size of buffer when reading files from disk")
+	fs.Usage = func() {
+		fmt.Println("Usage: osm diff -old_uri OLD -new_uri NEW [-output_uri OUTPUT] [-summarize] [-pretty] [-overwrite]")
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if len(old_uri) == 0 || len(new_uri) == 0 {
+		fmt.Println("Error: osm diff requires both -old_uri and -new_uri.")
+		fmt.Println("Run \"osm diff -help\" for more information.")
+		os.Exit(1)
+	}
+
+	if len(output_uri) == 0 && !summarize {
+		fmt.Println("Error: osm diff requires -output_uri, -summarize, or both.")
+		os.Exit(1)
+	}
+
+	if len(output_uri) > 0 && output_uri != "stdout" && output_uri != "stderr" && !(strings.HasSuffix(output_uri, ".osc") || strings.HasSuffix(output_uri, ".osc.gz")) {
+		fmt.Println("Error: output_uri " + output_uri + " is not an osmChange file (.osc or .osc.gz).")
+		os.Exit(1)
+	}
+
+	config := &osm.Config{
+		InputConfigs: []osm.InputConfig{
+			osm.NewInputConfig(old_uri, false, false, false, nil),
+			osm.NewInputConfig(new_uri, false, false, false, nil),
+		},
+		OutputConfigs: make([]osm.OutputConfig, 0),
+	}
+	if len(output_uri) > 0 {
+		config.OutputConfigs = append(config.OutputConfigs, osm.NewOutputConfig(output_uri, nil, false, false, false, false, false, false, false, false, false, pretty, []string{}))
+	}
+
+	funcs := dfl.NewFuntionMapWithDefaults()
+	err := config.Init(map[string]interface{}{}, &funcs)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	for _, output := range config.Outputs {
+		if output.IsType("file") && output.FileExists() {
+			if !overwrite {
+				fmt.Println("Output file already exists at output location " + output.Uri + ".")
+				fmt.Println("If you'd like to overwrite this file, then set the overwrite command line flag.")
+				os.Exit(1)
+			}
+		}
+	}
+
+	// The planets share a tags cache and user names, so the elements of both planets can be written through either planet.
+	old_planet := osm.NewPlanet()
+	new_planet := osm.NewPlanet()
+	new_planet.Tags = old_planet.Tags
+	new_planet.UserNames = old_planet.UserNames
+
+	for i, p := range []*osm.Planet{old_planet, new_planet} {
+		load_planet(&osm.Config{Inputs: config.Inputs[i : i+1]}, read_buffer_size, p)
+	}
+
+	c, err := osm.DiffPlanets(old_planet, new_planet)
+	if err != nil {
+		fmt.Println(errors.Wrap(err, "Error comparing planets"))
+		os.Exit(1)
+	}
+	c.Generator = "go-osm " + GO_OSM_VERSION
+
+	for _, output := range config.Outputs {
+		w, _, err := osm.OpenOutputWriter(output)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		err = osm.MarshalChange(w, new_planet, output, c)
+		if err != nil {
+			fmt.Println(errors.Wrap(err, "Error marshalling change to "+output.Uri))
+			os.Exit(1)
+		}
+		err = w.Close()
+		if err != nil {
+			fmt.Println(errors.Wrap(err, "Error closing output at "+output.Uri))
+			os.Exit(1)
+		}
+	}
+
+	if summarize {
+		c.Summarize(new_planet.Tags).Print()
+	}
+}
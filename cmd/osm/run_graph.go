@@ -0,0 +1,194 @@
+// +build !js
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+import (
+	"github.com/spatialcurrent/go-osm/osm"
+)
+
+// run_graph runs the "osm graph" mode, which builds a routing graph from the highway ways of the inputs
+// and writes the edges and vertices as CSV or the graph as GraphML.  See osm.Planet.BuildRoutingGraph.  Exits the process on error.
+func run_graph(args []string) {
+
+	var input_uri_text string
+	var input_uri_separator string
+	var merge_policy string
+	var bbox_text string
+	var boundary_uri string
+	var extract_strategy string
+	var dfl_text string
+	var profile_name string
+	var output_uri string
+	var output_format string
+	var vertices_uri string
+	var pretty bool
+	var overwrite bool
+	var read_buffer_size int
+	var verbose bool
+
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	fs.StringVar(&input_uri_text, "input_uri", "", "A single or separated list of input uris.  Uri to input file.")
+	fs.StringVar(&input_uri_separator, "input_uri_separator", "", "Separator for splitting input_uri into multiple, e.g., :.  By default nothing.")
+	fs.StringVar(&merge_policy, "merge_policy", osm.MERGE_FIRST, "Policy for resolving elements present in multiple inputs: "+strings.Join(osm.MERGE_POLICIES, ", ")+".")
+	fs.StringVar(&bbox_text, "bbox", "", "Only read the elements in the bounding box (minx,miny,maxx,maxy)")
+	fs.StringVar(&boundary_uri, "boundary_uri", "", "Only read the elements in the polygon in an Osmosis polygon filter file (.poly) or a GeoJSON Polygon or MultiPolygon file (.geojson, .json).")
+	fs.StringVar(&extract_strategy, "extract_strategy", osm.EXTRACT_COMPLETE_WAYS, "Strategy for ways crossing the bbox or boundary: "+strings.Join(osm.EXTRACT_STRATEGIES, ", ")+".")
+	fs.StringVar(&dfl_text, "dfl", "", "DFL filter for the ways included in the graph, e.g., '@highway in [motorway, trunk, primary]'")
+	fs.StringVar(&profile_name, "profile", "car", "Routing profile deciding which ways are routable, in which directions, and how fast: car, bike, or foot.")
+	fs.StringVar(&output_uri, "output_uri", "", "Uri to the output file (.csv, .csv.gz, .graphml, .graphml.gz), \"stdout\", or \"stderr\".  A CSV output has one row per edge.")
+	fs.StringVar(&output_format, "output_format", "", "The output format: csv or graphml.  Defaults to the format of the output_uri extension or csv.")
+	fs.StringVar(&vertices_uri, "vertices_uri", "", "Uri to the output CSV file (.csv or .csv.gz) for the vertices, when writing the edges as CSV.")
+	fs.BoolVar(&pretty, "pretty", false, "Pretty output.  Adds indents to GraphML.")
+	fs.BoolVar(&overwrite, "overwrite", false, "Overwrite output files.")
+	fs.IntVar(&read_buffer_size, "read_buffer_size", 4096, "Size of buffer when reading files from disk")
+	fs.BoolVar(&verbose, "verbose", false, "Print the size of the graph and the ways that were skipped.")
+	fs.Usage = func() {
+		fmt.Println("Usage: osm graph -input_uri INPUT -output_uri OUTPUT [-vertices_uri VERTICES] [-profile car|bike|foot] [-dfl FILTER] [-bbox minx,miny,maxx,maxy] [-overwrite]")
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if len(input_uri_text) == 0 || len(output_uri) == 0 {
+		fmt.Println("Error: osm graph requires -input_uri and -output_uri.")
+		fmt.Println("Run \"osm graph -help\" for more information.")
+		os.Exit(1)
+	}
+
+	profile, ok := osm.ROUTING_PROFILES[profile_name]
+	if !ok {
+		fmt.Println("Error: profile " + profile_name + " is not supported.  Expecting car, bike, or foot.")
+		os.Exit(1)
+	}
+
+	if len(output_format) == 0 {
+		output_format = osm.InferFormat(output_uri)
+		if output_format != "graphml" {
+			output_format = "csv"
+		}
+	}
+
+	if output_format != "csv" && output_format != "graphml" {
+		fmt.Println("Error: output_format " + output_format + " is not supported.  Expecting csv or graphml.")
+		os.Exit(1)
+	}
+
+	if len(vertices_uri) > 0 && output_format != "csv" {
+		fmt.Println("Error: -vertices_uri is only supported when writing the edges as CSV.")
+		os.Exit(1)
+	}
+
+	bbox, err := osm.ParseSliceFloat64(bbox_text)
+	if err != nil {
+		fmt.Println("Invalid bounding box " + bbox_text)
+		os.Exit(1)
+	}
+	if len(bbox) != 0 && len(bbox) != 4 {
+		fmt.Println("Invalid length of bounding box " + bbox_text)
+		os.Exit(1)
+	}
+
+	input_uris := []string{input_uri_text}
+	if len(input_uri_separator) > 0 {
+		input_uris = strings.Split(input_uri_text, input_uri_separator)
+	}
+
+	config := &osm.Config{
+		InputConfigs:  make([]osm.InputConfig, 0, len(input_uris)),
+		OutputConfigs: make([]osm.OutputConfig, 0),
+		MergePolicy:   merge_policy,
+	}
+	for _, input_uri := range input_uris {
+		input_filter := osm.NewFilter([]string{}, []string{}, "", false, bbox, boundary_uri, extract_strategy)
+		config.InputConfigs = append(config.InputConfigs, osm.NewInputConfig(input_uri, false, false, true, input_filter))
+	}
+	for _, uri := range []string{output_uri, vertices_uri} {
+		if len(uri) > 0 {
+			config.OutputConfigs = append(config.OutputConfigs, osm.NewOutputConfig(uri, nil, false, false, true, false, false, false, false, false, false, pretty, []string{}))
+		}
+	}
+
+	funcs := dfl.NewFuntionMapWithDefaults()
+	err = config.Init(map[string]interface{}{}, &funcs)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	err = config.Validate()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	way_filter := osm.NewFilter([]string{}, []string{}, dfl_text, false, []float64{}, "", "")
+	err = way_filter.Init(map[string]interface{}{}, &funcs)
+	if err != nil {
+		fmt.Println(errors.Wrap(err, "Error parsing DFL filter "+dfl_text))
+		os.Exit(1)
+	}
+
+	for _, output := range config.Outputs {
+		if output.IsType("file") && output.FileExists() {
+			if !overwrite {
+				fmt.Println("Output file already exists at output location " + output.Uri + ".")
+				fmt.Println("If you'd like to overwrite this file, then set the overwrite command line flag.")
+				os.Exit(1)
+			}
+		}
+	}
+
+	planet := osm.NewPlanet()
+	load_planet(config, read_buffer_size, planet)
+
+	g, err := planet.BuildRoutingGraph(way_filter, profile)
+	if err != nil {
+		fmt.Println(errors.Wrap(err, "Error building routing graph"))
+		os.Exit(1)
+	}
+
+	if verbose {
+		fmt.Println("Built routing graph with " + fmt.Sprint(len(g.Vertices)) + " vertices and " + fmt.Sprint(len(g.Edges)) + " edges.")
+		for _, id := range g.Incomplete {
+			fmt.Println("Skipped way " + fmt.Sprint(id) + ", since it references nodes missing from the inputs.")
+		}
+	}
+
+	for i, output := range config.Outputs {
+		w, _, err := osm.OpenOutputWriter(output)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		if i == 1 {
+			err = osm.MarshalRoutingVerticesCSV(w, g)
+		} else if output_format == "graphml" {
+			err = osm.MarshalRoutingGraphML(w, g, pretty)
+		} else {
+			err = osm.MarshalRoutingEdgesCSV(w, g)
+		}
+		if err != nil {
+			fmt.Println(errors.Wrap(err, "Error writing routing graph to "+output.Uri))
+			os.Exit(1)
+		}
+		err = w.Close()
+		if err != nil {
+			fmt.Println(errors.Wrap(err, "Error closing output at "+output.Uri))
+			os.Exit(1)
+		}
+	}
+}
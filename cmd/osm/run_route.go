@@ -0,0 +1,195 @@
+// +build !js
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+import (
+	"github.com/spatialcurrent/go-osm/osm"
+)
+
+// parse_point parses a point given as lon,lat.
+func parse_point(text string) ([]float64, error) {
+	point, err := osm.ParseSliceFloat64(text)
+	if err != nil {
+		return point, errors.Wrap(err, "Invalid point "+text)
+	}
+	if len(point) != 2 {
+		return point, errors.New("Invalid point " + text + ".  Expecting lon,lat.")
+	}
+	return point, nil
+}
+
+// run_route runs the "osm route" mode, which finds the fastest route between two points through the routing graph of the inputs
+// and writes the route as a GeoJSON LineString feature with the distance and duration.  See osm.Router.  Exits the process on error.
+func run_route(args []string) {
+
+	var input_uri_text string
+	var input_uri_separator string
+	var merge_policy string
+	var bbox_text string
+	var boundary_uri string
+	var from_text string
+	var to_text string
+	var profile_name string
+	var output_uri string
+	var pretty bool
+	var overwrite bool
+	var read_buffer_size int
+	var verbose bool
+
+	fs := flag.NewFlagSet("route", flag.ExitOnError)
+	fs.StringVar(&input_uri_text, "input_uri", "", "A single or separated list of input uris.  Uri to input file.")
+	fs.StringVar(&input_uri_separator, "input_uri_separator", "", "Separator for splitting input_uri into multiple, e.g., :.  By default nothing.")
+	fs.StringVar(&merge_policy, "merge_policy", osm.MERGE_FIRST, "Policy for resolving elements present in multiple inputs: "+strings.Join(osm.MERGE_POLICIES, ", ")+".")
+	fs.StringVar(&bbox_text, "bbox", "", "Only read the elements in the bounding box (minx,miny,maxx,maxy)")
+	fs.StringVar(&boundary_uri, "boundary_uri", "", "Only read the elements in the polygon in an Osmosis polygon filter file (.poly) or a GeoJSON Polygon or MultiPolygon file (.geojson, .json).")
+	fs.StringVar(&from_text, "from", "", "Start of the route as lon,lat.  Snapped to the nearest node of a routable way.")
+	fs.StringVar(&to_text, "to", "", "End of the route as lon,lat.  Snapped to the nearest node of a routable way.")
+	fs.StringVar(&profile_name, "profile", "car", "Routing profile deciding which ways are routable, in which directions, and how fast: car, bike, or foot.")
+	fs.StringVar(&output_uri, "output_uri", "stdout", "Uri to the output GeoJSON file, \"stdout\", or \"stderr\".")
+	fs.BoolVar(&pretty, "pretty", false, "Pretty output.  Adds indents.")
+	fs.BoolVar(&overwrite, "overwrite", false, "Overwrite output file.")
+	fs.IntVar(&read_buffer_size, "read_buffer_size", 4096, "Size of buffer when reading files from disk")
+	fs.BoolVar(&verbose, "verbose", false, "Print the size of the graph and the nodes the points snapped to.")
+	fs.Usage = func() {
+		fmt.Println("Usage: osm route -input_uri INPUT -from lon,lat -to lon,lat [-profile car|bike|foot] [-output_uri OUTPUT] [-bbox minx,miny,maxx,maxy]")
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if len(input_uri_text) == 0 || len(from_text) == 0 || len(to_text) == 0 {
+		fmt.Println("Error: osm route requires -input_uri, -from, and -to.")
+		fmt.Println("Run \"osm route -help\" for more information.")
+		os.Exit(1)
+	}
+
+	from, err := parse_point(from_text)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	to, err := parse_point(to_text)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	profile, ok := osm.ROUTING_PROFILES[profile_name]
+	if !ok {
+		fmt.Println("Error: profile " + profile_name + " is not supported.  Expecting car, bike, or foot.")
+		os.Exit(1)
+	}
+
+	bbox, err := osm.ParseSliceFloat64(bbox_text)
+	if err != nil {
+		fmt.Println("Invalid bounding box " + bbox_text)
+		os.Exit(1)
+	}
+	if len(bbox) != 0 && len(bbox) != 4 {
+		fmt.Println("Invalid length of bounding box " + bbox_text)
+		os.Exit(1)
+	}
+
+	if output_uri != "stdout" && output_uri != "stderr" && !overwrite {
+		if _, err := os.Stat(output_uri); err == nil {
+			fmt.Println("Output file already exists at output location " + output_uri + ".")
+			fmt.Println("If you'd like to overwrite this file, then set the overwrite command line flag.")
+			os.Exit(1)
+		}
+	}
+
+	input_uris := []string{input_uri_text}
+	if len(input_uri_separator) > 0 {
+		input_uris = strings.Split(input_uri_text, input_uri_separator)
+	}
+
+	config := &osm.Config{
+		InputConfigs:  make([]osm.InputConfig, 0, len(input_uris)),
+		OutputConfigs: make([]osm.OutputConfig, 0),
+		MergePolicy:   merge_policy,
+	}
+	for _, input_uri := range input_uris {
+		input_filter := osm.NewFilter([]string{}, []string{}, "", false, bbox, boundary_uri, osm.EXTRACT_COMPLETE_WAYS)
+		config.InputConfigs = append(config.InputConfigs, osm.NewInputConfig(input_uri, false, false, false, input_filter))
+	}
+
+	funcs := dfl.NewFuntionMapWithDefaults()
+	err = config.Init(map[string]interface{}{}, &funcs)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	err = config.Validate()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	planet := osm.NewPlanet()
+	load_planet(config, read_buffer_size, planet)
+
+	g, err := planet.BuildRoutingGraph(nil, profile)
+	if err != nil {
+		fmt.Println(errors.Wrap(err, "Error building routing graph"))
+		os.Exit(1)
+	}
+
+	router := osm.NewRouter(planet, g)
+
+	if verbose {
+		fmt.Println("Built routing graph with " + fmt.Sprint(len(g.Vertices)) + " vertices, " + fmt.Sprint(len(g.Edges)) + " edges, and " + fmt.Sprint(len(g.Restrictions)) + " turn restrictions.")
+		for _, p := range [][]float64{from, to} {
+			node, distance, err := router.Snap(p[0], p[1])
+			if err == nil {
+				fmt.Println("Snapped " + fmt.Sprint(p) + " to node " + fmt.Sprint(node) + ", " + fmt.Sprintf("%.1f", distance) + " meters away.")
+			}
+		}
+	}
+
+	route, err := router.Route(from, to)
+	if err != nil {
+		fmt.Println(errors.Wrap(err, "Error finding route"))
+		os.Exit(1)
+	}
+
+	var output_bytes []byte
+	if pretty {
+		output_bytes, err = json.MarshalIndent(route.Feature(), "", "  ")
+	} else {
+		output_bytes, err = json.Marshal(route.Feature())
+	}
+	if err != nil {
+		fmt.Println(errors.Wrap(err, "Error marshalling route as GeoJSON"))
+		os.Exit(1)
+	}
+
+	if output_uri == "stdout" {
+		fmt.Println(string(output_bytes))
+	} else if output_uri == "stderr" {
+		fmt.Fprintln(os.Stderr, string(output_bytes))
+	} else {
+		err := ioutil.WriteFile(output_uri, append(output_bytes, '\n'), 0644)
+		if err != nil {
+			fmt.Println(errors.Wrap(err, "Error writing route to "+output_uri))
+			os.Exit(1)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// +build !js
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+import (
+	"github.com/spatialcurrent/go-osm/osm"
+)
+
+// run_serve runs the "osm serve" mode, which loads the planet from the inputs once
+// and answers HTTP requests for the features that pass a filter.  See osm.Server.  Exits the process on error.
+func run_serve(args []string) {
+
+	var input_uri_text string
+	var input_uri_separator string
+	var merge_policy string
+	var relation_types_text string
+	var addr string
+	var read_buffer_size int
+	var verbose bool
+
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.StringVar(&input_uri_text, "input_uri", "", "A single or separated list of input uris.  Uri to input file.")
+	fs.StringVar(&input_uri_separator, "input_uri_separator", "", "Separator for splitting input_uri into multiple, e.g., :.  By default nothing.")
+	fs.StringVar(&merge_policy, "merge_policy", osm.MERGE_FIRST, "Policy for resolving elements present in multiple inputs: "+strings.Join(osm.MERGE_POLICIES, ", ")+".")
+	fs.StringVar(&relation_types_text, "relation_types", strings.Join(osm.DEFAULT_RELATION_TYPES, ","), "Default comma-separated list of the types of relations converted into features: multipolygon, boundary, route, route_master")
+	fs.StringVar(&addr, "addr", ":8080", "Address to listen on, e.g., :8080 or localhost:8080.")
+	fs.IntVar(&read_buffer_size, "read_buffer_size", 4096, "Size of buffer when reading files from disk")
+	fs.BoolVar(&verbose, "verbose", false, "Print each request to stdout.")
+	fs.Usage = func() {
+		fmt.Println("Usage: osm serve -input_uri INPUT [-addr :8080] [-verbose]")
+		fmt.Println("Endpoints:")
+		fmt.Println("  GET /features?bbox=minx,miny,maxx,maxy&dfl=EXPRESSION&keys_keep=KEYS&keys_drop=KEYS&relation_types=TYPES&format=geojson|geojsonl|osm")
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if len(input_uri_text) == 0 {
+		fmt.Println("Error: osm serve requires -input_uri.")
+		fmt.Println("Run \"osm serve -help\" for more information.")
+		os.Exit(1)
+	}
+
+	relation_types := osm.ParseSliceString(relation_types_text)
+	for _, t := range relation_types {
+		if t != "multipolygon" && t != "boundary" && t != "route" && t != "route_master" {
+			fmt.Println("Relation type " + t + " is not supported by -relation_types")
+			os.Exit(1)
+		}
+	}
+
+	input_uris := []string{input_uri_text}
+	if len(input_uri_separator) > 0 {
+		input_uris = strings.Split(input_uri_text, input_uri_separator)
+	}
+
+	config := &osm.Config{
+		InputConfigs:  make([]osm.InputConfig, 0, len(input_uris)),
+		OutputConfigs: make([]osm.OutputConfig, 0),
+		MergePolicy:   merge_policy,
+	}
+	for _, input_uri := range input_uris {
+		config.InputConfigs = append(config.InputConfigs, osm.NewInputConfig(input_uri, false, false, false, nil))
+	}
+
+	funcs := dfl.NewFuntionMapWithDefaults()
+	err := config.Init(map[string]interface{}{}, &funcs)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	err = config.Validate()
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	planet := osm.NewPlanet()
+	load_planet(config, read_buffer_size, planet)
+
+	var handler http.Handler = osm.NewServer(planet, &funcs, relation_types)
+	if verbose {
+		server := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Println(r.Method + " " + r.URL.String())
+			server.ServeHTTP(w, r)
+		})
+	}
+
+	fmt.Println("Serving " + fmt.Sprint(len(planet.Nodes)) + " nodes, " + fmt.Sprint(len(planet.Ways)) + " ways, and " + fmt.Sprint(len(planet.Relations)) + " relations on " + addr + ".")
+	err = http.ListenAndServe(addr, handler)
+	if err != nil {
+		fmt.Println(errors.Wrap(err, "Error serving on "+addr))
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,11 @@
+package osm
+
+// ACCESS_VALUES is the set of values of access tags that allow routing through a way.
+// Every other value denies it, including values that only allow some traffic, e.g., agricultural, forestry, delivery, destination, or customers,
+// and values that allow none, e.g., no or private.
+var ACCESS_VALUES = StringSet{
+	"yes":        struct{}{},
+	"designated": struct{}{},
+	"permissive": struct{}{},
+	"official":   struct{}{},
+}
@@ -0,0 +1,106 @@
+package osm
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+// AddElementsToPlanet adds the nodes, ways, and relations read from an input to the planet.
+// Relations, ways, and nodes are added if they pass the input filter.
+// Members of a kept relation and nodes referenced by a kept way are always added, so the planet remains referentially complete.
+// Elements already in the planet are resolved with the input's merge policy.
+// Returns an error if any.
+func AddElementsToPlanet(p *Planet, input *Input, nodes []*Node, ways []*Way, relations []*Relation, dfl_cache *dfl.Cache) error {
+
+	if input.DropWays && input.DropRelations {
+		for _, n := range nodes {
+			err := p.MergeNode(input, n)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// If the input is clipped to a bounding box or boundary, then only the elements in the extract are candidates.
+	// Members and way nodes outside of the extract are not added, so only the extract strategy completes ways and relations.
+	var extract *Extract
+	if input.Filter != nil && input.Filter.HasSpatialExtent() {
+		extract = ClipElements(p, input.Filter, nodes, ways, relations)
+		clipped_relations := make([]*Relation, 0, len(extract.Relations))
+		for _, r := range relations {
+			if extract.Relations.Contains(r.Id) {
+				clipped_relations = append(clipped_relations, r)
+			}
+		}
+		relations = clipped_relations
+	}
+
+	valid_relations, set_relation_nodes, set_relation_ways, err := SelectRelations(relations, func(r *Relation) (bool, error) {
+		return KeepRelation(p, input.Filter, r, dfl_cache)
+	})
+	if err != nil {
+		return err
+	}
+
+	valid_ways := make([]*Way, 0)
+	set_way_nodes := NewUInt64Set()
+	for _, w := range ways {
+		if extract != nil && !extract.Ways.Contains(w.Id) {
+			continue
+		}
+		keep := set_relation_ways.Contains(w.Id)
+		if !keep {
+			keep, err = KeepWay(p, input.Filter, w, dfl_cache)
+			if err != nil {
+				return err
+			}
+		}
+		if keep {
+			valid_ways = append(valid_ways, w)
+			for _, nr := range w.NodeReferences {
+				set_way_nodes.Add(nr.Reference)
+			}
+		}
+	}
+	slice_way_nodes := set_way_nodes.Slice(true)
+
+	valid_nodes := make([]*Node, 0)
+	for _, n := range nodes {
+		if extract != nil && !extract.Nodes.Contains(n.Id) {
+			continue
+		}
+		if slice_way_nodes.Contains(n.Id) || set_relation_nodes.Contains(n.Id) {
+			valid_nodes = append(valid_nodes, n)
+			continue
+		}
+		keep, err := KeepNode(p, input.Filter, n, dfl_cache)
+		if err != nil {
+			return err
+		}
+		if keep {
+			valid_nodes = append(valid_nodes, n)
+		}
+	}
+
+	// Add elements to planet
+	for _, n := range valid_nodes {
+		err := p.MergeNode(input, n)
+		if err != nil {
+			return err
+		}
+	}
+	for _, w := range valid_ways {
+		err := p.MergeWay(input, w)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range valid_relations {
+		err := p.MergeRelation(input, r)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package osm
+
+import (
+	"fmt"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+// ApplyChange applies the actions of the change to the planet in order.
+// Created and modified elements replace the element with the same id or are added to the planet, and deleted elements are removed from the planet.
+// An action is skipped for an element if the planet already has a newer version of the element.
+// The tags and attributes of created and modified elements are imported with the change input, so the change input should drop the same attributes and keys as the planet's inputs.
+// Created and modified elements are only added if they pass the change input's filter.  See selectChangeAction.
+// Deleting an element that is not in the planet does nothing.
+// Returns an error if any.
+func (p *Planet) ApplyChange(input *Input, c *Change) error {
+
+	var dfl_cache *dfl.Cache
+	if input.Filter != nil && input.Filter.HasExpression() && input.Filter.UseCache {
+		dfl_cache = dfl.NewCache()
+	}
+
+	removed := false
+
+	for _, a := range c.Actions {
+
+		if a.Type != CHANGE_CREATE && a.Type != CHANGE_MODIFY && a.Type != CHANGE_DELETE {
+			return errors.New("Unknown change action " + a.Type + " in change " + input.Uri)
+		}
+
+		a, err := p.selectChangeAction(input, a, dfl_cache)
+		if err != nil {
+			return errors.Wrap(err, "Error filtering "+a.Type+" action in change "+input.Uri)
+		}
+
+		if !input.DropNodes {
+			for _, n := range a.Nodes {
+				i, ok := p.nodesIndex[n.Id]
+				if ok && p.Nodes[i].Version > n.Version {
+					continue
+				}
+				if a.Type == CHANGE_DELETE {
+					if ok {
+						p.Nodes[i] = nil
+						delete(p.nodesIndex, n.Id)
+						removed = true
+					}
+					continue
+				}
+				if ok {
+					p.Nodes[i] = n
+				} else {
+					err := p.AddNode(n)
+					if err != nil {
+						return errors.Wrap(err, "Error adding node "+fmt.Sprint(n.Id)+" from change "+input.Uri)
+					}
+				}
+			}
+		}
+
+		if !input.DropWays {
+			for _, w := range a.Ways {
+				i, ok := p.waysIndex[w.Id]
+				if ok && p.Ways[i].Version > w.Version {
+					continue
+				}
+				if a.Type == CHANGE_DELETE {
+					if ok {
+						p.Ways[i] = nil
+						delete(p.waysIndex, w.Id)
+						removed = true
+					}
+					continue
+				}
+				if ok {
+					p.Ways[i] = w
+				} else {
+					err := p.AddWay(w)
+					if err != nil {
+						return errors.Wrap(err, "Error adding way "+fmt.Sprint(w.Id)+" from change "+input.Uri)
+					}
+				}
+			}
+		}
+
+		if !input.DropRelations {
+			for _, r := range a.Relations {
+				i, ok := p.relationsIndex[r.Id]
+				if ok && p.Relations[i].Version > r.Version {
+					continue
+				}
+				if a.Type == CHANGE_DELETE {
+					if ok {
+						p.Relations[i] = nil
+						delete(p.relationsIndex, r.Id)
+						removed = true
+					}
+					continue
+				}
+				if ok {
+					p.Relations[i] = r
+				} else {
+					err := p.AddRelation(r)
+					if err != nil {
+						return errors.Wrap(err, "Error adding relation "+fmt.Sprint(r.Id)+" from change "+input.Uri)
+					}
+				}
+			}
+		}
+	}
+
+	if removed {
+		p.compact()
+	}
+
+	p.rtreeValid = false
+
+	return nil
+}
+
+// compact removes the nil elements left by deletions from the nodes, ways, and relations of the planet
+// and rebuilds the indexes.  The order of the remaining elements is preserved.
+func (p *Planet) compact() {
+
+	nodes := make([]*Node, 0, len(p.nodesIndex))
+	for _, n := range p.Nodes {
+		if n != nil {
+			p.nodesIndex[n.Id] = len(nodes)
+			nodes = append(nodes, n)
+		}
+	}
+	p.Nodes = nodes
+
+	ways := make([]*Way, 0, len(p.waysIndex))
+	for _, w := range p.Ways {
+		if w != nil {
+			p.waysIndex[w.Id] = len(ways)
+			ways = append(ways, w)
+		}
+	}
+	p.Ways = ways
+
+	relations := make([]*Relation, 0, len(p.relationsIndex))
+	for _, r := range p.Relations {
+		if r != nil {
+			p.relationsIndex[r.Id] = len(relations)
+			relations = append(relations, r)
+		}
+	}
+	p.Relations = relations
+}
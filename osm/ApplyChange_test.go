@@ -0,0 +1,132 @@
+package osm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+const testChange = `<?xml version="1.0" encoding="UTF-8"?>
+<osmChange version="0.6" generator="test">
+  <create>
+    <node id="3" version="1" lat="1.5" lon="1.5"><tag k="amenity" v="bench"/></node>
+    <node id="4" version="1" lat="1.6" lon="1.6"/>
+    <node id="5" version="1" lat="3" lon="3"><tag k="shop" v="bakery"/></node>
+    <way id="11" version="1"><nd ref="3"/><nd ref="4"/><tag k="highway" v="footway"/></way>
+  </create>
+  <modify>
+    <node id="1" version="4" lat="38.9" lon="-77.04"><tag k="amenity" v="cafe"/></node>
+    <node id="2" version="1" lat="0" lon="0"/>
+  </modify>
+  <delete>
+    <relation id="20" version="2"/>
+    <node id="99" version="1"/>
+  </delete>
+</osmChange>
+`
+
+// openTestInput writes the text to a file with the given name in a temporary directory and opens it as an input.
+// Returns the input and a function that closes the input and removes the directory.
+func openTestInput(t *testing.T, name string, text string, filter *Filter) (*Input, func()) {
+	dir, err := ioutil.TempDir("", "osm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	err = ioutil.WriteFile(path, []byte(text), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := &Input{PlanetResource: &PlanetResource{FilteredResource: &FilteredResource{Resource: &Resource{Uri: path}, Filter: filter}}}
+	err = input.Init(map[string]interface{}{}, map[string]interface{}{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = input.Open(4096, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return input, func() {
+		input.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestApplyChange(t *testing.T) {
+	testCases := []struct {
+		name      string
+		filter    *Filter
+		nodes     []uint64
+		ways      []uint64
+		relations []uint64
+	}{
+		{name: "no filter", filter: nil, nodes: []uint64{1, 2, 3, 4, 5}, ways: []uint64{10, 11}, relations: []uint64{}},
+		{name: "keys to keep", filter: NewFilter([]string{"amenity", "highway"}, []string{}, "", true, []float64{}, "", ""), nodes: []uint64{1, 2, 3, 4}, ways: []uint64{10, 11}, relations: []uint64{}},
+		{name: "bbox", filter: NewFilter([]string{}, []string{}, "", true, []float64{1.55, 1.55, 2, 2}, "", ""), nodes: []uint64{1, 2, 3, 4}, ways: []uint64{10, 11}, relations: []uint64{}},
+	}
+	for _, tc := range testCases {
+		p := newTestPlanet(t)
+
+		input, close := openTestInput(t, "001.osc", testChange, tc.filter)
+		c, err := UnmarshalChange(input)
+		close()
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(c.Actions) != 3 {
+			t.Fatalf("%s: expected 3 actions, got %d.", tc.name, len(c.Actions))
+		}
+
+		err = p.ApplyChange(input, c)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		nodes := make([]uint64, 0)
+		for _, n := range p.Nodes {
+			nodes = append(nodes, n.Id)
+		}
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
+		ways := make([]uint64, 0)
+		for _, w := range p.Ways {
+			ways = append(ways, w.Id)
+		}
+		relations := make([]uint64, 0)
+		for _, r := range p.Relations {
+			relations = append(relations, r.Id)
+		}
+		for _, x := range []struct {
+			name     string
+			expected []uint64
+			actual   []uint64
+		}{
+			{name: "nodes", expected: tc.nodes, actual: nodes},
+			{name: "ways", expected: tc.ways, actual: ways},
+			{name: "relations", expected: tc.relations, actual: relations},
+		} {
+			if len(x.actual) != len(x.expected) {
+				t.Fatalf("%s: expected %s %v, got %v.", tc.name, x.name, x.expected, x.actual)
+			}
+			for i := range x.expected {
+				if x.actual[i] != x.expected[i] {
+					t.Fatalf("%s: expected %s %v, got %v.", tc.name, x.name, x.expected, x.actual)
+				}
+			}
+		}
+
+		// The modification of node 1 is applied and the older version of node 2 is skipped.
+		if n := p.Nodes[p.nodesIndex[1]]; n.Version != 4 || n.Longitude != -77.04 {
+			t.Fatalf("%s: expected node 1 version 4 at -77.04, got version %d at %v.", tc.name, n.Version, n.Longitude)
+		}
+		if n := p.Nodes[p.nodesIndex[2]]; n.Version != 3 || n.Longitude != 2.2945 {
+			t.Fatalf("%s: expected node 2 version 3 at 2.2945, got version %d at %v.", tc.name, n.Version, n.Longitude)
+		}
+		if tags := p.Tags.Slice(p.Nodes[p.nodesIndex[3]].TagsIndex); len(tags) != 1 || tags[0].Value != "bench" {
+			t.Fatalf("%s: expected node 3 to be tagged amenity=bench, got %v.", tc.name, tags)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package osm
+
+import (
+	"fmt"
+)
+
+// AreaRule describes which values of a tag key make a closed way an area, similar to the id-area-keys and osm2pgsql conventions.
+// If Values is empty, then every value of the key is an area, except for "no" and the values in Exclude.
+type AreaRule struct {
+	Key     string   `hcl:"key"`     // the tag key, e.g., building
+	Values  []string `hcl:"values"`  // values of the key that are areas.  If empty, then all values are areas.
+	Exclude []string `hcl:"exclude"` // values of the key that are lines, e.g., coastline for natural
+}
+
+// Match returns true if the tags have the key of the rule with a value that is an area.
+func (r AreaRule) Match(tags map[string]interface{}) bool {
+	v, ok := tags[r.Key]
+	if !ok {
+		return false
+	}
+	value := fmt.Sprint(v)
+	if value == "no" {
+		return false
+	}
+	if len(r.Values) > 0 {
+		return stringSliceContains(r.Values, value)
+	}
+	return !stringSliceContains(r.Exclude, value)
+}
@@ -0,0 +1,51 @@
+package osm
+
+// DEFAULT_AREA_RULES is the area rules used by an output that does not list any, based on the id-area-keys conventions.
+var DEFAULT_AREA_RULES = AreaRules{
+	AreaRule{Key: "building"},
+	AreaRule{Key: "building:part"},
+	AreaRule{Key: "landuse"},
+	AreaRule{Key: "amenity"},
+	AreaRule{Key: "shop"},
+	AreaRule{Key: "office"},
+	AreaRule{Key: "craft"},
+	AreaRule{Key: "tourism", Exclude: []string{"artwork"}},
+	AreaRule{Key: "historic"},
+	AreaRule{Key: "military"},
+	AreaRule{Key: "place"},
+	AreaRule{Key: "healthcare"},
+	AreaRule{Key: "area:highway"},
+	AreaRule{Key: "leisure", Exclude: []string{"track", "slipway"}},
+	AreaRule{Key: "natural", Exclude: []string{"coastline", "cliff", "ridge", "arete", "tree_row", "valley"}},
+	AreaRule{Key: "man_made", Exclude: []string{"breakwater", "cutline", "dyke", "embankment", "groyne", "pipeline"}},
+	AreaRule{Key: "power", Exclude: []string{"line", "minor_line", "cable"}},
+	AreaRule{Key: "barrier", Exclude: []string{"city_wall", "ditch", "fence", "hedge", "retaining_wall", "wall", "kerb"}},
+	AreaRule{Key: "aeroway", Exclude: []string{"runway", "taxiway", "taxilane", "parking_position", "jet_bridge"}},
+	AreaRule{Key: "public_transport", Values: []string{"platform", "station"}},
+	AreaRule{Key: "highway", Values: []string{"rest_area", "services", "platform"}},
+	AreaRule{Key: "railway", Values: []string{"platform", "station", "turntable", "roundhouse"}},
+	AreaRule{Key: "waterway", Values: []string{"riverbank", "dock", "boatyard", "fuel"}},
+}
+
+// AreaRules is a list of rules that decide whether a closed way is an area (polygon) or a line.
+type AreaRules []AreaRule
+
+// IsArea returns true if a closed way with the given tags is an area.
+// The area tag overrides the rules, so area=yes is always an area and area=no is never an area.
+// Otherwise, returns true if any rule matches the tags.
+func (rules AreaRules) IsArea(tags map[string]interface{}) bool {
+	if v, ok := tags["area"]; ok {
+		switch v {
+		case "yes":
+			return true
+		case "no":
+			return false
+		}
+	}
+	for _, r := range rules {
+		if r.Match(tags) {
+			return true
+		}
+	}
+	return false
+}
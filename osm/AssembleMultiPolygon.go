@@ -0,0 +1,201 @@
+package osm
+
+import (
+	"fmt"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// AssembleMultiPolygon assembles the member ways of a multipolygon or boundary relation into the coordinates of a MultiPolygon.
+// Way segments are stitched together end to end into closed rings, reversing segments as needed.
+// Ring nesting is resolved geometrically, rather than trusting member roles.
+// Rings nested at an even depth are outer rings and rings nested at an odd depth are holes in the ring that directly contains them.
+// Outer rings are oriented counter-clockwise and holes are oriented clockwise.
+// Returns an *IncompleteRelation as the error if member ways or nodes are missing from the planet or the rings cannot be assembled.
+func (p *Planet) AssembleMultiPolygon(r *Relation) ([][][][]float64, error) {
+
+	segments := make([][]uint64, 0, len(r.Members))
+	missing_ways := make([]uint64, 0)
+	missing_nodes := make([]uint64, 0)
+	set_missing_nodes := NewUInt64Set()
+	for _, m := range r.Members {
+		if m.Type != "way" || (m.Role != "outer" && m.Role != "inner" && m.Role != "") {
+			continue
+		}
+		i, ok := p.waysIndex[m.Reference]
+		if !ok {
+			missing_ways = append(missing_ways, m.Reference)
+			continue
+		}
+		w := p.Ways[i]
+		segment := make([]uint64, 0, len(w.NodeReferences))
+		for _, nr := range w.NodeReferences {
+			if _, ok := p.nodesIndex[nr.Reference]; !ok && !set_missing_nodes.Contains(nr.Reference) {
+				set_missing_nodes.Add(nr.Reference)
+				missing_nodes = append(missing_nodes, nr.Reference)
+			}
+			segment = append(segment, nr.Reference)
+		}
+		if len(segment) > 1 {
+			segments = append(segments, segment)
+		}
+	}
+
+	if len(missing_ways) > 0 || len(missing_nodes) > 0 {
+		return nil, &IncompleteRelation{Id: r.Id, MissingNodes: missing_nodes, MissingWays: missing_ways, Reason: "members are missing from the planet"}
+	}
+
+	if len(segments) == 0 {
+		return nil, &IncompleteRelation{Id: r.Id, Reason: "relation has no member ways"}
+	}
+
+	rings, err := stitchRings(segments)
+	if err != nil {
+		return nil, &IncompleteRelation{Id: r.Id, Reason: err.Error()}
+	}
+
+	coordinates := make([][][]float64, 0, len(rings))
+	for _, ring := range rings {
+		if len(ring) < 4 {
+			return nil, &IncompleteRelation{Id: r.Id, Reason: "ring has fewer than 4 nodes"}
+		}
+		c := make([][]float64, 0, len(ring))
+		for _, ref := range ring {
+			n := p.Nodes[p.nodesIndex[ref]]
+			c = append(c, []float64{n.Longitude, n.Latitude})
+		}
+		coordinates = append(coordinates, c)
+	}
+
+	// parents[i] is the index of the ring that directly contains ring i or -1.
+	depths := make([]int, len(coordinates))
+	parents := make([]int, len(coordinates))
+	for i := range coordinates {
+		parents[i] = -1
+		for j := range coordinates {
+			if i != j && ringContainsRing(coordinates[j], coordinates[i]) {
+				depths[i] += 1
+			}
+		}
+	}
+	for i := range coordinates {
+		for j := range coordinates {
+			if i != j && depths[j] == depths[i]-1 && ringContainsRing(coordinates[j], coordinates[i]) {
+				parents[i] = j
+				break
+			}
+		}
+	}
+
+	polygons := make([][][][]float64, 0)
+	outers := map[int]int{} // map of ring index to polygon index
+	for i, c := range coordinates {
+		if depths[i]%2 == 0 {
+			if ringArea(c) < 0 {
+				reverseRing(c)
+			}
+			outers[i] = len(polygons)
+			polygons = append(polygons, [][][]float64{c})
+		}
+	}
+	for i, c := range coordinates {
+		if depths[i]%2 == 1 {
+			j, ok := outers[parents[i]]
+			if !ok {
+				return nil, &IncompleteRelation{Id: r.Id, Reason: "inner ring " + fmt.Sprint(i) + " has no outer ring"}
+			}
+			if ringArea(c) > 0 {
+				reverseRing(c)
+			}
+			polygons[j] = append(polygons[j], c)
+		}
+	}
+
+	return polygons, nil
+}
+
+// stitchRings joins the segments of node references end to end into closed rings.
+// Returns an error if any segment cannot be joined into a closed ring.
+func stitchRings(segments [][]uint64) ([][]uint64, error) {
+	rings := make([][]uint64, 0)
+	used := make([]bool, len(segments))
+	for i, segment := range segments {
+		if used[i] {
+			continue
+		}
+		used[i] = true
+		ring := append(make([]uint64, 0, len(segment)), segment...)
+		for ring[0] != ring[len(ring)-1] {
+			last := ring[len(ring)-1]
+			found := false
+			for j, s := range segments {
+				if used[j] {
+					continue
+				}
+				if s[0] == last {
+					ring = append(ring, s[1:]...)
+				} else if s[len(s)-1] == last {
+					for k := len(s) - 2; k >= 0; k-- {
+						ring = append(ring, s[k])
+					}
+				} else {
+					continue
+				}
+				used[j] = true
+				found = true
+				break
+			}
+			if !found {
+				return rings, errors.New("ring starting at node " + fmt.Sprint(ring[0]) + " is not closed")
+			}
+		}
+		rings = append(rings, ring)
+	}
+	return rings, nil
+}
+
+// ringArea returns the signed area of the ring, which is positive if the ring is counter-clockwise.
+func ringArea(ring [][]float64) float64 {
+	area := 0.0
+	for i := 0; i < len(ring)-1; i++ {
+		area += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	return area / 2
+}
+
+// reverseRing reverses the order of the coordinates in the ring in place.
+func reverseRing(ring [][]float64) {
+	for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+		ring[i], ring[j] = ring[j], ring[i]
+	}
+}
+
+// ringContainsPoint returns true if the point is inside the ring, using the even-odd rule.
+func ringContainsPoint(ring [][]float64, point []float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		if (ring[i][1] > point[1]) != (ring[j][1] > point[1]) &&
+			point[0] < (ring[j][0]-ring[i][0])*(point[1]-ring[i][1])/(ring[j][1]-ring[i][1])+ring[i][0] {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// ringContainsRing returns true if the outer ring contains the inner ring.
+// Vertices shared by both rings are skipped, since rings in a multipolygon may touch.
+func ringContainsRing(outer [][]float64, inner [][]float64) bool {
+	shared := map[[2]float64]struct{}{}
+	for _, c := range outer {
+		shared[[2]float64{c[0], c[1]}] = struct{}{}
+	}
+	for _, c := range inner {
+		if _, ok := shared[[2]float64{c[0], c[1]}]; ok {
+			continue
+		}
+		return ringContainsPoint(outer, c)
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package osm
+
+import (
+	"strings"
+)
+
+// AssembleRoute assembles the member ways of a route or route_master relation into the coordinates of a MultiLineString.
+// The member ways of a route are joined in the order of the relation's members, reversing ways as needed, and a new line is started wherever consecutive ways are not connected.
+// Ways with a platform role are not part of the path, so they are skipped.
+// The lines of a route_master are the lines of its member routes, in order.
+// Also returns the members of the relation in order, with their type, reference, role, and sequence in the relation.
+// Returns an *IncompleteRelation as the error if member relations, ways, or nodes are missing from the planet.
+func (p *Planet) AssembleRoute(r *Relation) ([][][]float64, []map[string]interface{}, error) {
+
+	members := make([]map[string]interface{}, 0, len(r.Members))
+	for i, m := range r.Members {
+		members = append(members, map[string]interface{}{
+			"type":     m.Type,
+			"ref":      m.Reference,
+			"role":     m.Role,
+			"sequence": i,
+		})
+	}
+
+	ir := &IncompleteRelation{Id: r.Id, Reason: "members are missing from the planet"}
+	lines := p.assembleRouteLines(r, NewUInt64Set(), ir)
+	if len(ir.MissingRelations) > 0 || len(ir.MissingWays) > 0 || len(ir.MissingNodes) > 0 {
+		return nil, members, ir
+	}
+
+	if len(lines) == 0 {
+		return nil, members, &IncompleteRelation{Id: r.Id, Reason: "relation has no member ways"}
+	}
+
+	coordinates := make([][][]float64, 0, len(lines))
+	for _, line := range lines {
+		c := make([][]float64, 0, len(line))
+		for _, ref := range line {
+			n := p.Nodes[p.nodesIndex[ref]]
+			c = append(c, []float64{n.Longitude, n.Latitude})
+		}
+		coordinates = append(coordinates, c)
+	}
+
+	return coordinates, members, nil
+}
+
+// assembleRouteLines returns the lines of node references for the route or route_master relation.
+// Relations already in the visited set are skipped, so cycles of relations terminate.
+// Missing relations, ways, and nodes are added to the incomplete relation.
+func (p *Planet) assembleRouteLines(r *Relation, visited UInt64Set, ir *IncompleteRelation) [][]uint64 {
+
+	visited.Add(r.Id)
+
+	lines := make([][]uint64, 0)
+	line := make([]uint64, 0)
+	single := false // true if the current line is made of a single way and can still be reversed
+	for _, m := range r.Members {
+		switch m.Type {
+		case "relation":
+			if visited.Contains(m.Reference) {
+				continue
+			}
+			i, ok := p.relationsIndex[m.Reference]
+			if !ok {
+				ir.MissingRelations = append(ir.MissingRelations, m.Reference)
+				continue
+			}
+			if t := p.RelationType(p.Relations[i]); t != "route" && t != "route_master" {
+				continue
+			}
+			if len(line) > 0 {
+				lines = append(lines, line)
+				line = make([]uint64, 0)
+			}
+			lines = append(lines, p.assembleRouteLines(p.Relations[i], visited, ir)...)
+		case "way":
+			if strings.HasPrefix(m.Role, "platform") {
+				continue
+			}
+			i, ok := p.waysIndex[m.Reference]
+			if !ok {
+				ir.MissingWays = append(ir.MissingWays, m.Reference)
+				continue
+			}
+			w := p.Ways[i]
+			segment := make([]uint64, 0, len(w.NodeReferences))
+			for _, nr := range w.NodeReferences {
+				if _, ok := p.nodesIndex[nr.Reference]; !ok {
+					ir.MissingNodes = append(ir.MissingNodes, nr.Reference)
+				}
+				segment = append(segment, nr.Reference)
+			}
+			if len(segment) < 2 {
+				continue
+			}
+			if len(line) == 0 {
+				line = segment
+				single = true
+				continue
+			}
+			if single && (line[0] == segment[0] || line[0] == segment[len(segment)-1]) && line[len(line)-1] != segment[0] && line[len(line)-1] != segment[len(segment)-1] {
+				reverseNodeReferences(line)
+			}
+			if line[len(line)-1] == segment[0] {
+				line = append(line, segment[1:]...)
+			} else if line[len(line)-1] == segment[len(segment)-1] {
+				reverseNodeReferences(segment)
+				line = append(line, segment[1:]...)
+			} else {
+				lines = append(lines, line)
+				line = segment
+				single = true
+				continue
+			}
+			single = false
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// reverseNodeReferences reverses the order of the node references in place.
+func reverseNodeReferences(refs []uint64) {
+	for i, j := 0, len(refs)-1; i < j; i, j = i+1, j-1 {
+		refs[i], refs[j] = refs[j], refs[i]
+	}
+}
@@ -0,0 +1,100 @@
+package osm
+
+import (
+	"math"
+)
+
+// boundaryEdge is an edge of a ring of a boundary.
+type boundaryEdge struct {
+	x1 float64
+	y1 float64
+	x2 float64
+	y2 float64
+}
+
+// Boundary is a polygon or multipolygon used to clip extracts, e.g., a city or county boundary.
+// A point is inside the boundary if a ray from the point crosses the rings an odd number of times,
+// so holes are supported without distinguishing outer and inner rings.
+// The edges are indexed into horizontal bands, so a point-in-polygon test only checks the edges that span the latitude of the point.
+type Boundary struct {
+	Rings      [][][]float64    // the rings of the boundary as slices of [lon, lat]
+	Extent     *Bounds          // the bounding box of the boundary
+	bands      [][]boundaryEdge // edges indexed by band of latitude
+	bandHeight float64          // the height of each band in degrees of latitude
+}
+
+// NewBoundary returns a new Boundary for the given rings, each a slice of [lon, lat].
+// Rings do not need to be closed.
+func NewBoundary(rings [][][]float64) *Boundary {
+
+	extent := NewBounds(math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1))
+	edges := make([]boundaryEdge, 0)
+	for _, ring := range rings {
+		for i, c := range ring {
+			extent.ExtendPoint(c[0], c[1])
+			next := ring[(i+1)%len(ring)]
+			if c[0] != next[0] || c[1] != next[1] {
+				edges = append(edges, boundaryEdge{x1: c[0], y1: c[1], x2: next[0], y2: next[1]})
+			}
+		}
+	}
+
+	count := len(edges) / 4
+	if count < 1 {
+		count = 1
+	}
+	if count > 65536 {
+		count = 65536
+	}
+
+	b := &Boundary{
+		Rings:      rings,
+		Extent:     extent,
+		bands:      make([][]boundaryEdge, count),
+		bandHeight: (extent.MaximumLatitude - extent.MinimumLatitude) / float64(count),
+	}
+
+	for _, e := range edges {
+		first := b.band(math.Min(e.y1, e.y2))
+		last := b.band(math.Max(e.y1, e.y2))
+		for i := first; i <= last; i++ {
+			b.bands[i] = append(b.bands[i], e)
+		}
+	}
+
+	return b
+}
+
+// band returns the index of the band that contains the latitude.
+func (b *Boundary) band(lat float64) int {
+	if b.bandHeight == 0 {
+		return 0
+	}
+	i := int((lat - b.Extent.MinimumLatitude) / b.bandHeight)
+	if i < 0 {
+		return 0
+	}
+	if i >= len(b.bands) {
+		return len(b.bands) - 1
+	}
+	return i
+}
+
+// ContainsPoint returns true if the point is inside the boundary.
+func (b *Boundary) ContainsPoint(lon float64, lat float64) bool {
+
+	if len(b.bands) == 0 || !b.Extent.ContainsPoint(lon, lat) {
+		return false
+	}
+
+	inside := false
+	for _, e := range b.bands[b.band(lat)] {
+		if (e.y1 > lat) != (e.y2 > lat) {
+			if lon < e.x1+(lat-e.y1)*(e.x2-e.x1)/(e.y2-e.y1) {
+				inside = !inside
+			}
+		}
+	}
+
+	return inside
+}
@@ -1,10 +1,19 @@
 package osm
 
 import (
+	"math"
 	"strconv"
 	"strings"
 )
 
+import (
+	"github.com/dhconnelly/rtreego"
+	"github.com/pkg/errors"
+)
+
+// RTREE_TOLERANCE is the minimum width and height in degrees of a rectangle in the R-tree, since the R-tree does not support empty rectangles.
+const RTREE_TOLERANCE = 1e-9
+
 type Bounds struct {
 	MinimumLongitude float64 `xml:"minlon,attr"`
 	MinimumLatitude  float64 `xml:"minlat,attr"`
@@ -22,7 +31,39 @@ func (b Bounds) BoundingBox() string {
 }
 
 func (b Bounds) ContainsPoint(lon float64, lat float64) bool {
-	return lon >= b.MinimumLatitude && lon <= b.MaximumLongitude && lat >= b.MinimumLatitude && lat <= b.MaximumLatitude
+	return lon >= b.MinimumLongitude && lon <= b.MaximumLongitude && lat >= b.MinimumLatitude && lat <= b.MaximumLatitude
+}
+
+// ExtendPoint extends the bounds to include the point.
+func (b *Bounds) ExtendPoint(lon float64, lat float64) {
+	b.MinimumLongitude = math.Min(b.MinimumLongitude, lon)
+	b.MinimumLatitude = math.Min(b.MinimumLatitude, lat)
+	b.MaximumLongitude = math.Max(b.MaximumLongitude, lon)
+	b.MaximumLatitude = math.Max(b.MaximumLatitude, lat)
+}
+
+// Extend extends the bounds to include the other bounds.
+func (b *Bounds) Extend(other *Bounds) {
+	b.ExtendPoint(other.MinimumLongitude, other.MinimumLatitude)
+	b.ExtendPoint(other.MaximumLongitude, other.MaximumLatitude)
+}
+
+// Rect returns the bounds as a rectangle for the R-tree.
+// Returns an error if the bounds cannot be a rectangle, e.g., if a coordinate is NaN or a minimum is greater than its maximum.
+func (b Bounds) Rect() (*rtreego.Rect, error) {
+	if b.MinimumLongitude > b.MaximumLongitude || b.MinimumLatitude > b.MaximumLatitude {
+		return nil, errors.New("Invalid bounds " + b.BoundingBox() + ".  The minimum is greater than the maximum.")
+	}
+	width := math.Max(b.MaximumLongitude-b.MinimumLongitude, RTREE_TOLERANCE)
+	height := math.Max(b.MaximumLatitude-b.MinimumLatitude, RTREE_TOLERANCE)
+	if math.IsNaN(width) || math.IsNaN(height) {
+		return nil, errors.New("Invalid bounds " + b.BoundingBox() + ".")
+	}
+	rect, err := rtreego.NewRect(rtreego.Point([]float64{b.MinimumLongitude, b.MinimumLatitude}), []float64{width, height})
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating rectangle for bounds "+b.BoundingBox())
+	}
+	return rect, nil
 }
 
 func NewBounds(minlon float64, minlat float64, maxlon float64, maxlat float64) *Bounds {
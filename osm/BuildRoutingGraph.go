@@ -0,0 +1,137 @@
+package osm
+
+import (
+	"fmt"
+	"sort"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+// BuildRoutingGraph builds a routing graph for the profile from the routable ways in the planet that pass the filter.
+// A way is routable if the profile has a speed for it and it can be traveled in at least one direction.  See RoutingProfile.
+// Ways are split into edges at their end nodes and at every node shared with another routable way, and the shared nodes become the vertices of the graph.
+// The length of each edge is the sum of the haversine distances between its nodes.
+// The turn restrictions of the graph are the type=restriction relations in the planet that apply to the profile.  See TurnRestriction.
+// Ways that reference nodes missing from the planet are skipped and returned in the Incomplete list of the graph.
+// If the filter is nil, then uses every routable way.  Returns the graph and an error if any.
+func (p *Planet) BuildRoutingGraph(fi *Filter, profile *RoutingProfile) (*RoutingGraph, error) {
+
+	var dfl_cache *dfl.Cache
+	if fi != nil && fi.HasExpression() && fi.UseCache {
+		dfl_cache = dfl.NewCache()
+	}
+
+	g := &RoutingGraph{
+		Profile:      profile.Name,
+		Vertices:     make([]*RoutingVertex, 0),
+		Edges:        make([]*RoutingEdge, 0),
+		Restrictions: make([]*TurnRestriction, 0),
+		Incomplete:   make([]uint64, 0),
+	}
+
+	// Select the routable ways and count how many times each node is used, so ways can be split at shared nodes.
+	ways := make([]*Way, 0)
+	uses := map[uint64]int{}
+	for _, w := range p.Ways {
+		tags := p.Tags.Map(w.TagsIndex)
+		if _, ok := profile.Speed(tags); !ok {
+			continue
+		}
+		if forward, backward := profile.Directions(tags); !forward && !backward {
+			continue
+		}
+		if len(w.NodeReferences) < 2 {
+			continue
+		}
+		keep, err := KeepWay(p, fi, w, dfl_cache)
+		if err != nil {
+			return g, errors.Wrap(err, "Error filtering way for routing graph.")
+		}
+		if !keep {
+			continue
+		}
+		complete := true
+		for _, nr := range w.NodeReferences {
+			if _, ok := p.nodesIndex[nr.Reference]; !ok {
+				complete = false
+				break
+			}
+		}
+		if !complete {
+			g.Incomplete = append(g.Incomplete, w.Id)
+			continue
+		}
+		ways = append(ways, w)
+		for i, nr := range w.NodeReferences {
+			uses[nr.Reference] += 1
+			if i == 0 || i == len(w.NodeReferences)-1 {
+				// End nodes are always vertices.
+				uses[nr.Reference] += 1
+			}
+		}
+	}
+
+	vertices := map[uint64]*RoutingVertex{}
+	addVertex := func(n *Node) {
+		if _, ok := vertices[n.Id]; !ok {
+			vertices[n.Id] = &RoutingVertex{Id: n.Id, Longitude: n.Longitude, Latitude: n.Latitude}
+		}
+	}
+
+	for _, w := range ways {
+		tags := p.Tags.Map(w.TagsIndex)
+		highway := fmt.Sprint(tags["highway"])
+		speed, _ := profile.Speed(tags)
+		forward, backward := profile.Directions(tags)
+
+		newEdge := func(n *Node) *RoutingEdge {
+			addVertex(n)
+			return &RoutingEdge{
+				WayId:       w.Id,
+				Source:      n.Id,
+				Highway:     highway,
+				Speed:       speed,
+				Forward:     forward,
+				Backward:    backward,
+				Nodes:       []uint64{n.Id},
+				Coordinates: [][]float64{[]float64{n.Longitude, n.Latitude}},
+			}
+		}
+
+		prev := p.Nodes[p.nodesIndex[w.NodeReferences[0].Reference]]
+		e := newEdge(prev)
+		for i, nr := range w.NodeReferences[1:] {
+			n := p.Nodes[p.nodesIndex[nr.Reference]]
+			e.Length += Haversine(prev.Longitude, prev.Latitude, n.Longitude, n.Latitude)
+			e.Nodes = append(e.Nodes, n.Id)
+			e.Coordinates = append(e.Coordinates, []float64{n.Longitude, n.Latitude})
+			prev = n
+			if uses[n.Id] > 1 || i == len(w.NodeReferences)-2 {
+				addVertex(n)
+				e.Target = n.Id
+				e.Id = uint64(len(g.Edges) + 1)
+				g.Edges = append(g.Edges, e)
+				e = newEdge(n)
+			}
+		}
+	}
+
+	for _, r := range p.Relations {
+		if tr, ok := p.TurnRestriction(r, profile); ok {
+			g.Restrictions = append(g.Restrictions, tr)
+		}
+	}
+
+	for _, v := range vertices {
+		g.Vertices = append(g.Vertices, v)
+	}
+	sort.Slice(g.Vertices, func(i, j int) bool { return g.Vertices[i].Id < g.Vertices[j].Id })
+
+	return g, nil
+}
@@ -0,0 +1,114 @@
+package osm
+
+import (
+	"testing"
+)
+
+// addTestNode adds a node with the given id and coordinates to the planet.
+func addTestNode(t *testing.T, p *Planet, id uint64, lon float64, lat float64) {
+	n := &Node{Longitude: lon, Latitude: lat}
+	n.Id = id
+	err := p.AddNode(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// addTestWay adds a way with the given id, node references, and tags to the planet.
+func addTestWay(t *testing.T, p *Planet, id uint64, refs []uint64, tags []Tag) {
+	w := NewWay()
+	w.Id = id
+	for _, ref := range refs {
+		w.NodeReferences = append(w.NodeReferences, NodeReference{Reference: ref})
+	}
+	w.TagsIndex = p.AddTags(tags)
+	err := p.AddWay(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildRoutingGraph(t *testing.T) {
+	testCases := []struct {
+		name     string
+		profile  string
+		tags     []Tag
+		forward  bool
+		backward bool
+		routable bool
+	}{
+		{name: "residential", profile: "car", tags: []Tag{Tag{Key: "highway", Value: "residential"}}, forward: true, backward: true, routable: true},
+		{name: "oneway", profile: "car", tags: []Tag{Tag{Key: "highway", Value: "residential"}, Tag{Key: "oneway", Value: "-1"}}, forward: false, backward: true, routable: true},
+		{name: "footway", profile: "car", tags: []Tag{Tag{Key: "highway", Value: "footway"}}, routable: false},
+		{name: "access yes", profile: "car", tags: []Tag{Tag{Key: "highway", Value: "service"}, Tag{Key: "access", Value: "yes"}}, forward: true, backward: true, routable: true},
+		{name: "access private", profile: "car", tags: []Tag{Tag{Key: "highway", Value: "service"}, Tag{Key: "access", Value: "private"}}, routable: false},
+		{name: "access destination", profile: "car", tags: []Tag{Tag{Key: "highway", Value: "residential"}, Tag{Key: "access", Value: "destination"}}, routable: false},
+		{name: "access delivery", profile: "car", tags: []Tag{Tag{Key: "highway", Value: "service"}, Tag{Key: "access", Value: "delivery"}}, routable: false},
+		{name: "access agricultural", profile: "car", tags: []Tag{Tag{Key: "highway", Value: "track"}, Tag{Key: "access", Value: "agricultural"}}, routable: false},
+		{name: "motor_vehicle forestry", profile: "car", tags: []Tag{Tag{Key: "highway", Value: "track"}, Tag{Key: "motor_vehicle", Value: "forestry"}}, routable: false},
+		{name: "access no, motorcar permissive", profile: "car", tags: []Tag{Tag{Key: "highway", Value: "service"}, Tag{Key: "access", Value: "no"}, Tag{Key: "motorcar", Value: "permissive"}}, forward: true, backward: true, routable: true},
+		{name: "footway with bicycle designated", profile: "bike", tags: []Tag{Tag{Key: "highway", Value: "footway"}, Tag{Key: "bicycle", Value: "designated"}}, forward: true, backward: true, routable: true},
+		{name: "motor_vehicle destination", profile: "bike", tags: []Tag{Tag{Key: "highway", Value: "residential"}, Tag{Key: "motor_vehicle", Value: "destination"}}, forward: true, backward: true, routable: true},
+	}
+	for _, tc := range testCases {
+		p := NewPlanet()
+		addTestNode(t, p, 1, 0, 0)
+		addTestNode(t, p, 2, 0.001, 0)
+		addTestWay(t, p, 10, []uint64{1, 2}, tc.tags)
+
+		g, err := p.BuildRoutingGraph(nil, ROUTING_PROFILES[tc.profile])
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !tc.routable {
+			if len(g.Edges) != 0 {
+				t.Fatalf("%s: expected no edges, got %d.", tc.name, len(g.Edges))
+			}
+			continue
+		}
+		if len(g.Edges) != 1 || len(g.Vertices) != 2 {
+			t.Fatalf("%s: expected 1 edge and 2 vertices, got %d edges and %d vertices.", tc.name, len(g.Edges), len(g.Vertices))
+		}
+		if e := g.Edges[0]; e.Forward != tc.forward || e.Backward != tc.backward {
+			t.Fatalf("%s: expected forward %t and backward %t, got forward %t and backward %t.", tc.name, tc.forward, tc.backward, e.Forward, e.Backward)
+		}
+	}
+}
+
+func TestBuildRoutingGraphSplit(t *testing.T) {
+	p := NewPlanet()
+	for i := uint64(1); i <= 5; i++ {
+		addTestNode(t, p, i, float64(i)*0.001, 0)
+	}
+	addTestNode(t, p, 6, 0.003, 0.001)
+	highway := []Tag{Tag{Key: "highway", Value: "residential"}}
+	addTestWay(t, p, 10, []uint64{1, 2, 3, 4, 5}, highway)
+	addTestWay(t, p, 11, []uint64{3, 6}, highway)
+	addTestWay(t, p, 12, []uint64{6, 99}, highway)
+
+	g, err := p.BuildRoutingGraph(nil, ROUTING_PROFILES["car"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Way 10 is split at node 3, which is shared with way 11, but not at nodes 2 and 4.
+	expected := [][]uint64{[]uint64{1, 2, 3}, []uint64{3, 4, 5}, []uint64{3, 6}}
+	if len(g.Edges) != len(expected) {
+		t.Fatalf("Expected %d edges, got %d.", len(expected), len(g.Edges))
+	}
+	for i, nodes := range expected {
+		e := g.Edges[i]
+		if len(e.Nodes) != len(nodes) || e.Source != nodes[0] || e.Target != nodes[len(nodes)-1] {
+			t.Fatalf("Expected edge %d to have nodes %v, got %v.", i, nodes, e.Nodes)
+		}
+	}
+	if len(g.Vertices) != 4 {
+		t.Fatalf("Expected 4 vertices, got %d.", len(g.Vertices))
+	}
+	if len(g.Incomplete) != 1 || g.Incomplete[0] != 12 {
+		t.Fatalf("Expected way 12 to be incomplete, got %v.", g.Incomplete)
+	}
+	if l := g.Edges[0].Length; l < 222 || l > 223 {
+		t.Fatalf("Expected edge 0 to be about 222 meters long, got %v.", l)
+	}
+}
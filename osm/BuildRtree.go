@@ -0,0 +1,47 @@
+package osm
+
+import (
+	"github.com/dhconnelly/rtreego"
+)
+
+// BuildRtree rebuilds the planet's R-tree with every node and the bounding boxes of every way and relation.
+// Ways and relations without any nodes in the planet, or with invalid bounding boxes, are not indexed.
+// The R-tree is built automatically by QueryBBox and Nearest if the planet changed since it was last built.
+func (p *Planet) BuildRtree() {
+	p.rtreeMutex.Lock()
+	defer p.rtreeMutex.Unlock()
+	p.buildRtree()
+}
+
+// buildRtree rebuilds the planet's R-tree.  The caller must hold the R-tree mutex.
+func (p *Planet) buildRtree() {
+
+	tree := rtreego.NewTree(2, 25, 50)
+
+	for _, n := range p.Nodes {
+		tree.Insert(n)
+	}
+
+	for _, w := range p.Ways {
+		if b := p.WayBounds(w); b != nil {
+			se, err := NewSpatialElement(w, b)
+			if err != nil {
+				continue
+			}
+			tree.Insert(se)
+		}
+	}
+
+	for _, r := range p.Relations {
+		if b := p.RelationBounds(r); b != nil {
+			se, err := NewSpatialElement(r, b)
+			if err != nil {
+				continue
+			}
+			tree.Insert(se)
+		}
+	}
+
+	p.Rtree = tree
+	p.rtreeValid = true
+}
@@ -0,0 +1,77 @@
+package osm
+
+import (
+	"io"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	CHANGE_CREATE = "create" // osmChange action for creating an element
+	CHANGE_MODIFY = "modify" // osmChange action for modifying an element
+	CHANGE_DELETE = "delete" // osmChange action for deleting an element
+)
+
+// ChangeAction is a block of elements in an osmChange document that are created, modified, or deleted.
+type ChangeAction struct {
+	Type      string      // the action: create, modify, or delete
+	Nodes     []*Node     // the nodes in the block
+	Ways      []*Way      // the ways in the block
+	Relations []*Relation // the relations in the block
+}
+
+// Change is an osmChange document, which is a sequence of actions that are applied in order.
+//	- https://wiki.openstreetmap.org/wiki/OsmChange
+type Change struct {
+	Version   string          // the version of the osmChange document
+	Generator string          // the generator of the osmChange document
+	Actions   []*ChangeAction // the actions in order
+}
+
+// UnmarshalChange reads the elements from the change input and groups consecutive elements with the same action into a ChangeAction.
+// The input can be an osmChange (.osc) or o5c change file.  The elements keep their tags.
+// Returns the change and an error if any.
+func UnmarshalChange(input *Input) (*Change, error) {
+
+	c := &Change{Actions: make([]*ChangeAction, 0)}
+
+	decoder, err := NewElementDecoder(input.Reader, input.Format)
+	if err != nil {
+		return c, err
+	}
+
+	var a *ChangeAction
+	for {
+		element, err := decoder.Decode()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return c, errors.Wrap(err, "Error decoding "+input.Format+" from change "+input.Uri)
+		}
+
+		t := GetChangeAction(decoder)
+		if a == nil || a.Type != t {
+			a = &ChangeAction{Type: t}
+			c.Actions = append(c.Actions, a)
+		}
+
+		switch e := element.(type) {
+		case *Node:
+			a.Nodes = append(a.Nodes, e)
+		case *Way:
+			a.Ways = append(a.Ways, e)
+		case *Relation:
+			a.Relations = append(a.Relations, e)
+		}
+	}
+
+	if d, ok := decoder.(*XMLDecoder); ok {
+		c.Version = d.Version
+		c.Generator = d.Generator
+	}
+
+	return c, nil
+}
@@ -0,0 +1,79 @@
+package osm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ChangeSummary is a struct for storing the results of a summarization of a Change.
+type ChangeSummary struct {
+	CountsByAction map[string]map[string]int // count of nodes, ways, and relations by action (create, modify, or delete)
+	CountsByKey    map[string]map[string]int // count of created, modified, and deleted elements by tag key
+}
+
+// Print prints the summary to stdout.
+func (s ChangeSummary) Print() {
+	for _, action := range []string{CHANGE_CREATE, CHANGE_MODIFY, CHANGE_DELETE} {
+		counts := s.CountsByAction[action]
+		fmt.Println("Action:", action)
+		fmt.Println("Number of Nodes:", counts["nodes"])
+		fmt.Println("Number of Ways:", counts["ways"])
+		fmt.Println("Number of Relations:", counts["relations"])
+	}
+	keys := make([]string, 0, len(s.CountsByKey))
+	for key := range s.CountsByKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		counts := s.CountsByKey[key]
+		fmt.Println("-----------")
+		fmt.Println("Key:", key)
+		fmt.Println("Number Created:", counts[CHANGE_CREATE])
+		fmt.Println("Number Modified:", counts[CHANGE_MODIFY])
+		fmt.Println("Number Deleted:", counts[CHANGE_DELETE])
+	}
+}
+
+// Summarize counts the elements in the change by action and element type, and by action and tag key.
+// Tags are read from the elements or resolved through the tags cache, if the elements are stored in a planet.
+func (c *Change) Summarize(tc *TagsCache) ChangeSummary {
+
+	s := ChangeSummary{
+		CountsByAction: map[string]map[string]int{},
+		CountsByKey:    map[string]map[string]int{},
+	}
+
+	countKeys := func(action string, te *TaggedElement) {
+		tags := te.Tags
+		if len(tags) == 0 && len(te.TagsIndex) > 0 {
+			tags = tc.Slice(te.TagsIndex)
+		}
+		for _, t := range tags {
+			if _, ok := s.CountsByKey[t.Key]; !ok {
+				s.CountsByKey[t.Key] = map[string]int{}
+			}
+			s.CountsByKey[t.Key][action] += 1
+		}
+	}
+
+	for _, a := range c.Actions {
+		if _, ok := s.CountsByAction[a.Type]; !ok {
+			s.CountsByAction[a.Type] = map[string]int{}
+		}
+		s.CountsByAction[a.Type]["nodes"] += len(a.Nodes)
+		s.CountsByAction[a.Type]["ways"] += len(a.Ways)
+		s.CountsByAction[a.Type]["relations"] += len(a.Relations)
+		for _, n := range a.Nodes {
+			countKeys(a.Type, &n.TaggedElement)
+		}
+		for _, w := range a.Ways {
+			countKeys(a.Type, &w.TaggedElement)
+		}
+		for _, r := range a.Relations {
+			countKeys(a.Type, &r.TaggedElement)
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,99 @@
+package osm
+
+const (
+	EXTRACT_SIMPLE        = "simple"        // keep the nodes inside the extent, and the ways and relations that reference them, without completing the ways
+	EXTRACT_COMPLETE_WAYS = "complete_ways" // also keep every node of the ways crossing the extent
+	EXTRACT_SMART         = "smart"         // also keep every member of the multipolygon relations crossing the extent
+)
+
+// EXTRACT_STRATEGIES is the list of supported extract strategies, similar to the strategies of osmium extract.
+var EXTRACT_STRATEGIES = []string{EXTRACT_SIMPLE, EXTRACT_COMPLETE_WAYS, EXTRACT_SMART}
+
+// Extract is the set of ids of the nodes, ways, and relations that are kept by clipping to a bounding box or boundary.
+type Extract struct {
+	Nodes     UInt64Set // ids of the nodes in the extract
+	Ways      UInt64Set // ids of the ways in the extract
+	Relations UInt64Set // ids of the relations in the extract
+}
+
+// ClipElements clips the nodes, ways, and relations to the bounding box and boundary of the filter using the filter's extract strategy.
+// Nodes inside the extent are kept.  Ways with at least one node inside the extent are kept.
+// Relations with a member in the extract, and the parents of those relations, are kept.
+// With the complete_ways and smart strategies, every node of a kept way is kept, so ways crossing the extent are complete.
+// With the smart strategy, every member way of a kept multipolygon relation and the nodes of those ways are also kept.
+// Returns the extract.
+func ClipElements(p *Planet, fi *Filter, nodes []*Node, ways []*Way, relations []*Relation) *Extract {
+
+	e := &Extract{
+		Nodes:     NewUInt64Set(),
+		Ways:      NewUInt64Set(),
+		Relations: NewUInt64Set(),
+	}
+
+	complete := fi.Strategy != EXTRACT_SIMPLE
+
+	for _, n := range nodes {
+		if fi.ContainsPoint(n.Longitude, n.Latitude) {
+			e.Nodes.Add(n.Id)
+		}
+	}
+
+	inside := NewUInt64Set()
+	for id := range e.Nodes {
+		inside.Add(id)
+	}
+
+	waysIndex := map[uint64]*Way{}
+	for _, w := range ways {
+		waysIndex[w.Id] = w
+		for _, nr := range w.NodeReferences {
+			if inside.Contains(nr.Reference) {
+				e.Ways.Add(w.Id)
+				break
+			}
+		}
+		if complete && e.Ways.Contains(w.Id) {
+			for _, nr := range w.NodeReferences {
+				e.Nodes.Add(nr.Reference)
+			}
+		}
+	}
+
+	// Relations are added until no more relations reference a member in the extract, so parent relations are kept.
+	for changed := true; changed; {
+		changed = false
+		for _, r := range relations {
+			if e.Relations.Contains(r.Id) {
+				continue
+			}
+			for _, m := range r.Members {
+				if (m.Type == "node" && inside.Contains(m.Reference)) || (m.Type == "way" && e.Ways.Contains(m.Reference)) || (m.Type == "relation" && e.Relations.Contains(m.Reference)) {
+					e.Relations.Add(r.Id)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	if fi.Strategy == EXTRACT_SMART {
+		for _, r := range relations {
+			if !e.Relations.Contains(r.Id) || p.RelationType(r) != "multipolygon" {
+				continue
+			}
+			for _, m := range r.Members {
+				if m.Type != "way" {
+					continue
+				}
+				if w, ok := waysIndex[m.Reference]; ok {
+					e.Ways.Add(w.Id)
+					for _, nr := range w.NodeReferences {
+						e.Nodes.Add(nr.Reference)
+					}
+				}
+			}
+		}
+	}
+
+	return e
+}
@@ -0,0 +1,123 @@
+package osm
+
+// clipSegment clips the segment from a to b to the square from min to max on both axes with the Liang-Barsky algorithm.
+// Returns the fractions along the segment where the clipped segment starts and ends, and false if the segment is outside the square.
+func clipSegment(a []float64, b []float64, min float64, max float64) (float64, float64, bool) {
+	dx := b[0] - a[0]
+	dy := b[1] - a[1]
+	p := []float64{-dx, dx, -dy, dy}
+	q := []float64{a[0] - min, max - a[0], a[1] - min, max - a[1]}
+	t0, t1 := 0.0, 1.0
+	for i := range p {
+		if p[i] == 0 {
+			if q[i] < 0 {
+				return t0, t1, false
+			}
+			continue
+		}
+		r := q[i] / p[i]
+		if p[i] < 0 {
+			if r > t1 {
+				return t0, t1, false
+			}
+			if r > t0 {
+				t0 = r
+			}
+		} else {
+			if r < t0 {
+				return t0, t1, false
+			}
+			if r < t1 {
+				t1 = r
+			}
+		}
+	}
+	return t0, t1, true
+}
+
+// interpolate returns the point at fraction t along the segment from a to b.
+func interpolate(a []float64, b []float64, t float64) []float64 {
+	return []float64{a[0] + (b[0]-a[0])*t, a[1] + (b[1]-a[1])*t}
+}
+
+// clipLine clips a line to the square from min to max on both axes.
+// A line that leaves and re-enters the square is split into multiple lines.
+func clipLine(line [][]float64, min float64, max float64) [][][]float64 {
+	lines := make([][][]float64, 0)
+	var part [][]float64
+	flush := func() {
+		if len(part) > 1 {
+			lines = append(lines, part)
+		}
+		part = nil
+	}
+	for i := 1; i < len(line); i++ {
+		t0, t1, ok := clipSegment(line[i-1], line[i], min, max)
+		if !ok {
+			flush()
+			continue
+		}
+		if part == nil || t0 > 0 {
+			flush()
+			part = [][]float64{interpolate(line[i-1], line[i], t0)}
+		}
+		part = append(part, interpolate(line[i-1], line[i], t1))
+		if t1 < 1 {
+			flush()
+		}
+	}
+	flush()
+	return lines
+}
+
+// clipRing clips a closed ring to the square from min to max on both axes with the Sutherland-Hodgman algorithm.
+// Parts of the ring outside the square are replaced by segments along the edges of the square.
+// Returns the clipped ring, closed, or nil if the ring is outside the square.
+func clipRing(ring [][]float64, min float64, max float64) [][]float64 {
+	points := ring
+	if len(points) > 1 && points[0][0] == points[len(points)-1][0] && points[0][1] == points[len(points)-1][1] {
+		points = points[:len(points)-1]
+	}
+
+	edges := []struct {
+		axis    int
+		bound   float64
+		greater bool
+	}{
+		{axis: 0, bound: min, greater: true},
+		{axis: 0, bound: max, greater: false},
+		{axis: 1, bound: min, greater: true},
+		{axis: 1, bound: max, greater: false},
+	}
+
+	for _, e := range edges {
+		if len(points) == 0 {
+			break
+		}
+		inside := func(p []float64) bool {
+			if e.greater {
+				return p[e.axis] >= e.bound
+			}
+			return p[e.axis] <= e.bound
+		}
+		clipped := make([][]float64, 0, len(points)+4)
+		prev := points[len(points)-1]
+		for _, p := range points {
+			if inside(p) {
+				if !inside(prev) {
+					clipped = append(clipped, interpolate(prev, p, (e.bound-prev[e.axis])/(p[e.axis]-prev[e.axis])))
+				}
+				clipped = append(clipped, p)
+			} else if inside(prev) {
+				clipped = append(clipped, interpolate(prev, p, (e.bound-prev[e.axis])/(p[e.axis]-prev[e.axis])))
+			}
+			prev = p
+		}
+		points = clipped
+	}
+
+	if len(points) < 3 {
+		return nil
+	}
+	return append(points, points[0])
+}
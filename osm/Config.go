@@ -1,6 +1,12 @@
 // +build !js
 package osm
 
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
 import (
 	"github.com/pkg/errors"
 )
@@ -17,8 +23,11 @@ type Globals struct {
 type Config struct {
 	Globals               Globals        `hcl:"globals,omitempty"`
 	InputConfigs          []InputConfig  `hcl:"inputs,omitempty"`
+	ChangeConfigs         []InputConfig  `hcl:"changes,omitempty"` // osmChange or o5c files applied in order to the planet after the inputs are read
 	OutputConfigs         []OutputConfig `hcl:"outputs,omitempty"`
+	MergePolicy           string         `hcl:"merge_policy"` // policy for resolving elements present in multiple inputs: error, first, newest-version, or newest-timestamp.  Defaults to first.
 	Inputs                []*Input       `hcl:"-"`
+	Changes               []*Input       `hcl:"-"`
 	Outputs               []*Output      `hcl:"-"`
 	DropAllNodes          bool           `hcl:"-"`
 	DropAllWays           bool           `hcl:"-"`
@@ -35,6 +44,10 @@ type Config struct {
 
 func (c *Config) Init(ctx map[string]interface{}, funcs *dfl.FunctionMap) error {
 
+	if len(c.MergePolicy) == 0 {
+		c.MergePolicy = MERGE_FIRST
+	}
+
 	c.Inputs = make([]*Input, len(c.InputConfigs))
 	for i, x := range c.InputConfigs {
 
@@ -56,6 +69,18 @@ func (c *Config) Init(ctx map[string]interface{}, funcs *dfl.FunctionMap) error
 				KeysToKeep:    x.KeysToKeep,
 				KeysToDrop:    x.KeysToDrop,
 			},
+			Format:      x.Format,
+			History:     x.History,
+			MergePolicy: c.MergePolicy,
+			StartId:     x.StartId,
+		}
+
+		if len(x.SnapshotAt) > 0 {
+			snapshot_at, err := time.Parse(time.RFC3339, x.SnapshotAt)
+			if err != nil {
+				return errors.Wrap(err, "Error parsing snapshot_at "+x.SnapshotAt+" for input "+x.Uri+".  Expecting RFC 3339 format, e.g., 2018-01-01T00:00:00Z.")
+			}
+			input.SnapshotAt = &snapshot_at
 		}
 
 		err := input.Init(c.Globals.Input, ctx, funcs)
@@ -65,6 +90,37 @@ func (c *Config) Init(ctx map[string]interface{}, funcs *dfl.FunctionMap) error
 		c.Inputs[i] = input
 	}
 
+	c.Changes = make([]*Input, len(c.ChangeConfigs))
+	for i, x := range c.ChangeConfigs {
+
+		change := &Input{
+			PlanetResource: &PlanetResource{
+				FilteredResource: &FilteredResource{
+					Resource: &Resource{
+						Uri: x.Uri,
+					},
+					Filter: x.Filter,
+				},
+				DropWays:      x.DropWays,
+				DropRelations: x.DropRelations,
+				DropVersion:   x.DropVersion,
+				DropChangeset: x.DropChangeset,
+				DropTimestamp: x.DropTimestamp,
+				DropUserId:    x.DropUserId,
+				DropUserName:  x.DropUserName,
+				KeysToKeep:    x.KeysToKeep,
+				KeysToDrop:    x.KeysToDrop,
+			},
+			Format: x.Format,
+		}
+
+		err := change.Init(c.Globals.Input, ctx, funcs)
+		if err != nil {
+			return err
+		}
+		c.Changes[i] = change
+	}
+
 	c.Outputs = make([]*Output, len(c.OutputConfigs))
 	for i, x := range c.OutputConfigs {
 
@@ -86,8 +142,23 @@ func (c *Config) Init(ctx map[string]interface{}, funcs *dfl.FunctionMap) error
 				KeysToKeep:    x.KeysToKeep,
 				KeysToDrop:    x.KeysToDrop,
 			},
-			WaysToNodes: x.WaysToNodes,
-			Pretty:      x.Pretty,
+			Format:        x.Format,
+			WaysToNodes:   x.WaysToNodes,
+			Pretty:        x.Pretty,
+			RelationTypes: x.RelationTypes,
+			AreaRules:     x.AreaRules,
+			MinZoom:       x.MinZoom,
+			MaxZoom:       x.MaxZoom,
+			Layer:         x.Layer,
+			TileExtent:    x.TileExtent,
+			TileBuffer:    x.TileBuffer,
+			Simplify:      x.Simplify,
+			Columns:       x.Columns,
+			GDALIniUri:    x.GDALIniUri,
+			GDALLayers:    x.GDALLayers,
+			SQLSrid:       x.SQLSrid,
+			SQLTags:       x.SQLTags,
+			SQLPrefix:     x.SQLPrefix,
 		}
 
 		err := output.Init(c.Globals.Output, ctx, funcs)
@@ -194,9 +265,10 @@ func (c *Config) Init(ctx map[string]interface{}, funcs *dfl.FunctionMap) error
 	}
 	c.DropAllUserNames = drop_user_names
 
+	// SQL outputs promote the keys to keep to columns and still write every other tag in the tags column, so they keep all keys.
 	output_keys_keep := NewStringSet()
 	for _, o := range c.Outputs {
-		if len(o.KeysToKeep) == 0 {
+		if len(o.KeysToKeep) == 0 || o.IsSQL() {
 			output_keys_keep = NewStringSet()
 			break
 		}
@@ -214,7 +286,11 @@ func (c *Config) Init(ctx map[string]interface{}, funcs *dfl.FunctionMap) error
 	}
 	c.OutputKeysToDrop = output_keys_drop
 
-	for _, i := range c.Inputs {
+	// Changes drop the same attributes and keys as inputs, so changed elements match the elements in the planet.
+	inputs := make([]*Input, 0, len(c.Inputs)+len(c.Changes))
+	inputs = append(inputs, c.Inputs...)
+	inputs = append(inputs, c.Changes...)
+	for _, i := range inputs {
 		err := i.Init(c.Globals.Input, ctx, funcs)
 		if err != nil {
 			return err
@@ -228,7 +304,8 @@ func (c *Config) Init(ctx map[string]interface{}, funcs *dfl.FunctionMap) error
 			i.DropRelations = true
 		}
 
-		if c.DropAllVersions {
+		// The versions and timestamps are kept while reading if the merge policy compares them.  Outputs still drop them when writing.
+		if c.DropAllVersions && c.MergePolicy != MERGE_NEWEST_VERSION && c.MergePolicy != MERGE_NEWEST_TIMESTAMP {
 			i.DropVersion = true
 		}
 
@@ -236,7 +313,7 @@ func (c *Config) Init(ctx map[string]interface{}, funcs *dfl.FunctionMap) error
 			i.DropChangeset = true
 		}
 
-		if c.DropAllTimestamps {
+		if c.DropAllTimestamps && c.MergePolicy != MERGE_NEWEST_TIMESTAMP {
 			i.DropTimestamp = true
 		}
 
@@ -277,6 +354,12 @@ func (c *Config) HasResourceType(t string) bool {
 			break
 		}
 	}
+	for _, i := range c.Changes {
+		if i.Type == t {
+			has = true
+			break
+		}
+	}
 	for _, o := range c.Outputs {
 		if o.Type == t {
 			has = true
@@ -295,6 +378,12 @@ func (c *Config) GetNameNodes() []string {
 		}
 	}
 
+	for _, change := range c.Changes {
+		if change.IsType("hdfs") {
+			nameNodes = append(nameNodes, change.NameNode)
+		}
+	}
+
 	for _, output := range c.Outputs {
 		if output.IsType("hdfs") {
 			nameNodes = append(nameNodes, output.NameNode)
@@ -311,14 +400,44 @@ func (c *Config) HasDrop() bool {
 
 func (c *Config) Validate() error {
 
+	if !stringSliceContains(MERGE_POLICIES, c.MergePolicy) {
+		return errors.New("Error: merge_policy " + c.MergePolicy + " is not supported.  Expecting one of " + strings.Join(MERGE_POLICIES, ", ") + ".")
+	}
+
 	for _, input := range c.Inputs {
 		if len(input.Uri) == 0 {
 			return errors.New("Error: input_uri is missing.")
 		}
+		if input.DropVersion && (c.MergePolicy == MERGE_NEWEST_VERSION || c.MergePolicy == MERGE_NEWEST_TIMESTAMP) {
+			return errors.New("Error: input " + input.Uri + " drops versions, which are required by merge_policy " + c.MergePolicy + ".")
+		}
+		if input.DropTimestamp && c.MergePolicy == MERGE_NEWEST_TIMESTAMP {
+			return errors.New("Error: input " + input.Uri + " drops timestamps, which are required by merge_policy " + c.MergePolicy + ".")
+		}
+	}
+
+	for _, change := range c.Changes {
+		if change.History || change.SnapshotAt != nil {
+			return errors.New("Error: change at " + change.Uri + " cannot be a full-history file.")
+		}
+		if len(change.Uri) == 0 {
+			return errors.New("Error: change_uri is missing.")
+		}
+		if change.Format != "osc" && change.Format != "o5c" {
+			return errors.New("Error: change at " + change.Uri + " is not an osc or o5c file.")
+		}
 	}
 
 	for _, output := range c.Outputs {
 
+		format := output.Format
+		if len(format) == 0 {
+			format = InferFormat(output.Uri)
+		}
+		if format == "osc" {
+			return errors.New("Error: output " + output.Uri + " cannot be an osmChange file.  osmChange files can only be read with change_uri.")
+		}
+
 		if output.WaysToNodes && output.DropWays {
 			return errors.New("Error: cannot enable ways_to_nodes and drop_ways at the same time.")
 		}
@@ -327,6 +446,44 @@ func (c *Config) Validate() error {
 			return errors.New("Error: you cannot drop nodes, ways, and relations.  Output will be empty.")
 		}
 
+		if output.IsGDAL() {
+			layers := output.GetGDALLayers()
+			for _, layer := range layers {
+				if !stringSliceContains(GDAL_LAYERS, layer) {
+					return errors.New("Error: output " + output.Uri + " has unknown GDAL layer " + layer + ".  Expecting points, lines, multipolygons, multilinestrings, or other_relations.")
+				}
+			}
+			if len(layers) > 1 && !strings.Contains(output.Uri, "{layer}") {
+				return errors.New("Error: output " + output.Uri + " writes multiple GDAL layers, so its uri must contain {layer}, e.g., osm_{layer}.csv.")
+			}
+		}
+
+		if output.IsSQL() {
+			if output.SQLSrid != 4326 && output.SQLSrid != 3857 {
+				return errors.New("Error: output " + output.Uri + " has unsupported sql_srid " + strconv.Itoa(output.SQLSrid) + ".  Expecting 4326 or 3857.")
+			}
+			if output.SQLTags != "hstore" && output.SQLTags != "jsonb" {
+				return errors.New("Error: output " + output.Uri + " has unsupported sql_tags " + output.SQLTags + ".  Expecting hstore or jsonb.")
+			}
+			for i, key := range output.KeysToKeep {
+				if stringSliceContains(SQL_COLUMNS, key) {
+					return errors.New("Error: output " + output.Uri + " cannot keep key " + key + " as a column, since it is the same as the " + key + " column of sql outputs.")
+				}
+				if stringSliceContains(output.KeysToKeep[:i], key) {
+					return errors.New("Error: output " + output.Uri + " keeps key " + key + " more than once.  Each key to keep is a column of sql outputs, so keys to keep must be unique.")
+				}
+			}
+		}
+
+		if output.IsTileset() {
+			if output.MinZoom < 0 || output.MaxZoom > MAX_ZOOM || output.MinZoom > output.MaxZoom {
+				return errors.New("Error: output " + output.Uri + " has invalid zoom levels " + strconv.Itoa(output.MinZoom) + " to " + strconv.Itoa(output.MaxZoom) + ".  Expecting zoom levels between 0 and " + strconv.Itoa(MAX_ZOOM) + ".")
+			}
+			if output.TileExtent < 1 || output.TileBuffer < 0 || output.TileBuffer > output.TileExtent {
+				return errors.New("Error: output " + output.Uri + " has invalid tile_extent " + strconv.Itoa(output.TileExtent) + " or tile_buffer " + strconv.Itoa(output.TileBuffer) + ".")
+			}
+		}
+
 	}
 
 	return nil
@@ -0,0 +1,141 @@
+package osm
+
+// transformLine returns a copy of the line scaled by scale and then moved by dx and dy.
+func transformLine(line [][]float64, scale float64, dx float64, dy float64) [][]float64 {
+	transformed := make([][]float64, 0, len(line))
+	for _, c := range line {
+		transformed = append(transformed, []float64{c[0]*scale + dx, c[1]*scale + dy})
+	}
+	return transformed
+}
+
+// cutTileFeature cuts a feature into the tiles from zoom level min_zoom to max_zoom that it intersects, and calls add with the encoded geometry of the feature in each tile.
+// Points are added to the tile that contains them.  See pixelTile.
+// Lines and polygons are projected to pixel coordinates once and then clipped recursively, from tile 0/0/0 down to max_zoom, to each tile and a buffer around it,
+// so each tile only clips what is left of the feature in its parent tile and features crossing tile edges are drawn without seams.
+// The geometry in each tile is simplified with the tolerance in pixels after it is clipped.
+// Parts of the feature that collapse at a zoom level are dropped, and add is not called for tiles where nothing is left.
+func cutTileFeature(f *TileFeature, min_zoom int, max_zoom int, extent int, buffer int, tolerance float64, add func(z int, x int, y int, geometry_type int, geometry []uint64)) {
+
+	tile_size := float64(extent)
+
+	switch f.Type() {
+	case MVT_POINT:
+		for z := min_zoom; z <= max_zoom; z++ {
+			tiles := map[[2]int][][]float64{}
+			keys := make([][2]int, 0)
+			for _, c := range f.Points {
+				px, py := projectTile(c[0], c[1], z, extent)
+				x, y := pixelTile(px, py, z, extent)
+				k := [2]int{x, y}
+				if _, ok := tiles[k]; !ok {
+					keys = append(keys, k)
+				}
+				tiles[k] = append(tiles[k], []float64{px - float64(x)*tile_size, py - float64(y)*tile_size})
+			}
+			for _, k := range keys {
+				e := &tileGeometryEncoder{}
+				e.AddPoints(tiles[k])
+				add(z, k[0], k[1], MVT_POINT, e.Geometry)
+			}
+		}
+		return
+	case MVT_LINESTRING, MVT_POLYGON:
+	default:
+		return
+	}
+
+	// Project the geometry once to the pixel coordinates of tile 0/0/0.
+	lines := make([][][]float64, 0, len(f.Lines))
+	for _, line := range f.Lines {
+		lines = append(lines, projectTileLine(line, 0, extent))
+	}
+	polygons := make([][][][]float64, 0, len(f.Polygons))
+	for _, polygon := range f.Polygons {
+		rings := make([][][]float64, 0, len(polygon))
+		for _, ring := range polygon {
+			rings = append(rings, projectTileLine(ring, 0, extent))
+		}
+		if len(rings) > 0 {
+			polygons = append(polygons, rings)
+		}
+	}
+
+	pad := float64(buffer)
+
+	// cut clips the geometry, in the pixel coordinates of tile z/x/y, to the tile and its buffer,
+	// adds what is left to the tile, and then cuts it into the 4 tiles at the next zoom level.
+	var cut func(z int, x int, y int, lines [][][]float64, polygons [][][][]float64)
+	cut = func(z int, x int, y int, lines [][][]float64, polygons [][][][]float64) {
+
+		clipped_lines := make([][][]float64, 0, len(lines))
+		for _, line := range lines {
+			clipped_lines = append(clipped_lines, clipLine(line, -pad, tile_size+pad)...)
+		}
+		clipped_polygons := make([][][][]float64, 0, len(polygons))
+		for _, rings := range polygons {
+			outer := clipRing(rings[0], -pad, tile_size+pad)
+			if outer == nil {
+				continue
+			}
+			clipped := [][][]float64{outer}
+			for _, hole := range rings[1:] {
+				if h := clipRing(hole, -pad, tile_size+pad); h != nil {
+					clipped = append(clipped, h)
+				}
+			}
+			clipped_polygons = append(clipped_polygons, clipped)
+		}
+		if len(clipped_lines) == 0 && len(clipped_polygons) == 0 {
+			return
+		}
+
+		if z >= min_zoom {
+			e := &tileGeometryEncoder{}
+			for _, line := range clipped_lines {
+				e.AddLine(simplifyLine(line, tolerance))
+			}
+			for _, rings := range clipped_polygons {
+				outer := simplifyLine(rings[0], tolerance)
+				if len(outer) < 4 || !e.AddRing(outer, true) {
+					continue
+				}
+				for _, hole := range rings[1:] {
+					if h := simplifyLine(hole, tolerance); len(h) >= 4 {
+						e.AddRing(h, false)
+					}
+				}
+			}
+			if len(e.Geometry) > 0 {
+				add(z, x, y, f.Type(), e.Geometry)
+			}
+		}
+
+		if z >= max_zoom {
+			return
+		}
+
+		// Each child tile covers a quarter of the tile at twice the scale.
+		for i := 0; i < 2; i++ {
+			for j := 0; j < 2; j++ {
+				dx := -float64(i) * tile_size
+				dy := -float64(j) * tile_size
+				child_lines := make([][][]float64, 0, len(clipped_lines))
+				for _, line := range clipped_lines {
+					child_lines = append(child_lines, transformLine(line, 2, dx, dy))
+				}
+				child_polygons := make([][][][]float64, 0, len(clipped_polygons))
+				for _, rings := range clipped_polygons {
+					child_rings := make([][][]float64, 0, len(rings))
+					for _, ring := range rings {
+						child_rings = append(child_rings, transformLine(ring, 2, dx, dy))
+					}
+					child_polygons = append(child_polygons, child_rings)
+				}
+				cut(z+1, 2*x+i, 2*y+j, child_lines, child_polygons)
+			}
+		}
+	}
+
+	cut(0, 0, 0, lines, polygons)
+}
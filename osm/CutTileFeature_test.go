@@ -0,0 +1,109 @@
+package osm
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCutTileFeature(t *testing.T) {
+	testCases := []struct {
+		name     string
+		feature  *TileFeature
+		buffer   int
+		expected [][3]int // z, x, y of each tile with the feature
+	}{
+		{
+			name:     "point",
+			feature:  &TileFeature{Points: [][]float64{[]float64{-77.0365, 38.8977}}},
+			expected: [][3]int{[3]int{0, 0, 0}, [3]int{1, 0, 0}, [3]int{2, 1, 1}},
+		},
+		{
+			name:     "line across the prime meridian",
+			feature:  &TileFeature{Lines: [][][]float64{[][]float64{[]float64{-10, 10}, []float64{10, 10}}}},
+			expected: [][3]int{[3]int{0, 0, 0}, [3]int{1, 0, 0}, [3]int{2, 1, 1}, [3]int{1, 1, 0}, [3]int{2, 2, 1}},
+		},
+		{
+			name:     "line in the buffer of the tiles to the west",
+			feature:  &TileFeature{Lines: [][][]float64{[][]float64{[]float64{1, 10}, []float64{10, 10}}}},
+			buffer:   64,
+			expected: [][3]int{[3]int{0, 0, 0}, [3]int{1, 0, 0}, [3]int{2, 1, 1}, [3]int{1, 1, 0}, [3]int{2, 2, 1}},
+		},
+		{
+			name: "polygon",
+			feature: &TileFeature{Polygons: [][][][]float64{[][][]float64{[][]float64{
+				[]float64{10, 10}, []float64{20, 10}, []float64{20, 20}, []float64{10, 20}, []float64{10, 10},
+			}}}},
+			expected: [][3]int{[3]int{0, 0, 0}, [3]int{1, 1, 0}, [3]int{2, 2, 1}},
+		},
+	}
+	for _, tc := range testCases {
+		actual := make([][3]int, 0)
+		cutTileFeature(tc.feature, 0, 2, 4096, tc.buffer, 0, func(z int, x int, y int, geometry_type int, geometry []uint64) {
+			actual = append(actual, [3]int{z, x, y})
+		})
+		if len(actual) != len(tc.expected) {
+			t.Fatalf("%s: expected %v, got %v.", tc.name, tc.expected, actual)
+		}
+		for i := range tc.expected {
+			if actual[i] != tc.expected[i] {
+				t.Fatalf("%s: expected %v, got %v.", tc.name, tc.expected, actual)
+			}
+		}
+	}
+}
+
+func TestCutTileFeatureClip(t *testing.T) {
+	f := &TileFeature{Lines: [][][]float64{[][]float64{[]float64{-10, 10}, []float64{10, 10}}}}
+
+	var geometry []uint64
+	cutTileFeature(f, 2, 2, 4096, 0, 0, func(z int, x int, y int, geometry_type int, g []uint64) {
+		if x == 2 && y == 1 {
+			geometry = g
+		}
+	})
+
+	// The part of the line in tile 2/2/1 starts at the left edge of the tile.
+	px, py := projectTile(10, 10, 2, 4096)
+	dx := int64(math.Round(px - 2*4096))
+	dy := int64(math.Round(py - 4096))
+	expected := []uint64{9, 0, uint64(dy << 1), 10, uint64(dx << 1), 0}
+	if len(geometry) != len(expected) {
+		t.Fatalf("Expected %v, got %v.", expected, geometry)
+	}
+	for i := range expected {
+		if geometry[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v.", expected, geometry)
+		}
+	}
+}
+
+func TestCutTileFeaturePointTile(t *testing.T) {
+	// Points are binned into the same tiles as Node.Tile, including on the edges of tiles and beyond the limits of Web Mercator.
+	for _, c := range [][]float64{[]float64{0, 0}, []float64{-77.0365, 38.8977}, []float64{180, 89}, []float64{-180, -89}} {
+		f := &TileFeature{Points: [][]float64{c}}
+		cutTileFeature(f, 0, 10, 4096, 0, 0, func(z int, x int, y int, geometry_type int, geometry []uint64) {
+			expected := Node{Longitude: c[0], Latitude: c[1]}.Tile(z)
+			if x != expected[0] || y != expected[1] {
+				t.Fatalf("Expected point %v at zoom level %d in tile %v, got [%d %d].", c, z, expected, x, y)
+			}
+		})
+	}
+}
+
+func TestTileFeatureId(t *testing.T) {
+	testCases := []struct {
+		element_type string
+		expected     uint64
+	}{
+		{element_type: "node", expected: 421},
+		{element_type: "way", expected: 422},
+		{element_type: "relation", expected: 423},
+		{element_type: "", expected: 0},
+	}
+	for _, tc := range testCases {
+		f := &TileFeature{Id: 42, ElementType: tc.element_type}
+		if id := f.FeatureId(); id != tc.expected {
+			t.Fatalf("Expected %s 42 to have feature id %d, got %d.", tc.element_type, tc.expected, id)
+		}
+	}
+}
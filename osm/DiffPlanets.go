@@ -0,0 +1,69 @@
+package osm
+
+import (
+	"github.com/pkg/errors"
+)
+
+// DiffPlanets compares the old and new planets by element id and version and returns the change that turns the old planet into the new planet.
+// Elements only in the new planet are created, elements in both planets with different versions are modified, and elements only in the old planet are deleted.
+// If the versions of an element are equal or not set, then the element is modified if its tags, coordinates, node references, or members differ.
+// Created and modified elements are from the new planet and deleted elements are from the old planet.
+// The planets must share a TagsCache, so the tags of every element in the change can be resolved through either planet.
+// Returns the change and an error if any.
+func DiffPlanets(old *Planet, new *Planet) (*Change, error) {
+
+	if old.Tags != new.Tags {
+		return nil, errors.New("Cannot diff planets that do not share a tags cache.")
+	}
+
+	create := &ChangeAction{Type: CHANGE_CREATE}
+	modify := &ChangeAction{Type: CHANGE_MODIFY}
+	remove := &ChangeAction{Type: CHANGE_DELETE}
+
+	for _, n := range new.Nodes {
+		if i, ok := old.nodesIndex[n.Id]; !ok {
+			create.Nodes = append(create.Nodes, n)
+		} else if nodeChanged(old.Nodes[i], n) {
+			modify.Nodes = append(modify.Nodes, n)
+		}
+	}
+	for _, w := range new.Ways {
+		if i, ok := old.waysIndex[w.Id]; !ok {
+			create.Ways = append(create.Ways, w)
+		} else if wayChanged(old.Ways[i], w) {
+			modify.Ways = append(modify.Ways, w)
+		}
+	}
+	for _, r := range new.Relations {
+		if i, ok := old.relationsIndex[r.Id]; !ok {
+			create.Relations = append(create.Relations, r)
+		} else if relationChanged(old.Relations[i], r) {
+			modify.Relations = append(modify.Relations, r)
+		}
+	}
+
+	for _, n := range old.Nodes {
+		if _, ok := new.nodesIndex[n.Id]; !ok {
+			remove.Nodes = append(remove.Nodes, n)
+		}
+	}
+	for _, w := range old.Ways {
+		if _, ok := new.waysIndex[w.Id]; !ok {
+			remove.Ways = append(remove.Ways, w)
+		}
+	}
+	for _, r := range old.Relations {
+		if _, ok := new.relationsIndex[r.Id]; !ok {
+			remove.Relations = append(remove.Relations, r)
+		}
+	}
+
+	c := &Change{Version: "0.6", Actions: make([]*ChangeAction, 0, 3)}
+	for _, a := range []*ChangeAction{create, modify, remove} {
+		if len(a.Nodes) > 0 || len(a.Ways) > 0 || len(a.Relations) > 0 {
+			c.Actions = append(c.Actions, a)
+		}
+	}
+
+	return c, nil
+}
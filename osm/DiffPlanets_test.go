@@ -0,0 +1,126 @@
+package osm
+
+import (
+	"testing"
+)
+
+func TestDiffPlanets(t *testing.T) {
+	testCases := []struct {
+		name     string
+		edit     func(old *Planet, new *Planet)
+		expected map[string][]uint64 // ids of the elements in each action
+	}{
+		{
+			name:     "unchanged",
+			edit:     func(old *Planet, new *Planet) {},
+			expected: map[string][]uint64{},
+		},
+		{
+			name: "version",
+			edit: func(old *Planet, new *Planet) {
+				new.Nodes[0].Version = 4
+			},
+			expected: map[string][]uint64{CHANGE_MODIFY: []uint64{1}},
+		},
+		{
+			name: "coordinates without versions",
+			edit: func(old *Planet, new *Planet) {
+				for _, p := range []*Planet{old, new} {
+					for _, n := range p.Nodes {
+						n.Version = 0
+					}
+				}
+				new.Nodes[1].Longitude = 2.2946
+			},
+			expected: map[string][]uint64{CHANGE_MODIFY: []uint64{2}},
+		},
+		{
+			name: "tags with equal versions",
+			edit: func(old *Planet, new *Planet) {
+				new.Ways[0].TagsIndex = new.AddTags([]Tag{Tag{Key: "highway", Value: "primary"}})
+			},
+			expected: map[string][]uint64{CHANGE_MODIFY: []uint64{10}},
+		},
+		{
+			name: "tags in a different order",
+			edit: func(old *Planet, new *Planet) {
+				tags := []Tag{Tag{Key: "highway", Value: "residential"}, Tag{Key: "name", Value: "Main Street"}}
+				old.Ways[0].TagsIndex = old.AddTags(tags)
+				new.Ways[0].TagsIndex = new.AddTags([]Tag{tags[1], tags[0]})
+			},
+			expected: map[string][]uint64{},
+		},
+		{
+			name: "node references",
+			edit: func(old *Planet, new *Planet) {
+				new.Ways[0].NodeReferences = []NodeReference{NodeReference{Reference: 1}, NodeReference{Reference: 2}}
+			},
+			expected: map[string][]uint64{CHANGE_MODIFY: []uint64{10}},
+		},
+		{
+			name: "members",
+			edit: func(old *Planet, new *Planet) {
+				new.Relations[0].Members[0].Role = "backward"
+			},
+			expected: map[string][]uint64{CHANGE_MODIFY: []uint64{20}},
+		},
+		{
+			name: "create and delete",
+			edit: func(old *Planet, new *Planet) {
+				n := &Node{Longitude: 1, Latitude: 1}
+				n.Id = 3
+				new.AddNode(n)
+				n = &Node{Longitude: 1, Latitude: 1}
+				n.Id = 4
+				old.AddNode(n)
+			},
+			expected: map[string][]uint64{CHANGE_CREATE: []uint64{3}, CHANGE_DELETE: []uint64{4}},
+		},
+	}
+
+	for _, tc := range testCases {
+		old := newTestPlanet(t)
+		new := newTestPlanet(t)
+		// The test planets add the same tags in the same order, so the tags of the new planet can be resolved through the old planet.
+		new.Tags = old.Tags
+		tc.edit(old, new)
+
+		c, err := DiffPlanets(old, new)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+
+		actual := map[string][]uint64{}
+		for _, a := range c.Actions {
+			for _, n := range a.Nodes {
+				actual[a.Type] = append(actual[a.Type], n.Id)
+			}
+			for _, w := range a.Ways {
+				actual[a.Type] = append(actual[a.Type], w.Id)
+			}
+			for _, r := range a.Relations {
+				actual[a.Type] = append(actual[a.Type], r.Id)
+			}
+		}
+		if len(actual) != len(tc.expected) {
+			t.Fatalf("%s: expected %v, got %v.", tc.name, tc.expected, actual)
+		}
+		for action, ids := range tc.expected {
+			if len(actual[action]) != len(ids) {
+				t.Fatalf("%s: expected %v, got %v.", tc.name, tc.expected, actual)
+			}
+			for i := range ids {
+				if actual[action][i] != ids[i] {
+					t.Fatalf("%s: expected %v, got %v.", tc.name, tc.expected, actual)
+				}
+			}
+		}
+	}
+}
+
+func TestDiffPlanetsTagsCache(t *testing.T) {
+	_, err := DiffPlanets(newTestPlanet(t), newTestPlanet(t))
+	if err == nil {
+		t.Fatal("Expected an error for planets that do not share a tags cache.")
+	}
+}
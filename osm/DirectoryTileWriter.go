@@ -0,0 +1,49 @@
+package osm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// DirectoryTileWriter writes each tile to its own file, with a path built from a template such as tiles/{z}/{x}/{y}.pbf.
+// The placeholders {z}, {x}, and {y} are replaced with the tile coordinates and missing directories are created.
+// Tiles are written uncompressed, so they can be served as static files.
+type DirectoryTileWriter struct {
+	Template string // template of the path of each tile
+}
+
+// NewDirectoryTileWriter returns a new DirectoryTileWriter for the path template.
+func NewDirectoryTileWriter(template string) *DirectoryTileWriter {
+	return &DirectoryTileWriter{Template: template}
+}
+
+// Path returns the path of the tile.
+func (w *DirectoryTileWriter) Path(z int, x int, y int) string {
+	return strings.NewReplacer("{z}", strconv.Itoa(z), "{x}", strconv.Itoa(x), "{y}", strconv.Itoa(y)).Replace(w.Template)
+}
+
+// WriteTile writes the tile to its path, creating directories as needed.
+func (w *DirectoryTileWriter) WriteTile(z int, x int, y int, data []byte) error {
+	path := w.Path(z, x, y)
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return errors.Wrap(err, "Error creating directory for tile "+path)
+	}
+	err = ioutil.WriteFile(path, data, 0644)
+	if err != nil {
+		return errors.Wrap(err, "Error writing tile "+path)
+	}
+	return nil
+}
+
+// Close does nothing, since every tile is written to disk by WriteTile.
+func (w *DirectoryTileWriter) Close() error {
+	return nil
+}
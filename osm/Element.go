@@ -6,12 +6,14 @@ import (
 
 // Element is the base abstract element that Nodes, Ways, and Relations extend.
 type Element struct {
-	Id        uint64     `xml:"id,attr" parquet:"name=id, type=UINT_64"`                                // The planet-wide unique (literally) id of the element.
-	Version   uint16     `xml:"version,attr,omitempty" parquet:"name=version type=UINT_16"`             // The version of this element.  Every modification increments this counter.
-	Timestamp *time.Time `xml:"timestamp,attr,omitempty" parquet:"name=timestamp type=TimestampMicros"` // The timestamp of when this element was created or last modified.
-	Changeset uint64     `xml:"changeset,attr,omitempty" parquet:"name=changest type=UINT_64"`          // The ID of the changeset that created or modified this element.
-	UserId    uint64     `xml:"uid,attr,omitempty" parquet:"name=uid, type=UINT_64"`                    // The id of the user that created or last modified this element.
-	UserName  string     `xml:"user,attr,omitempty"`                                                    // The name of the user that created or last modified this element.
+	Id          uint64     `xml:"id,attr" parquet:"name=id, type=UINT_64"`                                // The planet-wide unique (literally) id of the element.
+	Version     uint16     `xml:"version,attr,omitempty" parquet:"name=version type=UINT_16"`             // The version of this element.  Every modification increments this counter.
+	Timestamp   *time.Time `xml:"timestamp,attr,omitempty" parquet:"name=timestamp type=TimestampMicros"` // The timestamp of when this element was created or last modified.
+	Changeset   uint64     `xml:"changeset,attr,omitempty" parquet:"name=changest type=UINT_64"`          // The ID of the changeset that created or modified this element.
+	UserId      uint64     `xml:"uid,attr,omitempty" parquet:"name=uid, type=UINT_64"`                    // The id of the user that created or last modified this element.
+	UserName    string     `xml:"user,attr,omitempty"`                                                    // The name of the user that created or last modified this element.
+	Visible     *bool      `xml:"visible,attr,omitempty"`                                                 // False if this version of the element is deleted.  Only set by full-history files.
+	Placeholder bool       `xml:"-"`                                                                      // True if the element does not exist in OSM yet, e.g., because it was converted from GeoJSON.
 }
 
 // GetId returns the element's ID as an int64
@@ -19,6 +21,29 @@ func (e *Element) GetId() uint64 {
 	return e.Id
 }
 
+// SignedId returns the id of the element as written to OSM files, which is negative if the element is a placeholder.
+// Editors such as JOSM treat elements with negative ids as new elements that have not been uploaded yet.
+func (e *Element) SignedId() int64 {
+	if e.Placeholder {
+		return -int64(e.Id)
+	}
+	return int64(e.Id)
+}
+
+// SignedReference returns the id of an element referenced by this element as written to OSM files.
+// Placeholder elements only reference other placeholder elements, so the reference is negative if this element is a placeholder.
+func (e *Element) SignedReference(ref uint64) int64 {
+	if e.Placeholder {
+		return -int64(ref)
+	}
+	return int64(ref)
+}
+
+// IsVisible returns false if this version of the element is deleted, otherwise true.
+func (e *Element) IsVisible() bool {
+	return e.Visible == nil || *e.Visible
+}
+
 // DropVersion sets the version to 0
 func (e *Element) DropVersion() {
 	e.Version = uint16(0)
@@ -0,0 +1,66 @@
+package osm
+
+import (
+	"math"
+)
+
+// WayBounds returns the bounding box of the nodes of the way that are in the planet.
+// Returns nil if none of the nodes are in the planet.
+func (p *Planet) WayBounds(w *Way) *Bounds {
+	var b *Bounds
+	for _, nr := range w.NodeReferences {
+		i, ok := p.nodesIndex[nr.Reference]
+		if !ok {
+			continue
+		}
+		n := p.Nodes[i]
+		if b == nil {
+			b = NewBounds(n.Longitude, n.Latitude, n.Longitude, n.Latitude)
+		} else {
+			b.ExtendPoint(n.Longitude, n.Latitude)
+		}
+	}
+	return b
+}
+
+// RelationBounds returns the bounding box of the members of the relation that are in the planet, including the members of nested relations.
+// Returns nil if none of the members are in the planet.
+func (p *Planet) RelationBounds(r *Relation) *Bounds {
+	return p.relationBounds(r, NewUInt64Set())
+}
+
+func (p *Planet) relationBounds(r *Relation, visited UInt64Set) *Bounds {
+	visited.Add(r.Id)
+	b := NewBounds(math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1))
+	found := false
+	for _, m := range r.Members {
+		switch m.Type {
+		case "node":
+			if i, ok := p.nodesIndex[m.Reference]; ok {
+				b.ExtendPoint(p.Nodes[i].Longitude, p.Nodes[i].Latitude)
+				found = true
+			}
+		case "way":
+			if i, ok := p.waysIndex[m.Reference]; ok {
+				if wb := p.WayBounds(p.Ways[i]); wb != nil {
+					b.Extend(wb)
+					found = true
+				}
+			}
+		case "relation":
+			if visited.Contains(m.Reference) {
+				continue
+			}
+			if i, ok := p.relationsIndex[m.Reference]; ok {
+				if rb := p.relationBounds(p.Relations[i], visited); rb != nil {
+					b.Extend(rb)
+					found = true
+				}
+			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,147 @@
+package osm
+
+import (
+	"io"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ElementDecoder is the interface shared by the pull-based decoders of OSM elements, e.g., XMLDecoder, PBFDecoder, and O5MDecoder.
+// Decode returns the next element as a *Node, *Way, or *Relation, with its Tags, UserId, and UserName set from the stream.
+// Decode returns io.EOF when there are no more elements.
+type ElementDecoder interface {
+	Decode() (interface{}, error)
+}
+
+// NewElementDecoder returns a new ElementDecoder for the given format reading from r.
+// Supports the osm, osc, pbf, o5m, o5c, osmjson, geojson, and geojsonl formats.
+func NewElementDecoder(r io.Reader, format string) (ElementDecoder, error) {
+	switch format {
+	case "osm", "osc", "":
+		return NewXMLDecoder(r), nil
+	case "pbf":
+		return NewPBFDecoder(r), nil
+	case "o5m", "o5c":
+		return NewO5MDecoder(r), nil
+	case "osmjson":
+		return NewOSMJSONDecoder(r), nil
+	case "geojson":
+		return NewGeoJSONDecoder(r), nil
+	case "geojsonl":
+		return NewGeoJSONLDecoder(r), nil
+	}
+	return nil, errors.New("Unknown input format " + format + ".")
+}
+
+// NewInputDecoder returns a new ElementDecoder reading the input in the input's format.
+// The decoder for a GeoJSON input allocates ids starting at the input's StartId, if set, otherwise at next_id,
+// so the ids do not collide with the elements of previous inputs.  If neither is set, then the ids start at 1.
+func NewInputDecoder(input *Input, next_id uint64) (ElementDecoder, error) {
+	decoder, err := NewElementDecoder(input.Reader, input.Format)
+	if err != nil {
+		return decoder, err
+	}
+	if d, ok := decoder.(*GeoJSONDecoder); ok {
+		if input.StartId > 0 {
+			d.StartId = input.StartId
+		} else if next_id > 0 {
+			d.StartId = next_id
+		}
+	}
+	return decoder, nil
+}
+
+// IsDeletion returns true if the element most recently returned by the decoder is a deletion rather than a current version of an element.
+func IsDeletion(decoder ElementDecoder) bool {
+	switch d := decoder.(type) {
+	case *XMLDecoder:
+		return d.Deleted()
+	case *O5MDecoder:
+		return d.Deleted()
+	}
+	return false
+}
+
+// GetChangeAction returns the osmChange action of the element most recently returned by the decoder: create, modify, or delete.
+// Elements in o5c change files are either deletions or modifications, since o5c does not distinguish between creating and modifying an element.
+// Elements in streams that are not change files are modifications.
+func GetChangeAction(decoder ElementDecoder) string {
+	if d, ok := decoder.(*XMLDecoder); ok && len(d.Action) > 0 {
+		return d.Action
+	}
+	if IsDeletion(decoder) {
+		return CHANGE_DELETE
+	}
+	return CHANGE_MODIFY
+}
+
+// ImportHeader sets the planet's version, generator, timestamp, and bounds from the header read by the decoder, if any.
+func (p *Planet) ImportHeader(decoder ElementDecoder) {
+	switch d := decoder.(type) {
+	case *XMLDecoder:
+		if len(d.Version) > 0 {
+			p.Version = d.Version
+		}
+		if len(d.Generator) > 0 {
+			p.Generator = d.Generator
+		}
+		if d.Timestamp != nil {
+			p.Timestamp = *d.Timestamp
+		}
+		if d.Bounds != nil {
+			p.Bounds = *d.Bounds
+		}
+	case *PBFDecoder:
+		if d.Header != nil {
+			if len(p.Version) == 0 && stringSliceContains(d.Header.RequiredFeatures, "OsmSchema-V0.6") {
+				p.Version = "0.6"
+			}
+			if d.Header.Bounds != nil {
+				p.Bounds = *d.Header.Bounds
+			}
+			if len(d.Header.WritingProgram) > 0 {
+				p.Generator = d.Header.WritingProgram
+			}
+			if d.Header.ReplicationTimestamp != nil {
+				p.Timestamp = *d.Header.ReplicationTimestamp
+			}
+		}
+	case *O5MDecoder:
+		if len(p.Version) == 0 {
+			p.Version = "0.6"
+		}
+		if d.Bounds != nil {
+			p.Bounds = *d.Bounds
+		}
+		if d.Timestamp != nil {
+			p.Timestamp = *d.Timestamp
+		}
+	case *OSMJSONDecoder:
+		if len(d.Version) > 0 {
+			p.Version = d.Version
+		}
+		if len(d.Generator) > 0 {
+			p.Generator = d.Generator
+		}
+		if d.Timestamp != nil {
+			p.Timestamp = *d.Timestamp
+		}
+		if d.Bounds != nil {
+			p.Bounds = *d.Bounds
+		}
+	case *GeoJSONDecoder:
+		if len(p.Version) == 0 {
+			p.Version = "0.6"
+		}
+		if d.Bounds != nil {
+			// The bounds of a previous input are extended, since the nodes of every input are in the planet.
+			if p.Bounds == (Bounds{}) {
+				p.Bounds = *d.Bounds
+			} else {
+				p.Bounds.Extend(d.Bounds)
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package osm
+
+import (
+	"io"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ElementEncoder is the interface shared by the encoders of OSM elements, e.g., XMLEncoder, PBFEncoder, O5MEncoder, and OSMJSONEncoder.
+// WriteHeader must be called before encoding any elements and Close must be called after the last element.
+// Close does not close the underlying writer.
+type ElementEncoder interface {
+	WriteHeader() error
+	EncodeNode(n *Node) error
+	EncodeWay(w *Way) error
+	EncodeRelation(r *Relation) error
+	Close() error
+}
+
+// NewElementEncoder returns a new ElementEncoder for the given format writing to w.
+// Tags and user names are resolved through the planet.
+// Supports the osm, pbf, o5m, o5c, and osmjson formats.
+func NewElementEncoder(w io.Writer, planet *Planet, output *Output, format string) (ElementEncoder, error) {
+	switch format {
+	case "osm":
+		return NewXMLEncoder(w, planet, output), nil
+	case "pbf":
+		return NewPBFEncoder(w, planet, output), nil
+	case "o5m":
+		return NewO5MEncoder(w, planet, output, false), nil
+	case "o5c":
+		return NewO5MEncoder(w, planet, output, true), nil
+	case "osmjson":
+		return NewOSMJSONEncoder(w, planet, output), nil
+	}
+	return nil, errors.New("Unknown output format " + format + ".")
+}
+
+// MarshalElements writes the header, nodes, ways, and relations to the encoder and then closes the encoder.
+// Returns an error if any.
+func MarshalElements(encoder ElementEncoder, nodes []*Node, ways []*Way, relations []*Relation) error {
+
+	err := encoder.WriteHeader()
+	if err != nil {
+		return errors.Wrap(err, "Error encoding header.")
+	}
+
+	for _, n := range nodes {
+		err := encoder.EncodeNode(n)
+		if err != nil {
+			return errors.Wrap(err, "Error marshalling node")
+		}
+	}
+
+	for _, w := range ways {
+		err := encoder.EncodeWay(w)
+		if err != nil {
+			return errors.Wrap(err, "Error marshalling way")
+		}
+	}
+
+	for _, r := range relations {
+		err := encoder.EncodeRelation(r)
+		if err != nil {
+			return errors.Wrap(err, "Error marshalling relation")
+		}
+	}
+
+	err = encoder.Close()
+	if err != nil {
+		return errors.Wrap(err, "Error closing encoder.")
+	}
+
+	return nil
+}
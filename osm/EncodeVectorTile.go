@@ -0,0 +1,12 @@
+package osm
+
+// EncodeVectorTile encodes the layers as a Mapbox Vector Tile.  Empty layers are skipped.
+func EncodeVectorTile(layers ...*VectorTileLayer) []byte {
+	pw := newProtoWriter(4096)
+	for _, l := range layers {
+		if l.Len() > 0 {
+			pw.WriteBytes(3, l.Bytes())
+		}
+	}
+	return pw.Bytes()
+}
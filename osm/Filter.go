@@ -2,6 +2,7 @@ package osm
 
 import (
 	"strconv"
+	"strings"
 )
 
 import (
@@ -22,6 +23,9 @@ type Filter struct {
 	UseCache       bool             `hcl:"use_cache"`
 	BoundingBox    []float64        `hcl:"bbox"`
 	MaxExtent      *Bounds          `hcl:"-"`
+	BoundaryUri    string           `hcl:"boundary"` // uri to an Osmosis polygon filter file (.poly) or GeoJSON polygon used to clip the extract
+	Boundary       *Boundary        `hcl:"-"`
+	Strategy       string           `hcl:"strategy"` // extract strategy for ways and relations crossing the bbox or boundary: simple, complete_ways, or smart.  Defaults to complete_ways.
 }
 
 func (f *Filter) Init(globals map[string]interface{}, funcs *dfl.FunctionMap) error {
@@ -43,6 +47,20 @@ func (f *Filter) Init(globals map[string]interface{}, funcs *dfl.FunctionMap) er
 		f.MaxExtent = NewBounds(f.BoundingBox[0], f.BoundingBox[1], f.BoundingBox[2], f.BoundingBox[3])
 	}
 
+	if len(f.BoundaryUri) > 0 && f.Boundary == nil {
+		b, err := LoadBoundary(f.BoundaryUri)
+		if err != nil {
+			return err
+		}
+		f.Boundary = b
+	}
+
+	if len(f.Strategy) == 0 {
+		f.Strategy = EXTRACT_COMPLETE_WAYS
+	} else if !stringSliceContains(EXTRACT_STRATEGIES, f.Strategy) {
+		return errors.New("Invalid extract strategy " + f.Strategy + ".  Expecting one of " + strings.Join(EXTRACT_STRATEGIES, ", ") + ".")
+	}
+
 	return nil
 }
 
@@ -54,6 +72,15 @@ func (fi Filter) HasMaxExtent() bool {
 	return fi.MaxExtent != nil
 }
 
+func (fi Filter) HasBoundary() bool {
+	return fi.Boundary != nil
+}
+
+// HasSpatialExtent returns true if the filter clips elements to a bounding box or boundary.
+func (fi Filter) HasSpatialExtent() bool {
+	return fi.HasMaxExtent() || fi.HasBoundary()
+}
+
 func (fi Filter) HasKeysToKeep() bool {
 	return len(fi.KeysToKeep) > 0
 }
@@ -62,20 +89,31 @@ func (fi Filter) HasKeysToDrop() bool {
 	return len(fi.KeysToDrop) > 0
 }
 
+// IsEmpty returns true if the filter keeps every element.
+func (fi Filter) IsEmpty() bool {
+	return !(fi.HasKeysToKeep() || fi.HasKeysToDrop() || fi.HasExpression() || fi.HasSpatialExtent())
+}
+
+// ContainsPoint returns true if the point is within the bounding box and boundary of the filter, if any.
 func (fi Filter) ContainsPoint(lon float64, lat float64) bool {
-	if fi.MaxExtent != nil {
-		return fi.MaxExtent.ContainsPoint(lon, lat)
+	if fi.MaxExtent != nil && !fi.MaxExtent.ContainsPoint(lon, lat) {
+		return false
+	}
+	if fi.Boundary != nil && !fi.Boundary.ContainsPoint(lon, lat) {
+		return false
 	}
 	return true
 }
 
-func NewFilter(keysToKeep []string, keysToDrop []string, exp string, useCache bool, bbox []float64) *Filter {
+func NewFilter(keysToKeep []string, keysToDrop []string, exp string, useCache bool, bbox []float64, boundaryUri string, strategy string) *Filter {
 	fi := &Filter{
 		KeysToKeep:     keysToKeep,
 		KeysToDrop:     keysToDrop,
 		ExpressionText: exp,
 		UseCache:       useCache,
 		BoundingBox:    bbox,
+		BoundaryUri:    boundaryUri,
+		Strategy:       strategy,
 	}
 	return fi
 }
@@ -0,0 +1,34 @@
+package osm
+
+// FilterTags returns the tags whose keys are kept given the slices of keys to keep and drop.
+// If keep_keys is not empty, then only tags with keys in keep_keys are returned.
+// Otherwise, tags with keys in drop_keys are removed.
+func FilterTags(tags []Tag, keep_keys []string, drop_keys []string) []Tag {
+	if len(keep_keys) == 0 && len(drop_keys) == 0 {
+		return tags
+	}
+	filtered := make([]Tag, 0, len(tags))
+	for _, tag := range tags {
+		keep := true
+		if len(keep_keys) > 0 {
+			keep = false
+			for _, k := range keep_keys {
+				if tag.Key == k {
+					keep = true
+					break
+				}
+			}
+		} else if len(drop_keys) > 0 {
+			for _, k := range drop_keys {
+				if tag.Key == k {
+					keep = false
+					break
+				}
+			}
+		}
+		if keep {
+			filtered = append(filtered, tag)
+		}
+	}
+	return filtered
+}
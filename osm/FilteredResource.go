@@ -17,7 +17,7 @@ func (fr *FilteredResource) Init(globals map[string]interface{}, ctx map[string]
 	}
 
 	if fr.Filter == nil {
-		fr.Filter = NewFilter([]string{}, []string{}, "", true, []float64{})
+		fr.Filter = NewFilter([]string{}, []string{}, "", true, []float64{}, "", "")
 	}
 
 	err = fr.Filter.Init(globals, funcs)
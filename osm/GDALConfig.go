@@ -0,0 +1,76 @@
+package osm
+
+// GDALConfig is the layers and global settings of the GDAL OSM driver, as set by an osmconf.ini file.
+//	- https://gdal.org/drivers/vector/osm.html
+//	- https://github.com/OSGeo/gdal/blob/master/ogr/ogrsf_frmts/osm/data/osmconf.ini
+type GDALConfig struct {
+	AreaRules      AreaRules             // closed ways with any of these keys are polygons, unless tagged area=no.  Set by closed_ways_are_polygons.
+	ReportAllNodes bool                  // report nodes without significant tags in the points layer
+	ReportAllWays  bool                  // report ways without significant tags in the lines and multipolygons layers
+	TagsFormat     string                // format of the other_tags and all_tags columns: hstore or json.  Defaults to hstore.
+	Launder        bool                  // replace colons in the names of attribute columns with underscores.  Set by attribute_name_laundering.
+	Layers         map[string]*GDALLayer // layers by name
+}
+
+// Layer returns the layer with the given name, or nil if the config has no such layer.
+func (c *GDALConfig) Layer(name string) *GDALLayer {
+	if l, ok := c.Layers[name]; ok {
+		return l
+	}
+	return nil
+}
+
+// IsPolygon returns true if a way with the given tags is reported as a polygon in the multipolygons layer, rather than as a line.
+// The way must be closed and either tagged area=yes or tagged with a key in closed_ways_are_polygons.
+func (c *GDALConfig) IsPolygon(w *Way, tags map[string]interface{}) bool {
+	return w.IsClosed() && c.AreaRules.IsArea(tags)
+}
+
+// NewDefaultGDALConfig returns the config of the osmconf.ini file that ships with GDAL.
+func NewDefaultGDALConfig() *GDALConfig {
+	ignore := []string{"created_by", "converted_by", "source", "time", "ele", "note", "todo", "openGeoDB:", "fixme", "FIXME"}
+	area_ignore := append([]string{"area"}, ignore...)
+	unsignificant := []string{"created_by", "converted_by", "source", "time", "ele", "attribution"}
+	layer := func(name string, attributes []string, unsignificant []string, ignore []string) *GDALLayer {
+		return &GDALLayer{
+			Name:          name,
+			OsmId:         true,
+			Attributes:    attributes,
+			Unsignificant: unsignificant,
+			Ignore:        ignore,
+			OtherTags:     true,
+		}
+	}
+	return &GDALConfig{
+		AreaRules:  gdalAreaRules([]string{"aeroway", "amenity", "boundary", "building", "craft", "geological", "historic", "landuse", "leisure", "military", "natural", "office", "place", "shop", "sport", "tourism"}),
+		TagsFormat: "hstore",
+		Launder:    true,
+		Layers: map[string]*GDALLayer{
+			"points": layer(
+				"points",
+				[]string{"name", "barrier", "highway", "ref", "address", "is_in", "place", "man_made"},
+				unsignificant,
+				ignore),
+			"lines": layer(
+				"lines",
+				[]string{"name", "highway", "waterway", "aerialway", "barrier", "man_made", "railway"},
+				append(append([]string{}, unsignificant...), "tiger:", "NHD:", "nhd:"),
+				ignore),
+			"multipolygons": layer(
+				"multipolygons",
+				[]string{"name", "type", "aeroway", "amenity", "admin_level", "barrier", "boundary", "building", "craft", "geological", "historic", "land_area", "landuse", "leisure", "man_made", "military", "natural", "office", "place", "shop", "sport", "tourism"},
+				append(append([]string{}, unsignificant...), "fixme", "FIXME"),
+				area_ignore),
+			"multilinestrings": layer(
+				"multilinestrings",
+				[]string{"name", "type"},
+				unsignificant,
+				area_ignore),
+			"other_relations": layer(
+				"other_relations",
+				[]string{"name", "type"},
+				unsignificant,
+				area_ignore),
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package osm
+
+import (
+	"strings"
+)
+
+// GDAL_LAYERS is the layers of the GDAL OSM driver, in the order GDAL reports them.
+//	- points are nodes with significant tags.
+//	- lines are ways that are not areas.
+//	- multipolygons are closed ways that are areas and multipolygon and boundary relations.
+//	- multilinestrings are multilinestring and route relations.
+//	- other_relations are all other relations.
+var GDAL_LAYERS = []string{"points", "lines", "multipolygons", "multilinestrings", "other_relations"}
+
+// GDALLayer is the columns and tag handling of a layer of the GDAL OSM driver, as set by a section of an osmconf.ini file.
+//	- https://gdal.org/drivers/vector/osm.html
+type GDALLayer struct {
+	Name          string   // name of the layer, e.g., points
+	OsmId         bool     // include the osm_id column (and osm_way_id in the multipolygons layer)
+	OsmVersion    bool     // include the osm_version column
+	OsmTimestamp  bool     // include the osm_timestamp column
+	OsmUid        bool     // include the osm_uid column
+	OsmUser       bool     // include the osm_user column
+	OsmChangeset  bool     // include the osm_changeset column
+	Attributes    []string // tag keys written as their own columns
+	Unsignificant []string // tag keys that do not make an element significant enough to be reported on their own
+	Ignore        []string // tag keys that are dropped
+	OtherTags     bool     // include the other_tags column with the tags that are not attributes
+	AllTags       bool     // include the all_tags column with every tag.  Replaces other_tags.
+}
+
+// Columns returns the names of the columns of the layer, in order.
+// If launder is true, then colons in the names of attribute columns are replaced by underscores, e.g., addr:street becomes addr_street.
+func (l *GDALLayer) Columns(launder bool) []string {
+	columns := make([]string, 0, len(l.Attributes)+8)
+	if l.OsmId {
+		columns = append(columns, "osm_id")
+		if l.Name == "multipolygons" {
+			columns = append(columns, "osm_way_id")
+		}
+	}
+	if l.OsmVersion {
+		columns = append(columns, "osm_version")
+	}
+	if l.OsmTimestamp {
+		columns = append(columns, "osm_timestamp")
+	}
+	if l.OsmUid {
+		columns = append(columns, "osm_uid")
+	}
+	if l.OsmUser {
+		columns = append(columns, "osm_user")
+	}
+	if l.OsmChangeset {
+		columns = append(columns, "osm_changeset")
+	}
+	for _, key := range l.Attributes {
+		if launder {
+			columns = append(columns, strings.Replace(key, ":", "_", -1))
+		} else {
+			columns = append(columns, key)
+		}
+	}
+	if l.AllTags {
+		columns = append(columns, "all_tags")
+	} else if l.OtherTags {
+		columns = append(columns, "other_tags")
+	}
+	return columns
+}
+
+// IsSignificant returns true if any of the tags is not unsignificant or ignored, so the element is reported in the layer.
+func (l *GDALLayer) IsSignificant(tags []Tag) bool {
+	for _, t := range tags {
+		if !gdalKeyMatches(l.Unsignificant, t.Key) && !gdalKeyMatches(l.Ignore, t.Key) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsIgnored returns true if the tag key is ignored by the layer.
+func (l *GDALLayer) IsIgnored(key string) bool {
+	return gdalKeyMatches(l.Ignore, key)
+}
@@ -0,0 +1,439 @@
+package osm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// GeoJSONDecoder decodes OSM elements from a GeoJSON (.geojson) or line-delimited GeoJSON (.geojsonl) stream.
+// A GeoJSON stream can contain feature collections, features, or bare geometries, one after the other.
+// A line-delimited GeoJSON stream contains one feature collection, feature, or geometry on each line.  Blank lines are skipped.
+// Each feature is converted into elements with ids allocated in order, starting at StartId for each element type.
+// The elements are placeholders for elements that do not exist in OSM yet, so they are written to OSM outputs with negative ids.  See Element.SignedId.
+// The Bounds of the decoder are the bounds of the allocated nodes, once the stream has been read.
+//	- Points and MultiPoints become tagged nodes.
+//	- LineStrings and MultiLineStrings become tagged ways.
+//	- Polygons with a single ring become tagged closed ways.  The tag area=yes is added if the tags do not describe an area.
+//	- Polygons with holes and MultiPolygons become multipolygon relations with untagged outer and inner ways.
+// Vertices are deduplicated into a single node at the 7 decimal place precision of OSM coordinates,
+// so features that share vertices share nodes.  The properties of a feature become the tags of its element,
+// with booleans as yes or no and arrays joined by semicolons.  Null properties are dropped.
+// The stream is read completely by the first call to Decode, so that all the nodes are returned before the ways and relations.
+type GeoJSONDecoder struct {
+	decoder   *json.Decoder      // decoder of a GeoJSON stream
+	lines     *bufio.Reader      // reader of a line-delimited GeoJSON stream
+	line      int                // number of the last line read from a line-delimited GeoJSON stream
+	StartId   uint64             // first id allocated to the nodes, ways, and relations.  Defaults to 1.
+	Bounds    *Bounds            // bounds of the nodes, or nil if there are no nodes or the stream has not been read
+	nodes     []*Node            // nodes in the order allocated
+	ways      []*Way             // ways in the order allocated
+	relations []*Relation        // relations in the order allocated
+	index     map[[2]int64]*Node // first node allocated at each position, by fixed point coordinates
+	elements  []interface{}      // elements remaining to be returned by Decode
+	read      bool               // true if the stream has been read
+}
+
+// NewGeoJSONDecoder returns a new GeoJSONDecoder reading from r.
+func NewGeoJSONDecoder(r io.Reader) *GeoJSONDecoder {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	return &GeoJSONDecoder{
+		decoder:   decoder,
+		StartId:   1,
+		nodes:     make([]*Node, 0),
+		ways:      make([]*Way, 0),
+		relations: make([]*Relation, 0),
+		index:     map[[2]int64]*Node{},
+	}
+}
+
+// NewGeoJSONLDecoder returns a new GeoJSONDecoder reading line-delimited GeoJSON from r.
+func NewGeoJSONLDecoder(r io.Reader) *GeoJSONDecoder {
+	return &GeoJSONDecoder{
+		lines:     bufio.NewReader(r),
+		StartId:   1,
+		nodes:     make([]*Node, 0),
+		ways:      make([]*Way, 0),
+		relations: make([]*Relation, 0),
+		index:     map[[2]int64]*Node{},
+	}
+}
+
+// next returns the next GeoJSON object in the stream.  Returns io.EOF when there are no more objects.
+func (d *GeoJSONDecoder) next() (*geojsonObject, error) {
+	o := &geojsonObject{}
+	if d.lines == nil {
+		err := d.decoder.Decode(o)
+		if err != nil {
+			if err == io.EOF {
+				return nil, err
+			}
+			return nil, errors.Wrap(err, "Error decoding GeoJSON")
+		}
+		return o, nil
+	}
+	for {
+		line, err := d.lines.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, errors.Wrap(err, "Error reading line-delimited GeoJSON")
+		}
+		if len(line) == 0 && err == io.EOF {
+			return nil, io.EOF
+		}
+		d.line += 1
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(line))
+		decoder.UseNumber()
+		if e := decoder.Decode(o); e != nil {
+			return nil, errors.Wrap(e, "Error decoding GeoJSON on line "+strconv.Itoa(d.line))
+		}
+		if decoder.More() {
+			return nil, errors.New("Error decoding GeoJSON on line " + strconv.Itoa(d.line) + ": the line has more than one object.")
+		}
+		return o, nil
+	}
+}
+
+// Decode returns the next element converted from the stream as a *Node, *Way, or *Relation.
+// The nodes are returned first, then the ways, and then the relations.
+// Returns io.EOF when there are no more elements.
+func (d *GeoJSONDecoder) Decode() (interface{}, error) {
+	if !d.read {
+		err := d.readAll()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(d.elements) == 0 {
+		return nil, io.EOF
+	}
+	element := d.elements[0]
+	d.elements = d.elements[1:]
+	return element, nil
+}
+
+// readAll reads every GeoJSON object in the stream and converts them into elements.
+func (d *GeoJSONDecoder) readAll() error {
+	d.read = true
+	for {
+		o, err := d.next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return err
+		}
+		err = d.addObject(o)
+		if err != nil {
+			return err
+		}
+	}
+	d.elements = make([]interface{}, 0, len(d.nodes)+len(d.ways)+len(d.relations))
+	for i, n := range d.nodes {
+		if i == 0 {
+			d.Bounds = NewBounds(n.Longitude, n.Latitude, n.Longitude, n.Latitude)
+		} else {
+			d.Bounds.ExtendPoint(n.Longitude, n.Latitude)
+		}
+		d.elements = append(d.elements, n)
+	}
+	for _, w := range d.ways {
+		d.elements = append(d.elements, w)
+	}
+	for _, r := range d.relations {
+		d.elements = append(d.elements, r)
+	}
+	d.nodes = nil
+	d.ways = nil
+	d.relations = nil
+	d.index = nil
+	return nil
+}
+
+// addObject converts a feature collection, feature, or geometry into elements.
+func (d *GeoJSONDecoder) addObject(o *geojsonObject) error {
+	switch o.Type {
+	case "FeatureCollection":
+		for _, f := range o.Features {
+			if f == nil {
+				continue
+			}
+			err := d.addObject(f)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case "Feature":
+		if o.Geometry == nil {
+			return nil
+		}
+		return d.addGeometry(o.Geometry, geojsonTags(o.Properties))
+	}
+	return d.addGeometry(o, []Tag{})
+}
+
+// addGeometry converts a geometry into elements with the given tags.
+func (d *GeoJSONDecoder) addGeometry(g *geojsonObject, tags []Tag) error {
+	switch g.Type {
+	case "Point":
+		position := make([]float64, 0)
+		err := json.Unmarshal(g.Coordinates, &position)
+		if err != nil {
+			return errors.Wrap(err, "Error parsing coordinates of GeoJSON Point")
+		}
+		return d.addPoint(position, tags)
+	case "MultiPoint":
+		positions := make([][]float64, 0)
+		err := json.Unmarshal(g.Coordinates, &positions)
+		if err != nil {
+			return errors.Wrap(err, "Error parsing coordinates of GeoJSON MultiPoint")
+		}
+		for _, position := range positions {
+			err := d.addPoint(position, tags)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case "LineString":
+		positions := make([][]float64, 0)
+		err := json.Unmarshal(g.Coordinates, &positions)
+		if err != nil {
+			return errors.Wrap(err, "Error parsing coordinates of GeoJSON LineString")
+		}
+		_, err = d.addWay(positions, false, tags)
+		return err
+	case "MultiLineString":
+		lines := make([][][]float64, 0)
+		err := json.Unmarshal(g.Coordinates, &lines)
+		if err != nil {
+			return errors.Wrap(err, "Error parsing coordinates of GeoJSON MultiLineString")
+		}
+		for _, positions := range lines {
+			_, err := d.addWay(positions, false, tags)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case "Polygon":
+		polygon := make([][][]float64, 0)
+		err := json.Unmarshal(g.Coordinates, &polygon)
+		if err != nil {
+			return errors.Wrap(err, "Error parsing coordinates of GeoJSON Polygon")
+		}
+		return d.addPolygons([][][][]float64{polygon}, tags)
+	case "MultiPolygon":
+		multipolygon := make([][][][]float64, 0)
+		err := json.Unmarshal(g.Coordinates, &multipolygon)
+		if err != nil {
+			return errors.Wrap(err, "Error parsing coordinates of GeoJSON MultiPolygon")
+		}
+		return d.addPolygons(multipolygon, tags)
+	case "GeometryCollection":
+		for _, child := range g.Geometries {
+			if child == nil {
+				continue
+			}
+			err := d.addGeometry(child, tags)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return errors.New("Unknown GeoJSON type " + g.Type + ".")
+}
+
+// addPoint adds a tagged node at the position.
+// The tags are added to the existing node at the position if it has no tags, otherwise a new node is allocated.
+func (d *GeoJSONDecoder) addPoint(position []float64, tags []Tag) error {
+	n, err := d.node(position)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	if len(n.Tags) > 0 {
+		n = d.newNode(n.Longitude, n.Latitude)
+	}
+	n.SetTags(tags)
+	return nil
+}
+
+// addPolygons adds the polygons as a single closed way if there is only one ring, otherwise as a multipolygon relation.
+// In each polygon, the first ring is an outer ring and the other rings are inner rings.
+func (d *GeoJSONDecoder) addPolygons(polygons [][][][]float64, tags []Tag) error {
+
+	rings := 0
+	for _, polygon := range polygons {
+		rings += len(polygon)
+	}
+	if rings == 0 {
+		return nil
+	}
+
+	if rings == 1 {
+		for _, polygon := range polygons {
+			if len(polygon) == 1 {
+				m := make(map[string]interface{}, len(tags))
+				for _, t := range tags {
+					m[t.Key] = t.Value
+				}
+				if _, ok := m["area"]; !ok && !DEFAULT_AREA_RULES.IsArea(m) {
+					tags = append(append(make([]Tag, 0, len(tags)+1), tags...), Tag{Key: "area", Value: "yes"})
+				}
+				_, err := d.addWay(polygon[0], true, tags)
+				return err
+			}
+		}
+	}
+
+	r := NewRelation()
+	r.Id = d.StartId + uint64(len(d.relations))
+	r.Placeholder = true
+	for _, polygon := range polygons {
+		for i, ring := range polygon {
+			w, err := d.addWay(ring, true, []Tag{})
+			if err != nil {
+				return err
+			}
+			role := "outer"
+			if i > 0 {
+				role = "inner"
+			}
+			r.Members = append(r.Members, RelationMember{Type: "way", Reference: w.Id, Role: role})
+		}
+	}
+
+	has_type := false
+	for _, t := range tags {
+		if t.Key == "type" {
+			has_type = true
+			break
+		}
+	}
+	if !has_type {
+		tags = append([]Tag{Tag{Key: "type", Value: "multipolygon"}}, tags...)
+	}
+	r.SetTags(tags)
+
+	d.relations = append(d.relations, r)
+	return nil
+}
+
+// addWay adds a way through the nodes at the positions.  Consecutive positions at the same node are collapsed.
+// If closed is true, then the way is closed if the last position is not the same as the first.
+// Returns an error if the way has too few nodes.
+func (d *GeoJSONDecoder) addWay(positions [][]float64, closed bool, tags []Tag) (*Way, error) {
+	w := NewWay()
+	w.NodeReferences = make([]NodeReference, 0, len(positions)+1)
+	for _, position := range positions {
+		n, err := d.node(position)
+		if err != nil {
+			return nil, err
+		}
+		if len(w.NodeReferences) > 0 && w.NodeReferences[len(w.NodeReferences)-1].Reference == n.Id {
+			continue
+		}
+		w.NodeReferences = append(w.NodeReferences, NodeReference{Reference: n.Id})
+	}
+	if closed {
+		if len(w.NodeReferences) > 0 && w.NodeReferences[0].Reference != w.NodeReferences[len(w.NodeReferences)-1].Reference {
+			w.NodeReferences = append(w.NodeReferences, w.NodeReferences[0])
+		}
+		if len(w.NodeReferences) < 4 {
+			return nil, errors.New("GeoJSON ring has fewer than 3 distinct positions.")
+		}
+	} else if len(w.NodeReferences) < 2 {
+		return nil, errors.New("GeoJSON LineString has fewer than 2 distinct positions.")
+	}
+	w.Id = d.StartId + uint64(len(d.ways))
+	w.Placeholder = true
+	w.SetTags(tags)
+	d.ways = append(d.ways, w)
+	return w, nil
+}
+
+// node returns the node at the position, allocating a new node if there is none.
+func (d *GeoJSONDecoder) node(position []float64) (*Node, error) {
+	if len(position) < 2 {
+		return nil, errors.New("GeoJSON position has fewer than 2 coordinates.")
+	}
+	key := [2]int64{toFixedPoint(position[0], 1e7), toFixedPoint(position[1], 1e7)}
+	if n, ok := d.index[key]; ok {
+		return n, nil
+	}
+	n := d.newNode(float64(key[0])/1e7, float64(key[1])/1e7)
+	d.index[key] = n
+	return n, nil
+}
+
+// newNode allocates a new node at the coordinates.
+func (d *GeoJSONDecoder) newNode(lon float64, lat float64) *Node {
+	n := NewNode()
+	n.Id = d.StartId + uint64(len(d.nodes))
+	n.Placeholder = true
+	n.Longitude = lon
+	n.Latitude = lat
+	d.nodes = append(d.nodes, n)
+	return n
+}
+
+// geojsonTags returns the properties of a GeoJSON feature as tags sorted by key.
+func geojsonTags(properties map[string]interface{}) []Tag {
+	tags := make([]Tag, 0, len(properties))
+	for k, v := range properties {
+		if value, ok := geojsonTagValue(v); ok {
+			tags = append(tags, Tag{Key: k, Value: value})
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key })
+	return tags
+}
+
+// geojsonTagValue returns the value of a GeoJSON property as the value of a tag, and false if the property is null or empty.
+func geojsonTagValue(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, len(v) > 0
+	case bool:
+		if v {
+			return "yes", true
+		}
+		return "no", true
+	case json.Number:
+		return v.String(), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, x := range v {
+			if value, ok := geojsonTagValue(x); ok {
+				values = append(values, value)
+			}
+		}
+		return strings.Join(values, ";"), len(values) > 0
+	case map[string]interface{}:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	}
+	return "", false
+}
@@ -0,0 +1,170 @@
+package osm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const testGeoJSONPoint = `{"type": "Feature", "properties": {"name": "A", "bench": true}, "geometry": {"type": "Point", "coordinates": [1, 2]}}`
+
+const testGeoJSONLine = `{"type": "Feature", "properties": {"highway": "footway"}, "geometry": {"type": "LineString", "coordinates": [[1, 2], [3, 4]]}}`
+
+const testGeoJSONPolygon = `{"type": "Feature", "properties": {"landuse": "grass"}, "geometry": {"type": "Polygon", "coordinates": [[[0, 0], [10, 0], [10, 10], [0, 10], [0, 0]], [[2, 2], [4, 2], [4, 4], [2, 2]]]}}`
+
+// testGeoJSON returns the test features as a GeoJSON feature collection or as line-delimited GeoJSON.
+func testGeoJSON(format string) string {
+	if format == "geojsonl" {
+		return testGeoJSONPoint + "\n\n" + testGeoJSONLine + "\n" + testGeoJSONPolygon + "\n"
+	}
+	return `{"type": "FeatureCollection", "features": [` + testGeoJSONPoint + ", " + testGeoJSONLine + ", " + testGeoJSONPolygon + "]}"
+}
+
+func TestScannerGeoJSON(t *testing.T) {
+	for _, format := range []string{"geojson", "geojsonl"} {
+		s, err := NewScanner(strings.NewReader(testGeoJSON(format)), format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		nodes := make([]*Node, 0)
+		ways := make([]*Way, 0)
+		relations := make([]*Relation, 0)
+		for s.Scan() {
+			if n := s.Node(); n != nil {
+				nodes = append(nodes, n)
+			} else if w := s.Way(); w != nil {
+				ways = append(ways, w)
+			} else if r := s.Relation(); r != nil {
+				relations = append(relations, r)
+			}
+		}
+		if err := s.Err(); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+
+		if len(nodes) != 9 || len(ways) != 3 || len(relations) != 1 {
+			t.Fatalf("%s: expected 9 nodes, 3 ways, and 1 relation, got %d nodes, %d ways, and %d relations.", format, len(nodes), len(ways), len(relations))
+		}
+		if n := nodes[0]; n.Id != 1 || n.Longitude != 1 || n.Latitude != 2 || len(n.Tags) != 2 || n.Tags[0].Key != "bench" || n.Tags[0].Value != "yes" {
+			t.Fatalf("%s: expected node 1 at 1,2 tagged bench=yes and name=A, got node %d at %v,%v tagged %v.", format, n.Id, n.Longitude, n.Latitude, n.Tags)
+		}
+		if w := ways[0]; w.Id != 1 || len(w.NodeReferences) != 2 || w.NodeReferences[0].Reference != 1 || w.NodeReferences[1].Reference != 2 {
+			t.Fatalf("%s: expected way 1 from node 1 to node 2, got way %d with %v.", format, w.Id, w.NodeReferences)
+		}
+		if r := relations[0]; len(r.Members) != 2 || r.Members[0].Reference != 2 || r.Members[0].Role != "outer" || r.Members[1].Reference != 3 || r.Members[1].Role != "inner" {
+			t.Fatalf("%s: expected relation with outer way 2 and inner way 3, got %v.", format, r.Members)
+		}
+		for _, n := range nodes {
+			if !n.Placeholder {
+				t.Fatalf("%s: expected node %d to be a placeholder.", format, n.Id)
+			}
+		}
+		for _, w := range ways {
+			if !w.Placeholder {
+				t.Fatalf("%s: expected way %d to be a placeholder.", format, w.Id)
+			}
+		}
+		if !relations[0].Placeholder {
+			t.Fatalf("%s: expected relation %d to be a placeholder.", format, relations[0].Id)
+		}
+	}
+}
+
+func TestGeoJSONLDecoderInvalidLine(t *testing.T) {
+	testCases := []struct {
+		name string
+		text string
+		line string
+	}{
+		{name: "invalid json", text: testGeoJSONPoint + "\n{\"type\": \n" + testGeoJSONLine, line: "line 2"},
+		{name: "two objects", text: "\n" + testGeoJSONPoint + "\n\n" + testGeoJSONLine + " " + testGeoJSONLine + "\n", line: "line 4"},
+	}
+	for _, tc := range testCases {
+		d := NewGeoJSONLDecoder(strings.NewReader(tc.text))
+		_, err := d.Decode()
+		if err == nil {
+			t.Fatalf("%s: expected an error.", tc.name)
+		}
+		if !strings.Contains(err.Error(), tc.line) {
+			t.Fatalf("%s: expected an error on %s, got %v.", tc.name, tc.line, err)
+		}
+	}
+}
+
+func TestGeoJSONPlaceholderIds(t *testing.T) {
+	d := NewGeoJSONDecoder(strings.NewReader(testGeoJSON("geojson")))
+	p := NewPlanet()
+	for {
+		element, err := d.Decode()
+		if err != nil {
+			break
+		}
+		switch e := element.(type) {
+		case *Node:
+			e.TagsIndex = p.AddTags(e.Tags)
+			err = p.AddNode(e)
+		case *Way:
+			e.TagsIndex = p.AddTags(e.Tags)
+			err = p.AddWay(e)
+		case *Relation:
+			e.TagsIndex = p.AddTags(e.Tags)
+			err = p.AddRelation(e)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// OSM XML is checked as text, since ids are parsed as unsigned integers.
+	buf := new(bytes.Buffer)
+	e, err := NewElementEncoder(buf, p, newTestOutput(), "osm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = MarshalElements(e, p.Nodes, p.Ways, p.Relations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`<node id="-1"`, `<way id="-1"`, `<nd ref="-1">`, `<relation id="-1"`, `ref="-3" role="inner"`} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatalf("Expected osm output to contain %s, got %s.", s, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), `id="1"`) {
+		t.Fatalf("Expected osm output to only contain negative ids, got %s.", buf.String())
+	}
+
+	// The binary formats are decoded as unsigned integers, so negative ids are checked as signed integers.
+	for _, format := range []string{"pbf", "o5m"} {
+		buf := new(bytes.Buffer)
+		e, err := NewElementEncoder(buf, p, newTestOutput(), format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = MarshalElements(e, p.Nodes, p.Ways, p.Relations)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := NewScanner(buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ways := 0
+		for s.Scan() {
+			if n := s.Node(); n != nil && int64(n.Id) >= 0 {
+				t.Fatalf("%s: expected node %d to have a negative id.", format, int64(n.Id))
+			} else if w := s.Way(); w != nil {
+				ways += 1
+				if ways == 1 && (int64(w.Id) != -1 || int64(w.NodeReferences[0].Reference) != -1 || int64(w.NodeReferences[1].Reference) != -2) {
+					t.Fatalf("%s: expected way -1 from node -1 to node -2, got way %d with %v.", format, int64(w.Id), w.NodeReferences)
+				}
+			} else if r := s.Relation(); r != nil && (int64(r.Id) != -1 || int64(r.Members[1].Reference) != -3) {
+				t.Fatalf("%s: expected relation -1 with inner way -3, got relation %d with %v.", format, int64(r.Id), r.Members)
+			}
+		}
+		if err := s.Err(); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package osm
+
+import (
+	"github.com/spatialcurrent/go-graph/graph"
+)
+
+// GetTileFeatures returns the nodes, ways, and relations in the planet that pass the output filter as features for vector tiles.
+// The features are selected by WalkFeatures, but keep their geometry as coordinates, so they can be projected, clipped, and simplified for each tile.
+// Ways and relations that cannot be converted into features are not returned as features, but are returned as incomplete ways and relations.
+// Returns the features, the incomplete ways, the incomplete relations, and an error if any.
+func (p *Planet) GetTileFeatures(output *Output) ([]*TileFeature, []*IncompleteWay, []*IncompleteRelation, error) {
+
+	area_rules := output.GetAreaRules()
+
+	features := make([]*TileFeature, 0)
+	incomplete_ways, incomplete, err := p.WalkFeatures(output, func(element_type string, element interface{}, f graph.Feature) error {
+		switch e := element.(type) {
+		case *Node:
+			features = append(features, &TileFeature{
+				Id:          e.Id,
+				ElementType: "node",
+				Properties:  p.Tags.Map(e.TagsIndex),
+				Points:      [][]float64{[]float64{e.Longitude, e.Latitude}},
+			})
+		case *Way:
+			coordinates, err := p.WayCoordinates(e)
+			if err != nil {
+				return err
+			}
+			tf := &TileFeature{Id: e.Id, ElementType: "way", Properties: p.Tags.Map(e.TagsIndex)}
+			if e.IsClosed() && area_rules.IsArea(tf.Properties) {
+				tf.Polygons = [][][][]float64{[][][]float64{coordinates}}
+			} else {
+				tf.Lines = [][][]float64{coordinates}
+			}
+			features = append(features, tf)
+		case *Relation:
+			tf := &TileFeature{Id: e.Id, ElementType: "relation", Properties: p.Tags.Map(e.TagsIndex)}
+			var err error
+			switch p.RelationType(e) {
+			case "multipolygon", "boundary":
+				tf.Polygons, err = p.AssembleMultiPolygon(e)
+			case "route", "route_master":
+				tf.Lines, _, err = p.AssembleRoute(e)
+			}
+			if err != nil {
+				return err
+			}
+			features = append(features, tf)
+		}
+		return nil
+	})
+	return features, incomplete_ways, incomplete, err
+}
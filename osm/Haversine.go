@@ -0,0 +1,18 @@
+package osm
+
+import (
+	"math"
+)
+
+// EARTH_RADIUS is the mean radius of the Earth in meters.
+const EARTH_RADIUS = 6371008.8
+
+// Haversine returns the great-circle distance in meters between two points given as longitude and latitude in degrees.
+func Haversine(lon1 float64, lat1 float64, lon2 float64, lat2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180.0
+	phi2 := lat2 * math.Pi / 180.0
+	d_phi := (lat2 - lat1) * math.Pi / 180.0
+	d_lambda := (lon2 - lon1) * math.Pi / 180.0
+	a := math.Sin(d_phi/2)*math.Sin(d_phi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(d_lambda/2)*math.Sin(d_lambda/2)
+	return 2 * EARTH_RADIUS * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,22 @@
+package osm
+
+// HIGHWAY_SPEEDS is the default speed in kilometers per hour of each value of the highway tag routable by car, used when a way has no valid maxspeed tag.
+// Ways with other values of the highway tag, e.g., footway, construction, or proposed, are not routable by car.  See ROUTING_PROFILES.
+var HIGHWAY_SPEEDS = map[string]float64{
+	"motorway":       90,
+	"motorway_link":  45,
+	"trunk":          85,
+	"trunk_link":     40,
+	"primary":        65,
+	"primary_link":   30,
+	"secondary":      55,
+	"secondary_link": 25,
+	"tertiary":       40,
+	"tertiary_link":  20,
+	"unclassified":   25,
+	"residential":    25,
+	"living_street":  10,
+	"service":        15,
+	"road":           20,
+	"track":          10,
+}
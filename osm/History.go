@@ -0,0 +1,103 @@
+package osm
+
+import (
+	"sort"
+	"time"
+)
+
+// History is a store of every version of the nodes, ways, and relations read from a full-history file.
+// Unlike a Planet, a History accepts many versions of an element with the same id.
+// The versions of each element are sorted by version number.
+type History struct {
+	Nodes     map[uint64][]*Node     // versions of nodes by id
+	Ways      map[uint64][]*Way      // versions of ways by id
+	Relations map[uint64][]*Relation // versions of relations by id
+}
+
+// NewHistory returns a new empty History.
+func NewHistory() *History {
+	return &History{
+		Nodes:     map[uint64][]*Node{},
+		Ways:      map[uint64][]*Way{},
+		Relations: map[uint64][]*Relation{},
+	}
+}
+
+// AddNode adds a version of a node to the history.
+func (h *History) AddNode(n *Node) {
+	versions := append(h.Nodes[n.Id], n)
+	for i := len(versions) - 1; i > 0 && versions[i-1].Version > versions[i].Version; i-- {
+		versions[i-1], versions[i] = versions[i], versions[i-1]
+	}
+	h.Nodes[n.Id] = versions
+}
+
+// AddWay adds a version of a way to the history.
+func (h *History) AddWay(w *Way) {
+	versions := append(h.Ways[w.Id], w)
+	for i := len(versions) - 1; i > 0 && versions[i-1].Version > versions[i].Version; i-- {
+		versions[i-1], versions[i] = versions[i], versions[i-1]
+	}
+	h.Ways[w.Id] = versions
+}
+
+// AddRelation adds a version of a relation to the history.
+func (h *History) AddRelation(r *Relation) {
+	versions := append(h.Relations[r.Id], r)
+	for i := len(versions) - 1; i > 0 && versions[i-1].Version > versions[i].Version; i-- {
+		versions[i-1], versions[i] = versions[i], versions[i-1]
+	}
+	h.Relations[r.Id] = versions
+}
+
+// Snapshot returns the nodes, ways, and relations as they existed at the given time, sorted by id.
+// For each element, the snapshot uses the latest version with a timestamp at or before the given time.
+// Elements whose version at that time is not visible, i.e., deleted, are not returned.
+// Versions without a timestamp are always included.  If at is nil, then uses the latest version of every element.
+func (h *History) Snapshot(at *time.Time) ([]*Node, []*Way, []*Relation) {
+
+	current := func(ts *time.Time) bool {
+		return at == nil || ts == nil || !ts.After(*at)
+	}
+
+	nodes := make([]*Node, 0, len(h.Nodes))
+	for _, versions := range h.Nodes {
+		for i := len(versions) - 1; i >= 0; i-- {
+			if current(versions[i].Timestamp) {
+				if versions[i].IsVisible() {
+					nodes = append(nodes, versions[i])
+				}
+				break
+			}
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Id < nodes[j].Id })
+
+	ways := make([]*Way, 0, len(h.Ways))
+	for _, versions := range h.Ways {
+		for i := len(versions) - 1; i >= 0; i-- {
+			if current(versions[i].Timestamp) {
+				if versions[i].IsVisible() {
+					ways = append(ways, versions[i])
+				}
+				break
+			}
+		}
+	}
+	sort.Slice(ways, func(i, j int) bool { return ways[i].Id < ways[j].Id })
+
+	relations := make([]*Relation, 0, len(h.Relations))
+	for _, versions := range h.Relations {
+		for i := len(versions) - 1; i >= 0; i-- {
+			if current(versions[i].Timestamp) {
+				if versions[i].IsVisible() {
+					relations = append(relations, versions[i])
+				}
+				break
+			}
+		}
+	}
+	sort.Slice(relations, func(i, j int) bool { return relations[i].Id < relations[j].Id })
+
+	return nodes, ways, relations
+}
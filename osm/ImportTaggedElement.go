@@ -0,0 +1,17 @@
+package osm
+
+// ImportTaggedElement prepares an element decoded from an input for storage in the planet.
+// Caches the element's user name, drops the attributes and tags excluded by the input,
+// and replaces the element's tags with indexes into the planet's TagsCache.
+func (p *Planet) ImportTaggedElement(input *Input, te *TaggedElement) {
+
+	if te.UserId > 0 && len(te.UserName) > 0 && !input.DropUserId && !input.DropUserName {
+		p.UserNames[te.UserId] = te.UserName
+	}
+	te.DropUserName()
+
+	te.DropAttributes(input.PlanetResource)
+
+	te.SetTagsIndex(p.AddTags(FilterTags(te.Tags, input.KeysToKeep, input.KeysToDrop)))
+	te.SetTags(nil)
+}
@@ -0,0 +1,40 @@
+package osm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IncompleteRelation describes a relation that could not be converted into a feature,
+// because members are missing from the planet or the members do not form a valid geometry.
+type IncompleteRelation struct {
+	Id               uint64   // the id of the relation
+	MissingNodes     []uint64 // ids of nodes referenced by the relation's member ways that are missing from the planet
+	MissingWays      []uint64 // ids of member ways that are missing from the planet
+	MissingRelations []uint64 // ids of member relations that are missing from the planet
+	Reason           string   // description of why the relation is incomplete
+}
+
+// Error returns a description of the incomplete relation, so it can be returned and logged as an error.
+func (ir *IncompleteRelation) Error() string {
+	parts := []string{"Relation " + fmt.Sprint(ir.Id) + " is incomplete: " + ir.Reason}
+	if len(ir.MissingRelations) > 0 {
+		parts = append(parts, "missing relations "+joinUInt64s(ir.MissingRelations, ","))
+	}
+	if len(ir.MissingWays) > 0 {
+		parts = append(parts, "missing ways "+joinUInt64s(ir.MissingWays, ","))
+	}
+	if len(ir.MissingNodes) > 0 {
+		parts = append(parts, "missing nodes "+joinUInt64s(ir.MissingNodes, ","))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// joinUInt64s joins the values into a string with the given separator.
+func joinUInt64s(values []uint64, sep string) string {
+	s := make([]string, len(values))
+	for i, x := range values {
+		s[i] = fmt.Sprint(x)
+	}
+	return strings.Join(s, sep)
+}
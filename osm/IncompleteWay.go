@@ -0,0 +1,22 @@
+package osm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IncompleteWay describes a way that could not be converted into a feature, because its nodes are missing from the planet.
+type IncompleteWay struct {
+	Id           uint64   // the id of the way
+	MissingNodes []uint64 // ids of nodes referenced by the way that are missing from the planet
+	Reason       string   // description of why the way is incomplete
+}
+
+// Error returns a description of the incomplete way, so it can be returned and logged as an error.
+func (iw *IncompleteWay) Error() string {
+	parts := []string{"Way " + fmt.Sprint(iw.Id) + " is incomplete: " + iw.Reason}
+	if len(iw.MissingNodes) > 0 {
+		parts = append(parts, "missing nodes "+joinUInt64s(iw.MissingNodes, ","))
+	}
+	return strings.Join(parts, "; ")
+}
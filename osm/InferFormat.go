@@ -0,0 +1,38 @@
+package osm
+
+import (
+	"strings"
+)
+
+// InferFormat returns the format of an OSM resource given its uri.
+// Returns "pbf" for .osm.pbf and .osh.pbf files, "o5m" for .o5m files, "o5c" for .o5c files, "osc" for .osc files, "osmjson" for .osm.json files,
+// "csv" for .csv files, "tsv" for .tsv files, "graphml" for .graphml files, "geojson" for .geojson files, "geojsonl" for .geojsonl files,
+// "parquet" for .parquet files, "sql" for .sql files, and "osm" otherwise.
+func InferFormat(uri string) string {
+	if strings.HasSuffix(uri, ".osm.pbf") || strings.HasSuffix(uri, ".osh.pbf") {
+		return "pbf"
+	} else if strings.HasSuffix(uri, ".o5m") {
+		return "o5m"
+	} else if strings.HasSuffix(uri, ".o5c") {
+		return "o5c"
+	} else if strings.HasSuffix(uri, ".osc") || strings.HasSuffix(uri, ".osc.gz") || strings.HasSuffix(uri, ".osc.bz2") {
+		return "osc"
+	} else if strings.HasSuffix(uri, ".osm.json") || strings.HasSuffix(uri, ".osm.json.gz") {
+		return "osmjson"
+	} else if strings.HasSuffix(uri, ".csv") || strings.HasSuffix(uri, ".csv.gz") {
+		return "csv"
+	} else if strings.HasSuffix(uri, ".tsv") || strings.HasSuffix(uri, ".tsv.gz") {
+		return "tsv"
+	} else if strings.HasSuffix(uri, ".graphml") || strings.HasSuffix(uri, ".graphml.gz") {
+		return "graphml"
+	} else if strings.HasSuffix(uri, ".geojson") || strings.HasSuffix(uri, ".geojson.gz") {
+		return "geojson"
+	} else if strings.HasSuffix(uri, ".geojsonl") || strings.HasSuffix(uri, ".geojsonl.gz") {
+		return "geojsonl"
+	} else if strings.HasSuffix(uri, ".parquet") {
+		return "parquet"
+	} else if strings.HasSuffix(uri, ".sql") || strings.HasSuffix(uri, ".sql.gz") {
+		return "sql"
+	}
+	return "osm"
+}
@@ -0,0 +1,10 @@
+package osm
+
+import (
+	"strings"
+)
+
+// InferHistory returns true if the uri is a full-history file, i.e., .osh, .osh.gz, .osh.bz2, or .osh.pbf.
+func InferHistory(uri string) bool {
+	return strings.HasSuffix(uri, ".osh") || strings.HasSuffix(uri, ".osh.gz") || strings.HasSuffix(uri, ".osh.bz2") || strings.HasSuffix(uri, ".osh.pbf")
+}
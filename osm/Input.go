@@ -3,6 +3,7 @@ package osm
 
 import (
 	"strings"
+	"time"
 )
 
 import (
@@ -20,6 +21,11 @@ import (
 // Input is a struct for holding all the configuration describing an input destination
 type Input struct {
 	*PlanetResource `hcl:"resource"`
+	Format          string                `hcl:"format"`   // format of the input: osm, osc, pbf, o5m, o5c, osmjson, geojson, or geojsonl.  Inferred from the uri if not set.
+	History         bool                  `hcl:"history"`  // input is a full-history file with every version of each element.  Inferred from an .osh uri.
+	SnapshotAt      *time.Time            `hcl:"-"`        // if set, then the planet is read as it existed at this time.  Requires a full-history input.
+	MergePolicy     string                `hcl:"-"`        // policy for resolving elements already in the planet: error, first, newest-version, or newest-timestamp.  Set by the config.
+	StartId         uint64                `hcl:"start_id"` // first id allocated to the elements converted from a GeoJSON input.  Defaults to after the largest id of the previous inputs.
 	Reader          reader.ByteReadCloser `hcl:"-"`
 }
 
@@ -30,6 +36,14 @@ func (i *Input) Init(globals map[string]interface{}, ctx map[string]interface{},
 		return err
 	}
 
+	if len(i.Format) == 0 {
+		i.Format = InferFormat(i.Uri)
+	}
+
+	if !i.History {
+		i.History = InferHistory(i.Uri)
+	}
+
 	return nil
 }
 
@@ -62,7 +76,7 @@ func (i *Input) Open(read_buffer_size int, s3_client *s3.S3, hdfs_clients map[st
 
 func (i *Input) OpenFile(read_buffer_size int) error {
 
-	if strings.HasSuffix(i.PathExpanded, ".osm.gz") {
+	if strings.HasSuffix(i.PathExpanded, ".osm.gz") || strings.HasSuffix(i.PathExpanded, ".osc.gz") || strings.HasSuffix(i.PathExpanded, ".osh.gz") || strings.HasSuffix(i.PathExpanded, ".osm.json.gz") || strings.HasSuffix(i.PathExpanded, ".geojson.gz") || strings.HasSuffix(i.PathExpanded, ".geojsonl.gz") {
 
 		r, err := reader.OpenFile(i.Path, "gzip", false, read_buffer_size)
 		if err != nil {
@@ -70,7 +84,7 @@ func (i *Input) OpenFile(read_buffer_size int) error {
 		}
 		i.Reader = r
 
-	} else if strings.HasSuffix(i.PathExpanded, ".osm.bz2") {
+	} else if strings.HasSuffix(i.PathExpanded, ".osm.bz2") || strings.HasSuffix(i.PathExpanded, ".osc.bz2") || strings.HasSuffix(i.PathExpanded, ".osh.bz2") {
 
 		r, err := reader.OpenFile(i.Path, "bzip2", false, read_buffer_size)
 		if err != nil {
@@ -78,7 +92,23 @@ func (i *Input) OpenFile(read_buffer_size int) error {
 		}
 		i.Reader = r
 
-	} else if strings.HasSuffix(i.PathExpanded, ".osm") {
+	} else if strings.HasSuffix(i.PathExpanded, ".osm") || strings.HasSuffix(i.PathExpanded, ".osc") || strings.HasSuffix(i.PathExpanded, ".osh") || strings.HasSuffix(i.PathExpanded, ".osm.json") || strings.HasSuffix(i.PathExpanded, ".geojson") || strings.HasSuffix(i.PathExpanded, ".geojsonl") {
+
+		r, err := reader.OpenFile(i.Path, "none", false, read_buffer_size)
+		if err != nil {
+			return errors.Wrap(err, "error opening file at path "+i.Path)
+		}
+		i.Reader = r
+
+	} else if strings.HasSuffix(i.PathExpanded, ".osm.pbf") || strings.HasSuffix(i.PathExpanded, ".osh.pbf") {
+
+		r, err := reader.OpenFile(i.Path, "none", false, read_buffer_size)
+		if err != nil {
+			return errors.Wrap(err, "error opening file at path "+i.Path)
+		}
+		i.Reader = r
+
+	} else if strings.HasSuffix(i.PathExpanded, ".o5m") || strings.HasSuffix(i.PathExpanded, ".o5c") {
 
 		r, err := reader.OpenFile(i.Path, "none", false, read_buffer_size)
 		if err != nil {
@@ -86,10 +116,6 @@ func (i *Input) OpenFile(read_buffer_size int) error {
 		}
 		i.Reader = r
 
-	} else if strings.HasSuffix(i.PathExpanded, ".osm.pbf") {
-		return errors.New("The OSM PBF format is not supported yet.")
-	} else if strings.HasSuffix(i.PathExpanded, ".o5m") {
-		return errors.New("The o5m format is not supported yet.")
 	} else {
 		return errors.New("Unknown file extension for input at " + i.Uri + ".")
 	}
@@ -99,7 +125,7 @@ func (i *Input) OpenFile(read_buffer_size int) error {
 
 func (i *Input) OpenWeb() error {
 
-	if strings.HasSuffix(i.Uri, ".osm.gz") {
+	if strings.HasSuffix(i.Uri, ".osm.gz") || strings.HasSuffix(i.Uri, ".osc.gz") || strings.HasSuffix(i.Uri, ".osh.gz") || strings.HasSuffix(i.Uri, ".osm.json.gz") || strings.HasSuffix(i.Uri, ".geojson.gz") || strings.HasSuffix(i.Uri, ".geojsonl.gz") {
 
 		r, _, err := reader.OpenHTTPFile(i.Uri, "gzip", false)
 		if err != nil {
@@ -107,7 +133,7 @@ func (i *Input) OpenWeb() error {
 		}
 		i.Reader = r
 
-	} else if strings.HasSuffix(i.Uri, ".osm.bz2") {
+	} else if strings.HasSuffix(i.Uri, ".osm.bz2") || strings.HasSuffix(i.Uri, ".osc.bz2") || strings.HasSuffix(i.Uri, ".osh.bz2") {
 
 		r, _, err := reader.OpenHTTPFile(i.Uri, "bzip2", false)
 		if err != nil {
@@ -115,7 +141,23 @@ func (i *Input) OpenWeb() error {
 		}
 		i.Reader = r
 
-	} else if strings.HasSuffix(i.Uri, ".osm") {
+	} else if strings.HasSuffix(i.Uri, ".osm") || strings.HasSuffix(i.Uri, ".osc") || strings.HasSuffix(i.Uri, ".osh") || strings.HasSuffix(i.Uri, ".osm.json") || strings.HasSuffix(i.Uri, ".geojson") || strings.HasSuffix(i.Uri, ".geojsonl") {
+
+		r, _, err := reader.OpenHTTPFile(i.Uri, "none", false)
+		if err != nil {
+			return errors.Wrap(err, "error opening file at uri "+i.Uri)
+		}
+		i.Reader = r
+
+	} else if strings.HasSuffix(i.Uri, ".osm.pbf") || strings.HasSuffix(i.Uri, ".osh.pbf") {
+
+		r, _, err := reader.OpenHTTPFile(i.Uri, "none", false)
+		if err != nil {
+			return errors.Wrap(err, "error opening file at uri "+i.Uri)
+		}
+		i.Reader = r
+
+	} else if strings.HasSuffix(i.Uri, ".o5m") || strings.HasSuffix(i.Uri, ".o5c") {
 
 		r, _, err := reader.OpenHTTPFile(i.Uri, "none", false)
 		if err != nil {
@@ -123,10 +165,6 @@ func (i *Input) OpenWeb() error {
 		}
 		i.Reader = r
 
-	} else if strings.HasSuffix(i.Uri, ".osm.pbf") {
-		return errors.New("The OSM PBF format is not supported yet.")
-	} else if strings.HasSuffix(i.Uri, ".o5m") {
-		return errors.New("The o5m format is not supported yet.")
 	} else {
 		return errors.New("Unknown file extension for input at " + i.Uri + ".")
 	}
@@ -136,7 +174,7 @@ func (i *Input) OpenWeb() error {
 
 func (i *Input) OpenFileOnHDFS(hdfs_client *hdfs.Client, read_buffer_size int) error {
 
-	if strings.HasSuffix(i.PathExpanded, ".osm.gz") {
+	if strings.HasSuffix(i.PathExpanded, ".osm.gz") || strings.HasSuffix(i.PathExpanded, ".osc.gz") || strings.HasSuffix(i.PathExpanded, ".osh.gz") || strings.HasSuffix(i.PathExpanded, ".osm.json.gz") || strings.HasSuffix(i.PathExpanded, ".geojson.gz") || strings.HasSuffix(i.PathExpanded, ".geojsonl.gz") {
 
 		r, err := reader.OpenHDFSFile(i.Path, "gzip", false, hdfs_client)
 		if err != nil {
@@ -144,7 +182,7 @@ func (i *Input) OpenFileOnHDFS(hdfs_client *hdfs.Client, read_buffer_size int) e
 		}
 		i.Reader = r
 
-	} else if strings.HasSuffix(i.PathExpanded, ".osm.bz2") {
+	} else if strings.HasSuffix(i.PathExpanded, ".osm.bz2") || strings.HasSuffix(i.PathExpanded, ".osc.bz2") || strings.HasSuffix(i.PathExpanded, ".osh.bz2") {
 
 		r, err := reader.OpenHDFSFile(i.Path, "bzip2", false, hdfs_client)
 		if err != nil {
@@ -152,7 +190,7 @@ func (i *Input) OpenFileOnHDFS(hdfs_client *hdfs.Client, read_buffer_size int) e
 		}
 		i.Reader = r
 
-	} else if strings.HasSuffix(i.PathExpanded, ".osm") {
+	} else if strings.HasSuffix(i.PathExpanded, ".osm") || strings.HasSuffix(i.PathExpanded, ".osc") || strings.HasSuffix(i.PathExpanded, ".osh") || strings.HasSuffix(i.PathExpanded, ".osm.json") || strings.HasSuffix(i.PathExpanded, ".geojson") || strings.HasSuffix(i.PathExpanded, ".geojsonl") {
 
 		r, err := reader.OpenHDFSFile(i.Path, "none", false, hdfs_client)
 		if err != nil {
@@ -160,10 +198,22 @@ func (i *Input) OpenFileOnHDFS(hdfs_client *hdfs.Client, read_buffer_size int) e
 		}
 		i.Reader = r
 
-	} else if strings.HasSuffix(i.PathExpanded, ".osm.pbf") {
-		return errors.New("The OSM PBF format is not supported yet.")
-	} else if strings.HasSuffix(i.PathExpanded, ".o5m") {
-		return errors.New("The o5m format is not supported yet.")
+	} else if strings.HasSuffix(i.PathExpanded, ".osm.pbf") || strings.HasSuffix(i.PathExpanded, ".osh.pbf") {
+
+		r, err := reader.OpenHDFSFile(i.Path, "none", false, hdfs_client)
+		if err != nil {
+			return errors.Wrap(err, "error opening file on HDFS at path "+i.Path)
+		}
+		i.Reader = r
+
+	} else if strings.HasSuffix(i.PathExpanded, ".o5m") || strings.HasSuffix(i.PathExpanded, ".o5c") {
+
+		r, err := reader.OpenHDFSFile(i.Path, "none", false, hdfs_client)
+		if err != nil {
+			return errors.Wrap(err, "error opening file on HDFS at path "+i.Path)
+		}
+		i.Reader = r
+
 	} else {
 		return errors.New("Unknown file extension for input at " + i.Uri + ".")
 	}
@@ -173,7 +223,7 @@ func (i *Input) OpenFileOnHDFS(hdfs_client *hdfs.Client, read_buffer_size int) e
 
 func (i *Input) OpenS3Object(s3_client *s3.S3) error {
 
-	if strings.HasSuffix(i.Key, ".osm.gz") {
+	if strings.HasSuffix(i.Key, ".osm.gz") || strings.HasSuffix(i.Key, ".osc.gz") || strings.HasSuffix(i.Key, ".osh.gz") || strings.HasSuffix(i.Key, ".osm.json.gz") || strings.HasSuffix(i.Key, ".geojson.gz") || strings.HasSuffix(i.Key, ".geojsonl.gz") {
 
 		r, _, err := reader.OpenS3Object(i.Bucket, i.Key, "gzip", false, s3_client)
 		if err != nil {
@@ -181,7 +231,7 @@ func (i *Input) OpenS3Object(s3_client *s3.S3) error {
 		}
 		i.Reader = r
 
-	} else if strings.HasSuffix(i.Key, ".osm.bz2") {
+	} else if strings.HasSuffix(i.Key, ".osm.bz2") || strings.HasSuffix(i.Key, ".osc.bz2") || strings.HasSuffix(i.Key, ".osh.bz2") {
 
 		r, _, err := reader.OpenS3Object(i.Bucket, i.Key, "bzip2", false, s3_client)
 		if err != nil {
@@ -189,7 +239,23 @@ func (i *Input) OpenS3Object(s3_client *s3.S3) error {
 		}
 		i.Reader = r
 
-	} else if strings.HasSuffix(i.Key, ".osm") {
+	} else if strings.HasSuffix(i.Key, ".osm") || strings.HasSuffix(i.Key, ".osc") || strings.HasSuffix(i.Key, ".osh") || strings.HasSuffix(i.Key, ".osm.json") || strings.HasSuffix(i.Key, ".geojson") || strings.HasSuffix(i.Key, ".geojsonl") {
+
+		r, _, err := reader.OpenS3Object(i.Bucket, i.Key, "none", false, s3_client)
+		if err != nil {
+			return errors.Wrap(err, "error opening s3 object at s3://"+i.Bucket+"/"+i.Key)
+		}
+		i.Reader = r
+
+	} else if strings.HasSuffix(i.Key, ".osm.pbf") || strings.HasSuffix(i.Key, ".osh.pbf") {
+
+		r, _, err := reader.OpenS3Object(i.Bucket, i.Key, "none", false, s3_client)
+		if err != nil {
+			return errors.Wrap(err, "error opening s3 object at s3://"+i.Bucket+"/"+i.Key)
+		}
+		i.Reader = r
+
+	} else if strings.HasSuffix(i.Key, ".o5m") || strings.HasSuffix(i.Key, ".o5c") {
 
 		r, _, err := reader.OpenS3Object(i.Bucket, i.Key, "none", false, s3_client)
 		if err != nil {
@@ -197,10 +263,6 @@ func (i *Input) OpenS3Object(s3_client *s3.S3) error {
 		}
 		i.Reader = r
 
-	} else if strings.HasSuffix(i.Key, ".osm.pbf") {
-		return errors.New("The OSM PBF format is not supported yet.")
-	} else if strings.HasSuffix(i.Key, ".o5m") {
-		return errors.New("The o5m format is not supported yet.")
 	} else {
 		return errors.New("Unknown file extension for input at " + i.Uri + ".")
 	}
@@ -2,6 +2,10 @@ package osm
 
 type InputConfig struct {
 	Uri           string   `hcl:"uri"`            // resource URI
+	Format        string   `hcl:"format"`         // format of the input: osm, osc, pbf, o5m, o5c, osmjson, geojson, or geojsonl.  Inferred from the uri if not set.
+	History       bool     `hcl:"history"`        // input is a full-history file.  Inferred from an .osh uri.
+	SnapshotAt    string   `hcl:"snapshot_at"`    // RFC 3339 timestamp.  If set, then the planet is read as it existed at this time.
+	StartId       uint64   `hcl:"start_id"`       // first id allocated to the elements converted from a GeoJSON input.  Defaults to after the largest id of the previous inputs.
 	DropNodes     bool     `hcl:"drop_nodes"`     //drop nodes
 	DropWays      bool     `hcl:"drop_ways"`      // drop ways
 	DropRelations bool     `hcl:"drop_relations"` // drop relations
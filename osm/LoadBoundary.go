@@ -0,0 +1,48 @@
+package osm
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+)
+
+import (
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+)
+
+// LoadBoundary loads a boundary from an Osmosis polygon filter file (.poly) or a GeoJSON file (.geojson or .json) on the local file system.
+// Returns the boundary and an error if any.
+func LoadBoundary(uri string) (*Boundary, error) {
+
+	scheme, path := SplitUri(uri, []string{"file"})
+	if scheme != "file" {
+		return nil, errors.New("Unsupported scheme for boundary uri " + uri)
+	}
+
+	path_expanded, err := homedir.Expand(path)
+	if err != nil {
+		return nil, errors.New("Error: Could not expand home directory for path " + path + ".")
+	}
+
+	d, err := ioutil.ReadFile(path_expanded)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading boundary at "+uri)
+	}
+
+	if strings.HasSuffix(path_expanded, ".poly") {
+		b, err := ParsePoly(bytes.NewReader(d))
+		if err != nil {
+			return nil, errors.Wrap(err, "Error parsing polygon file at "+uri)
+		}
+		return b, nil
+	} else if strings.HasSuffix(path_expanded, ".geojson") || strings.HasSuffix(path_expanded, ".json") {
+		b, err := ParseGeoJSONBoundary(d)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error parsing GeoJSON file at "+uri)
+		}
+		return b, nil
+	}
+
+	return nil, errors.New("Unknown file extension for boundary at " + uri + ".  Expecting .poly, .geojson, or .json.")
+}
@@ -0,0 +1,79 @@
+package osm
+
+import (
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+	"gopkg.in/ini.v1"
+)
+
+// LoadGDALConfig loads the layers and global settings of the GDAL OSM driver from an osmconf.ini file.
+// The global settings closed_ways_are_polygons, report_all_nodes, report_all_ways, tags_format, and attribute_name_laundering are read from the top of the file.
+// Each of the sections points, lines, multipolygons, multilinestrings, and other_relations sets the columns and tag handling of its layer.
+//	- osm_id, osm_version, osm_timestamp, osm_uid, osm_user, and osm_changeset include the attributes of the elements as columns.
+//	- attributes is the tag keys written as their own columns.
+//	- unsignificant is the tag keys that do not make an element significant enough to be reported on their own.
+//	- ignore is the tag keys that are dropped.
+//	- other_tags and all_tags include the other tags or all the tags in a single column.
+// Keys that are missing from the file default to the values of GDAL, which reports osm_id and other_tags and nothing else.
+// Computed attributes, such as z_order, are not supported and are ignored.
+// Returns the config and an error if any.
+func LoadGDALConfig(uri string) (*GDALConfig, error) {
+
+	scheme, path := SplitUri(uri, []string{"file"})
+	if scheme != "file" {
+		return nil, errors.New("Unsupported scheme for GDAL ini uri " + uri)
+	}
+
+	cfg, err := ini.Load(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading GDAL ini file at "+uri)
+	}
+
+	global := cfg.Section("")
+	c := &GDALConfig{
+		AreaRules:      gdalAreaRules(ParseSliceString(global.Key("closed_ways_are_polygons").String())),
+		ReportAllNodes: ParseBool(global.Key("report_all_nodes").String()),
+		ReportAllWays:  ParseBool(global.Key("report_all_ways").String()),
+		TagsFormat:     "hstore",
+		Launder:        !global.HasKey("attribute_name_laundering") || ParseBool(global.Key("attribute_name_laundering").String()),
+		Layers:         map[string]*GDALLayer{},
+	}
+
+	if global.HasKey("tags_format") {
+		switch tags_format := strings.ToLower(global.Key("tags_format").String()); tags_format {
+		case "hstore", "json":
+			c.TagsFormat = tags_format
+		default:
+			return nil, errors.New("Unknown tags_format " + tags_format + " in GDAL ini file at " + uri + ".  Expecting hstore or json.")
+		}
+	}
+
+	for _, name := range GDAL_LAYERS {
+		section := cfg.Section(name)
+		flag := func(key string, value bool) bool {
+			if section.HasKey(key) {
+				return ParseBool(section.Key(key).String())
+			}
+			return value
+		}
+		c.Layers[name] = &GDALLayer{
+			Name:          name,
+			OsmId:         flag("osm_id", true),
+			OsmVersion:    flag("osm_version", false),
+			OsmTimestamp:  flag("osm_timestamp", false),
+			OsmUid:        flag("osm_uid", false),
+			OsmUser:       flag("osm_user", false),
+			OsmChangeset:  flag("osm_changeset", false),
+			Attributes:    ParseSliceString(section.Key("attributes").String()),
+			Unsignificant: ParseSliceString(section.Key("unsignificant").String()),
+			Ignore:        ParseSliceString(section.Key("ignore").String()),
+			OtherTags:     flag("other_tags", true),
+			AllTags:       flag("all_tags", false),
+		}
+	}
+
+	return c, nil
+}
@@ -0,0 +1,107 @@
+// +build !js
+
+package osm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// MBTilesWriter writes tiles to an MBTiles file, a SQLite database with a tiles table and a metadata table.
+// Tiles are gzip compressed and the rows are flipped to the TMS scheme, as required by the MBTiles 1.3 specification.
+// The tiles are written in a single transaction, which is committed by Close.
+type MBTilesWriter struct {
+	db     *sql.DB
+	tx     *sql.Tx
+	insert *sql.Stmt
+}
+
+// NewMBTilesWriter creates the tables of an MBTiles file at path, writes the metadata, and returns a writer for the tiles.
+// Replaces the tiles and metadata if the file already exists.
+// The sqlite3 database driver must be registered, e.g., by importing github.com/mattn/go-sqlite3.
+func NewMBTilesWriter(path string, metadata map[string]string) (*MBTilesWriter, error) {
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error opening MBTiles file "+path)
+	}
+
+	statements := []string{
+		"CREATE TABLE IF NOT EXISTS metadata (name text, value text);",
+		"CREATE UNIQUE INDEX IF NOT EXISTS name ON metadata (name);",
+		"CREATE TABLE IF NOT EXISTS tiles (zoom_level integer, tile_column integer, tile_row integer, tile_data blob);",
+		"CREATE UNIQUE INDEX IF NOT EXISTS tile_index ON tiles (zoom_level, tile_column, tile_row);",
+		"DELETE FROM metadata;",
+		"DELETE FROM tiles;",
+	}
+	for _, s := range statements {
+		_, err := db.Exec(s)
+		if err != nil {
+			db.Close()
+			return nil, errors.Wrap(err, "Error creating tables in MBTiles file "+path)
+		}
+	}
+
+	for name, value := range metadata {
+		_, err := db.Exec("INSERT INTO metadata (name, value) VALUES (?, ?);", name, value)
+		if err != nil {
+			db.Close()
+			return nil, errors.Wrap(err, "Error writing metadata to MBTiles file "+path)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "Error starting transaction for MBTiles file "+path)
+	}
+
+	insert, err := tx.Prepare("INSERT INTO tiles (zoom_level, tile_column, tile_row, tile_data) VALUES (?, ?, ?, ?);")
+	if err != nil {
+		tx.Rollback()
+		db.Close()
+		return nil, errors.Wrap(err, "Error preparing statement for MBTiles file "+path)
+	}
+
+	return &MBTilesWriter{db: db, tx: tx, insert: insert}, nil
+}
+
+// WriteTile compresses the tile and inserts it into the tiles table.
+func (w *MBTilesWriter) WriteTile(z int, x int, y int, data []byte) error {
+	buf := new(bytes.Buffer)
+	gw := gzip.NewWriter(buf)
+	_, err := gw.Write(data)
+	if err != nil {
+		return errors.Wrap(err, "Error compressing tile")
+	}
+	err = gw.Close()
+	if err != nil {
+		return errors.Wrap(err, "Error compressing tile")
+	}
+	_, err = w.insert.Exec(z, x, (1<<uint(z))-1-y, buf.Bytes())
+	if err != nil {
+		return errors.Wrap(err, "Error inserting tile into MBTiles file")
+	}
+	return nil
+}
+
+// Close commits the tiles and closes the MBTiles file.
+func (w *MBTilesWriter) Close() error {
+	err := w.insert.Close()
+	if err != nil {
+		w.tx.Rollback()
+		w.db.Close()
+		return errors.Wrap(err, "Error closing statement for MBTiles file")
+	}
+	err = w.tx.Commit()
+	if err != nil {
+		w.db.Close()
+		return errors.Wrap(err, "Error committing tiles to MBTiles file")
+	}
+	return w.db.Close()
+}
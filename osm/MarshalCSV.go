@@ -0,0 +1,138 @@
+package osm
+
+import (
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/spatialcurrent/go-graph/graph"
+)
+
+// MarshalCSV writes the features of the planet selected for the output as a csv or tsv table, with a header and one row per feature.
+// The features are selected the same as GetFeatures, so the output's filter, drop flags, relation types, and area rules apply.
+// The columns of the table are listed by the output.  See Output.GetColumns.
+//	- id is the id of the element.
+//	- type is the type of the element: node, way, or relation.
+//	- lon and lat are the coordinates of a node, or the centroid of the geometry of a way or relation.
+//	- wkt is the geometry as Well-Known Text.
+//	- other_tags is the tags that are not in other columns, as an hstore literal.  See MarshalHStore.
+//	- any other column is the value of the tag with that key.  The prefix "tag:" selects a tag with the same key as a column above, e.g., tag:type.
+// If the path of the output ends in .gz, then the table is gzip compressed.
+// Ways and relations that cannot be converted into features are skipped and returned as incomplete ways and relations.
+// Returns the incomplete ways, the incomplete relations, and an error if any.
+func MarshalCSV(output *Output, planet *Planet) ([]*IncompleteWay, []*IncompleteRelation, error) {
+
+	writer, format, err := OpenOutputWriter(output)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cw := csv.NewWriter(writer)
+	if format == "tsv" {
+		cw.Comma = '\t'
+	}
+
+	columns := output.GetColumns()
+	tag_columns := map[string]struct{}{}
+	for _, column := range columns {
+		switch column {
+		case "id", "type", "lon", "lat", "wkt", "other_tags":
+		default:
+			tag_columns[strings.TrimPrefix(column, "tag:")] = struct{}{}
+		}
+	}
+
+	err = cw.Write(columns)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Error writing header of "+format+" output")
+	}
+
+	area_rules := output.GetAreaRules()
+
+	incomplete_ways, incomplete, err := planet.WalkFeatures(output, func(element_type string, element interface{}, f graph.Feature) error {
+
+		geometry, err := planet.elementGeometry(element, area_rules)
+		if err != nil {
+			return err
+		}
+
+		var centroid []float64
+		if geometry != nil {
+			centroid, err = geojsonCentroid(geometry)
+			if err != nil {
+				return err
+			}
+		}
+
+		row := make([]string, 0, len(columns))
+		for _, column := range columns {
+			switch column {
+			case "id":
+				row = append(row, fmt.Sprint(f.Id))
+			case "type":
+				row = append(row, element_type)
+			case "lon":
+				if centroid == nil {
+					row = append(row, "")
+				} else {
+					row = append(row, strconv.FormatFloat(centroid[0], 'f', -1, 64))
+				}
+			case "lat":
+				if centroid == nil {
+					row = append(row, "")
+				} else {
+					row = append(row, strconv.FormatFloat(centroid[1], 'f', -1, 64))
+				}
+			case "wkt":
+				if geometry == nil {
+					row = append(row, "")
+				} else {
+					wkt, err := geojsonToWKT(geometry)
+					if err != nil {
+						return err
+					}
+					row = append(row, wkt)
+				}
+			case "other_tags":
+				other_tags := map[string]interface{}{}
+				for k, v := range f.Properties {
+					if _, ok := tag_columns[k]; !ok {
+						other_tags[k] = v
+					}
+				}
+				row = append(row, MarshalHStore(other_tags))
+			default:
+				row = append(row, stringifyTagValue(f.Properties[strings.TrimPrefix(column, "tag:")]))
+			}
+		}
+
+		err = cw.Write(row)
+		if err != nil {
+			return errors.Wrap(err, "Error writing "+element_type+" "+fmt.Sprint(f.Id)+" to "+format+" output")
+		}
+		return nil
+	})
+	if err != nil {
+		return incomplete_ways, incomplete, err
+	}
+
+	cw.Flush()
+	err = cw.Error()
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error writing "+format+" output")
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error closing writer for "+format+" output.")
+	}
+
+	return incomplete_ways, incomplete, nil
+}
@@ -0,0 +1,113 @@
+package osm
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// MarshalChange writes the change to w as an osmChange (.osc) document.
+// Each action is written as a create, modify, or delete block.  Deletions are written in the order relations, ways, and then nodes,
+// so that no element is deleted before the elements that reference it.
+// Tags and user names are resolved through the planet and the output's drop flags are applied.
+// Returns an error if any.
+func MarshalChange(w io.Writer, planet *Planet, output *Output, c *Change) error {
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return errors.Wrap(err, "Error writing xml header.")
+	}
+
+	encoder := xml.NewEncoder(w)
+	if output.Pretty {
+		encoder.Indent("", "    ")
+	}
+
+	attrs := []xml.Attr{
+		xml.Attr{Name: xml.Name{Space: "", Local: "version"}, Value: "0.6"},
+	}
+	if len(c.Generator) > 0 {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "generator"}, Value: c.Generator})
+	}
+	token_change := xml.StartElement{
+		Name: xml.Name{Space: "", Local: "osmChange"},
+		Attr: attrs,
+	}
+	err = encoder.EncodeToken(token_change)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding osmChange start element.")
+	}
+
+	for _, a := range c.Actions {
+
+		token_action := xml.StartElement{Name: xml.Name{Space: "", Local: a.Type}}
+		err := encoder.EncodeToken(token_action)
+		if err != nil {
+			return errors.Wrap(err, "Error encoding "+a.Type+" start element.")
+		}
+
+		marshalNodes := func() error {
+			for _, n := range a.Nodes {
+				err := MarshalNode(encoder, planet, output, n)
+				if err != nil {
+					return errors.Wrap(err, "Error marshalling node")
+				}
+			}
+			return nil
+		}
+		marshalWays := func() error {
+			for _, w := range a.Ways {
+				err := MarshalWay(encoder, planet, output, w)
+				if err != nil {
+					return errors.Wrap(err, "Error marshalling way")
+				}
+			}
+			return nil
+		}
+		marshalRelations := func() error {
+			for _, r := range a.Relations {
+				err := MarshalRelation(encoder, planet, output, r)
+				if err != nil {
+					return errors.Wrap(err, "Error marshalling relation")
+				}
+			}
+			return nil
+		}
+
+		steps := []func() error{marshalNodes, marshalWays, marshalRelations}
+		if a.Type == CHANGE_DELETE {
+			steps = []func() error{marshalRelations, marshalWays, marshalNodes}
+		}
+		for _, step := range steps {
+			err := step()
+			if err != nil {
+				return err
+			}
+		}
+
+		err = encoder.EncodeToken(token_action.End())
+		if err != nil {
+			return errors.Wrap(err, "Error encoding "+a.Type+" end element.")
+		}
+	}
+
+	err = encoder.EncodeToken(token_change.End())
+	if err != nil {
+		return errors.Wrap(err, "Error encoding osmChange end element.")
+	}
+
+	err = encoder.Flush()
+	if err != nil {
+		return errors.Wrap(err, "Error flushing buffered xml.")
+	}
+
+	_, err = io.WriteString(w, "\n")
+	if err != nil {
+		return errors.Wrap(err, "Error writing xml.")
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package osm
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// MarshalGDAL writes the GDAL layers of the output, with the same features, columns, and other_tags as ogr2ogr reading the planet with the output's osmconf.ini file.
+// If the uri of the output contains {layer}, then each layer is written to the uri with {layer} replaced by the name of the layer, e.g., osm_{layer}.csv.
+// Each layer is written as csv or tsv, with the geometry as Well-Known Text in the first column, WKT, or as GeoJSON or GeoJSONL, with the columns as properties.
+// See WalkGDALLayer.
+// Returns the incomplete ways, the incomplete relations, and an error if any.
+func MarshalGDAL(output *Output, planet *Planet) ([]*IncompleteWay, []*IncompleteRelation, error) {
+	incomplete_ways := make([]*IncompleteWay, 0)
+	incomplete := make([]*IncompleteRelation, 0)
+	for _, name := range output.GetGDALLayers() {
+		iw, ir, err := marshalGDALLayer(output.gdalLayerOutput(name), planet, name)
+		incomplete_ways = append(incomplete_ways, iw...)
+		incomplete = append(incomplete, ir...)
+		if err != nil {
+			return incomplete_ways, incomplete, err
+		}
+	}
+	return incomplete_ways, incomplete, nil
+}
+
+// gdalLayerOutput returns the output with {layer} in its uri replaced by the name of the layer.
+func (o *Output) gdalLayerOutput(name string) *Output {
+	if !strings.Contains(o.Uri, "{layer}") {
+		return o
+	}
+	r := *o.Resource
+	r.Uri = strings.Replace(r.Uri, "{layer}", name, -1)
+	r.Path = strings.Replace(r.Path, "{layer}", name, -1)
+	r.PathExpanded = strings.Replace(r.PathExpanded, "{layer}", name, -1)
+	fr := *o.FilteredResource
+	fr.Resource = &r
+	pr := *o.PlanetResource
+	pr.FilteredResource = &fr
+	lo := *o
+	lo.PlanetResource = &pr
+	return &lo
+}
+
+// marshalGDALLayer writes the GDAL layer with the given name to the output.
+func marshalGDALLayer(output *Output, planet *Planet, name string) ([]*IncompleteWay, []*IncompleteRelation, error) {
+
+	format := output.Format
+	if len(format) == 0 {
+		format = InferFormat(output.Uri)
+	}
+	if format != "csv" && format != "tsv" && format != "geojson" && format != "geojsonl" {
+		return nil, nil, errors.New("Cannot write GDAL layer " + name + " to output " + output.Uri + ".  Expecting a csv, tsv, geojson, or geojsonl output.")
+	}
+
+	writer, format, err := OpenOutputWriter(output)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := output.GDAL.Layer(name).Columns(output.GDAL.Launder)
+
+	var incomplete_ways []*IncompleteWay
+	var incomplete []*IncompleteRelation
+	switch format {
+	case "csv", "tsv":
+		cw := csv.NewWriter(writer)
+		if format == "tsv" {
+			cw.Comma = '\t'
+		}
+		err = cw.Write(append([]string{"WKT"}, columns...))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "Error writing header of GDAL layer "+name)
+		}
+		incomplete_ways, incomplete, err = planet.WalkGDALLayer(output, output.GDAL, name, func(values []interface{}, geometry *geojsonObject) error {
+			row := make([]string, 0, len(values)+1)
+			if geometry == nil {
+				row = append(row, "")
+			} else {
+				wkt, err := geojsonToWKT(geometry)
+				if err != nil {
+					return err
+				}
+				row = append(row, wkt)
+			}
+			for _, v := range values {
+				if v == nil {
+					row = append(row, "")
+				} else {
+					row = append(row, fmt.Sprint(v))
+				}
+			}
+			return cw.Write(row)
+		})
+		if err != nil {
+			return incomplete_ways, incomplete, errors.Wrap(err, "Error writing GDAL layer "+name)
+		}
+		cw.Flush()
+		err = cw.Error()
+		if err != nil {
+			return incomplete_ways, incomplete, errors.Wrap(err, "Error writing GDAL layer "+name)
+		}
+	case "geojson", "geojsonl":
+		if format == "geojson" {
+			_, err = io.WriteString(writer, "{\"type\":\"FeatureCollection\",\"name\":\""+name+"\",\"features\":[\n")
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "Error writing GDAL layer "+name)
+			}
+		}
+		count := 0
+		incomplete_ways, incomplete, err = planet.WalkGDALLayer(output, output.GDAL, name, func(values []interface{}, geometry *geojsonObject) error {
+			b, err := gdalFeatureJSON(columns, values, geometry)
+			if err != nil {
+				return err
+			}
+			if format == "geojson" && count > 0 {
+				_, err = io.WriteString(writer, ",\n")
+				if err != nil {
+					return err
+				}
+			}
+			if format == "geojsonl" {
+				b = append(b, '\n')
+			}
+			_, err = writer.Write(b)
+			count += 1
+			return err
+		})
+		if err != nil {
+			return incomplete_ways, incomplete, errors.Wrap(err, "Error writing GDAL layer "+name)
+		}
+		if format == "geojson" {
+			_, err = io.WriteString(writer, "\n]}\n")
+			if err != nil {
+				return incomplete_ways, incomplete, errors.Wrap(err, "Error writing GDAL layer "+name)
+			}
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error closing writer for GDAL layer "+name+".")
+	}
+
+	return incomplete_ways, incomplete, nil
+}
+
+// gdalFeatureJSON returns a GeoJSON feature with the columns as properties, in order.  Null values are left out, the same as GDAL leaves out unset fields.
+func gdalFeatureJSON(columns []string, values []interface{}, geometry *geojsonObject) ([]byte, error) {
+	properties := make([]string, 0, len(columns))
+	for i, column := range columns {
+		if values[i] == nil {
+			continue
+		}
+		k, err := json.Marshal(column)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error marshalling property "+column)
+		}
+		v, err := json.Marshal(values[i])
+		if err != nil {
+			return nil, errors.Wrap(err, "Error marshalling property "+column)
+		}
+		properties = append(properties, string(k)+":"+string(v))
+	}
+	g := []byte("null")
+	if geometry != nil {
+		var err error
+		g, err = json.Marshal(geometry)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error marshalling geometry")
+		}
+	}
+	return []byte("{\"type\":\"Feature\",\"properties\":{" + strings.Join(properties, ",") + "},\"geometry\":" + string(g) + "}"), nil
+}
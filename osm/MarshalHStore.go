@@ -0,0 +1,26 @@
+package osm
+
+import (
+	"sort"
+	"strings"
+)
+
+// hstoreEscaper escapes the backslashes and double quotes in the keys and values of an hstore.
+var hstoreEscaper = strings.NewReplacer("\\", "\\\\", "\"", "\\\"")
+
+// MarshalHStore returns the tags as a PostgreSQL hstore literal sorted by key, e.g., "amenity"=>"cafe","name"=>"Joe's".
+// This is the format of the other_tags column written by GDAL.  Values that are not strings are written as JSON.
+//	- https://www.postgresql.org/docs/current/hstore.html
+func MarshalHStore(tags map[string]interface{}) string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, "\""+hstoreEscaper.Replace(k)+"\"=>\""+hstoreEscaper.Replace(stringifyTagValue(tags[k]))+"\"")
+	}
+	return strings.Join(pairs, ",")
+}
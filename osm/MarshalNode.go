@@ -13,14 +13,14 @@ import (
 
 func MarshalNode(encoder *xml.Encoder, planet *Planet, output_config *Output, n *Node) error {
 	attrs := []xml.Attr{
-		xml.Attr{Name: xml.Name{Space: "", Local: "id"}, Value: fmt.Sprint(n.Id)},
+		xml.Attr{Name: xml.Name{Space: "", Local: "id"}, Value: strconv.FormatInt(n.SignedId(), 10)},
 		xml.Attr{Name: xml.Name{Space: "", Local: "lat"}, Value: strconv.FormatFloat(n.Latitude, 'f', 6, 64)},
 		xml.Attr{Name: xml.Name{Space: "", Local: "lon"}, Value: strconv.FormatFloat(n.Longitude, 'f', 6, 64)},
 	}
 	if !output_config.DropVersion {
 		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "version"}, Value: fmt.Sprint(n.Version)})
 	}
-	if !output_config.DropTimestamp {
+	if !output_config.DropTimestamp && n.Timestamp != nil {
 		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "timestamp"}, Value: n.Timestamp.Format(time.RFC3339)})
 	}
 	if !output_config.DropChangeset {
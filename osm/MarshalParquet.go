@@ -0,0 +1,188 @@
+package osm
+
+import (
+	"encoding/json"
+)
+
+import (
+	"github.com/pkg/errors"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+// MarshalParquet writes the elements of the planet selected for the output as a GeoParquet file, with one row per element.
+// The elements are selected the same as MarshalPlanet, so the output's filter and drop flags apply.
+// The nodes, ways, and relations are written in separate row groups, in that order.
+//	- id, type, version, timestamp, changeset, uid, and user are the attributes of the element.
+//	- tags is a map of the tags of the element, with the output's keys to keep and drop applied.
+//	- lon and lat are the coordinates of a node.
+//	- nodes is the list of node ids of a way.
+//	- members is the list of the type, ref, and role of each member of a relation.
+//	- geometry is the geometry as Well-Known Binary, or null if the element has no geometry.  See elementGeometry.
+// The GeoParquet metadata of the geometry column, including its geometry types and bbox, is written to the "geo" key of the file metadata.
+//	- https://geoparquet.org/releases/v1.0.0/
+// Ways with missing nodes and relations that cannot be assembled into geometries are written with a null geometry and returned as incomplete ways and relations.
+// Returns the incomplete ways, the incomplete relations, and an error if any.
+func MarshalParquet(output *Output, planet *Planet) ([]*IncompleteWay, []*IncompleteRelation, error) {
+
+	var dfl_cache *dfl.Cache
+	if output.Filter.HasExpression() && output.Filter.UseCache {
+		dfl_cache = dfl.NewCache()
+	}
+
+	incomplete_ways := make([]*IncompleteWay, 0)
+	incomplete := make([]*IncompleteRelation, 0)
+
+	nodes, ways, relations, err := SelectOutputElements(planet, output, dfl_cache)
+	if err != nil {
+		return incomplete_ways, incomplete, err
+	}
+
+	w, _, err := OpenOutputWriter(output)
+	if err != nil {
+		return incomplete_ways, incomplete, err
+	}
+
+	pw, err := writer.NewParquetWriterFromWriter(w, new(parquetElement), 4)
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error creating parquet writer for output "+output.Uri)
+	}
+
+	area_rules := output.GetAreaRules()
+	metadata := newGeoparquetMetadata("geometry")
+
+	write := func(element_type string, element interface{}, te *TaggedElement) error {
+		row := &parquetElement{Id: int64(te.Id), Type: element_type}
+		if !output.DropVersion {
+			version := int32(te.Version)
+			row.Version = &version
+		}
+		if !output.DropTimestamp && te.Timestamp != nil {
+			timestamp := te.Timestamp.UnixNano() / 1000
+			row.Timestamp = &timestamp
+		}
+		if !output.DropChangeset {
+			changeset := int64(te.Changeset)
+			row.Changeset = &changeset
+		}
+		if !output.DropUserId {
+			uid := int64(te.UserId)
+			row.UserId = &uid
+		}
+		if !output.DropUserName {
+			if user, ok := planet.UserNames[te.UserId]; ok {
+				row.UserName = &user
+			}
+		}
+
+		tags := FilterTags(planet.Tags.Slice(te.GetTagsIndex()), output.KeysToKeep, output.KeysToDrop)
+		row.Tags = make(map[string]string, len(tags))
+		for _, tag := range tags {
+			row.Tags[tag.Key] = tag.Value
+		}
+
+		switch e := element.(type) {
+		case *Node:
+			lon := e.Longitude
+			lat := e.Latitude
+			row.Longitude = &lon
+			row.Latitude = &lat
+		case *Way:
+			row.Nodes = make([]int64, 0, len(e.NodeReferences))
+			for _, nr := range e.NodeReferences {
+				row.Nodes = append(row.Nodes, int64(nr.Reference))
+			}
+		case *Relation:
+			row.Members = make([]parquetMember, 0, len(e.Members))
+			for _, m := range e.Members {
+				row.Members = append(row.Members, parquetMember{Type: m.Type, Reference: int64(m.Reference), Role: m.Role})
+			}
+		}
+
+		geometry, err := planet.elementGeometry(element, area_rules)
+		if err != nil {
+			switch x := err.(type) {
+			case *IncompleteWay:
+				incomplete_ways = append(incomplete_ways, x)
+			case *IncompleteRelation:
+				incomplete = append(incomplete, x)
+			default:
+				return err
+			}
+		}
+		if geometry != nil {
+			wkb, err := geojsonToWKB(geometry, 0)
+			if err != nil {
+				return errors.Wrap(err, "Error encoding geometry of "+element_type+" as WKB")
+			}
+			err = metadata.Columns["geometry"].extend(geometry)
+			if err != nil {
+				return err
+			}
+			s := string(wkb)
+			row.Geometry = &s
+		}
+
+		err = pw.Write(row)
+		if err != nil {
+			return errors.Wrap(err, "Error writing "+element_type+" to parquet output")
+		}
+		return nil
+	}
+
+	for _, n := range nodes {
+		err := write("node", n, &n.TaggedElement)
+		if err != nil {
+			return incomplete_ways, incomplete, err
+		}
+	}
+	err = pw.Flush(true)
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error writing row group of nodes to parquet output")
+	}
+
+	for _, way := range ways {
+		err := write("way", way, &way.TaggedElement)
+		if err != nil {
+			return incomplete_ways, incomplete, err
+		}
+	}
+	err = pw.Flush(true)
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error writing row group of ways to parquet output")
+	}
+
+	for _, r := range relations {
+		err := write("relation", r, &r.TaggedElement)
+		if err != nil {
+			return incomplete_ways, incomplete, err
+		}
+	}
+	err = pw.Flush(true)
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error writing row group of relations to parquet output")
+	}
+
+	geo, err := json.Marshal(metadata)
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error marshalling GeoParquet metadata")
+	}
+	geo_value := string(geo)
+	pw.Footer.KeyValueMetadata = append(pw.Footer.KeyValueMetadata, &parquet.KeyValue{Key: "geo", Value: &geo_value})
+
+	err = pw.WriteStop()
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error writing footer of parquet output")
+	}
+
+	err = w.Close()
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error closing writer for parquet output.")
+	}
+
+	return incomplete_ways, incomplete, nil
+}
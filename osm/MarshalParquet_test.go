@@ -0,0 +1,145 @@
+package osm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTestIncompletePlanet returns the test planet with a way that references a missing node
+// and a multipolygon relation that references a missing way.
+func newTestIncompletePlanet(t *testing.T) *Planet {
+	p := newTestPlanet(t)
+
+	w := NewWay()
+	w.Id = 11
+	w.NodeReferences = []NodeReference{NodeReference{Reference: 1}, NodeReference{Reference: 99}}
+	w.TagsIndex = p.AddTags([]Tag{Tag{Key: "highway", Value: "service"}})
+	err := p.AddWay(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRelation()
+	r.Id = 21
+	r.Members = []RelationMember{RelationMember{Type: "way", Reference: 98, Role: "outer"}}
+	r.TagsIndex = p.AddTags([]Tag{Tag{Key: "type", Value: "multipolygon"}})
+	err = p.AddRelation(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+func TestMarshalParquet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "osm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "planet.parquet")
+	output := &Output{PlanetResource: &PlanetResource{FilteredResource: &FilteredResource{Resource: &Resource{Uri: path}}}}
+	err = output.Init(map[string]interface{}{}, map[string]interface{}{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !output.IsParquet() {
+		t.Fatalf("Expected output %s to be parquet.", path)
+	}
+
+	incomplete_ways, incomplete, err := MarshalParquet(output, newTestIncompletePlanet(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(incomplete_ways) != 1 || incomplete_ways[0].Id != 11 {
+		t.Fatalf("Expected way 11 to be incomplete, got %v.", incomplete_ways)
+	}
+	if len(incomplete_ways[0].MissingNodes) != 1 || incomplete_ways[0].MissingNodes[0] != 99 {
+		t.Fatalf("Expected way 11 to be missing node 99, got %v.", incomplete_ways[0].MissingNodes)
+	}
+	if len(incomplete) != 1 || incomplete[0].Id != 21 {
+		t.Fatalf("Expected relation 21 to be incomplete, got %v.", incomplete)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() == 0 {
+		t.Fatalf("Expected parquet output at %s, got an empty file.", path)
+	}
+}
+
+func TestGeojsonToWKB(t *testing.T) {
+	point := func(header ...uint32) []byte {
+		buf := new(bytes.Buffer)
+		buf.WriteByte(1)
+		for _, x := range header {
+			binary.Write(buf, binary.LittleEndian, x)
+		}
+		binary.Write(buf, binary.LittleEndian, math.Float64bits(-77.0365))
+		binary.Write(buf, binary.LittleEndian, math.Float64bits(38.8977))
+		return buf.Bytes()
+	}
+	testCases := []struct {
+		name     string
+		srid     int
+		expected []byte
+	}{
+		{name: "wkb", srid: 0, expected: point(1)},
+		{name: "ewkb", srid: 4326, expected: point(1|WKB_SRID_FLAG, 4326)},
+	}
+	geometry, err := newGeojsonGeometry("Point", []float64{-77.0365, 38.8977})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range testCases {
+		actual, err := geojsonToWKB(geometry, tc.srid)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !bytes.Equal(actual, tc.expected) {
+			t.Fatalf("%s: expected %x, got %x.", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestGeoparquetColumnExtend(t *testing.T) {
+	metadata := newGeoparquetMetadata("geometry")
+	c := metadata.Columns["geometry"]
+	for _, x := range []struct {
+		t           string
+		coordinates interface{}
+	}{
+		{t: "Point", coordinates: []float64{2, 3}},
+		{t: "LineString", coordinates: [][]float64{[]float64{-1, 5}, []float64{4, 1}}},
+		{t: "Point", coordinates: []float64{0, -2}},
+	} {
+		geometry, err := newGeojsonGeometry(x.t, x.coordinates)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = c.extend(geometry)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(c.GeometryTypes) != 2 || c.GeometryTypes[0] != "Point" || c.GeometryTypes[1] != "LineString" {
+		t.Fatalf("Expected geometry types [Point LineString], got %v.", c.GeometryTypes)
+	}
+	expected := []float64{-1, -2, 4, 5}
+	if len(c.Bbox) != len(expected) {
+		t.Fatalf("Expected bbox %v, got %v.", expected, c.Bbox)
+	}
+	for i := range expected {
+		if c.Bbox[i] != expected[i] {
+			t.Fatalf("Expected bbox %v, got %v.", expected, c.Bbox)
+		}
+	}
+}
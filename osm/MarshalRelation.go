@@ -0,0 +1,82 @@
+package osm
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+func MarshalRelation(encoder *xml.Encoder, planet *Planet, output_config *Output, r *Relation) error {
+	attrs := []xml.Attr{
+		xml.Attr{Name: xml.Name{Space: "", Local: "id"}, Value: strconv.FormatInt(r.SignedId(), 10)},
+	}
+	if !output_config.DropVersion {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "version"}, Value: fmt.Sprint(r.Version)})
+	}
+	if !output_config.DropTimestamp && r.Timestamp != nil {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "timestamp"}, Value: r.Timestamp.Format(time.RFC3339)})
+	}
+	if !output_config.DropChangeset {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "changeset"}, Value: fmt.Sprint(r.Changeset)})
+	}
+	if !output_config.DropUserId {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "uid"}, Value: fmt.Sprint(r.UserId)})
+	}
+	if !output_config.DropUserName {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "user"}, Value: fmt.Sprint(planet.UserNames[r.UserId])})
+	}
+	token_relation := xml.StartElement{
+		Name: xml.Name{Space: "", Local: "relation"},
+		Attr: attrs,
+	}
+	err := encoder.EncodeToken(token_relation)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding relation start element.")
+	}
+	for _, m := range r.Members {
+		token_member := xml.StartElement{
+			Name: xml.Name{Space: "", Local: "member"},
+			Attr: []xml.Attr{
+				xml.Attr{Name: xml.Name{Space: "", Local: "type"}, Value: m.Type},
+				xml.Attr{Name: xml.Name{Space: "", Local: "ref"}, Value: strconv.FormatInt(r.SignedReference(m.Reference), 10)},
+				xml.Attr{Name: xml.Name{Space: "", Local: "role"}, Value: m.Role},
+			},
+		}
+		err = encoder.EncodeToken(token_member)
+		if err != nil {
+			return errors.Wrap(err, "Error encoding member element.")
+		}
+		err = encoder.EncodeToken(token_member.End())
+		if err != nil {
+			return errors.Wrap(err, "Error encoding member end element.")
+		}
+	}
+	for _, tagIndex := range r.GetTagsIndex() {
+		tag := planet.GetTag(tagIndex)
+		token_tag := xml.StartElement{
+			Name: xml.Name{Space: "", Local: "tag"},
+			Attr: []xml.Attr{
+				xml.Attr{Name: xml.Name{Space: "", Local: "k"}, Value: fmt.Sprint(tag.Key)},
+				xml.Attr{Name: xml.Name{Space: "", Local: "v"}, Value: fmt.Sprint(tag.Value)},
+			},
+		}
+		err = encoder.EncodeToken(token_tag)
+		if err != nil {
+			return errors.Wrap(err, "Error encoding tag element.")
+		}
+		err = encoder.EncodeToken(token_tag.End())
+		if err != nil {
+			return errors.Wrap(err, "Error encoding bounds end element.")
+		}
+	}
+	err = encoder.EncodeToken(token_relation.End())
+	if err != nil {
+		return errors.Wrap(err, "Error encoding relation end element")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package osm
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ROUTING_EDGE_COLUMNS is the header of the edges CSV written by MarshalRoutingEdgesCSV.
+var ROUTING_EDGE_COLUMNS = []string{"id", "way_id", "source", "target", "highway", "length", "speed", "duration", "forward", "backward", "geometry"}
+
+// ROUTING_VERTEX_COLUMNS is the header of the vertices CSV written by MarshalRoutingVerticesCSV.
+var ROUTING_VERTEX_COLUMNS = []string{"id", "lon", "lat"}
+
+// MarshalRoutingEdgesCSV writes the edges of the graph as CSV, with a header and one row per edge.
+// Length is in meters, speed in kilometers per hour, duration in seconds, and geometry is a WKT LineString.
+func MarshalRoutingEdgesCSV(w io.Writer, g *RoutingGraph) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(ROUTING_EDGE_COLUMNS)
+	if err != nil {
+		return errors.Wrap(err, "Error writing header of edges CSV")
+	}
+	for _, e := range g.Edges {
+		points := make([]string, 0, len(e.Coordinates))
+		for _, c := range e.Coordinates {
+			points = append(points, strconv.FormatFloat(c[0], 'f', -1, 64)+" "+strconv.FormatFloat(c[1], 'f', -1, 64))
+		}
+		err := cw.Write([]string{
+			strconv.FormatUint(e.Id, 10),
+			strconv.FormatUint(e.WayId, 10),
+			strconv.FormatUint(e.Source, 10),
+			strconv.FormatUint(e.Target, 10),
+			e.Highway,
+			strconv.FormatFloat(e.Length, 'f', 2, 64),
+			strconv.FormatFloat(e.Speed, 'f', -1, 64),
+			strconv.FormatFloat(e.Duration(), 'f', 2, 64),
+			strconv.FormatBool(e.Forward),
+			strconv.FormatBool(e.Backward),
+			"LINESTRING(" + strings.Join(points, ", ") + ")",
+		})
+		if err != nil {
+			return errors.Wrap(err, "Error writing edge "+strconv.FormatUint(e.Id, 10)+" to CSV")
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// MarshalRoutingVerticesCSV writes the vertices of the graph as CSV, with a header and one row per vertex.
+func MarshalRoutingVerticesCSV(w io.Writer, g *RoutingGraph) error {
+	cw := csv.NewWriter(w)
+	err := cw.Write(ROUTING_VERTEX_COLUMNS)
+	if err != nil {
+		return errors.Wrap(err, "Error writing header of vertices CSV")
+	}
+	for _, v := range g.Vertices {
+		err := cw.Write([]string{
+			strconv.FormatUint(v.Id, 10),
+			strconv.FormatFloat(v.Longitude, 'f', -1, 64),
+			strconv.FormatFloat(v.Latitude, 'f', -1, 64),
+		})
+		if err != nil {
+			return errors.Wrap(err, "Error writing vertex "+strconv.FormatUint(v.Id, 10)+" to CSV")
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
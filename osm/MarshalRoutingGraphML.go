@@ -0,0 +1,117 @@
+package osm
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+type graphMLKey struct {
+	Id   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	Id   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Id     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLGraph struct {
+	Id          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+// MarshalRoutingGraphML writes the graph as a directed GraphML document.
+// Each vertex is a node with lon and lat data.  Each edge is written once for each direction it can be traveled,
+// with the id of the edge and a suffix of "f" for forward or "b" for backward, and with way_id, highway, length, speed, and duration data.
+func MarshalRoutingGraphML(w io.Writer, g *RoutingGraph, pretty bool) error {
+
+	doc := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			graphMLKey{Id: "lon", For: "node", Name: "lon", Type: "double"},
+			graphMLKey{Id: "lat", For: "node", Name: "lat", Type: "double"},
+			graphMLKey{Id: "way_id", For: "edge", Name: "way_id", Type: "long"},
+			graphMLKey{Id: "highway", For: "edge", Name: "highway", Type: "string"},
+			graphMLKey{Id: "length", For: "edge", Name: "length", Type: "double"},
+			graphMLKey{Id: "speed", For: "edge", Name: "speed", Type: "double"},
+			graphMLKey{Id: "duration", For: "edge", Name: "duration", Type: "double"},
+		},
+		Graph: graphMLGraph{
+			Id:          "osm",
+			EdgeDefault: "directed",
+			Nodes:       make([]graphMLNode, 0, len(g.Vertices)),
+			Edges:       make([]graphMLEdge, 0, len(g.Edges)),
+		},
+	}
+
+	for _, v := range g.Vertices {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			Id: strconv.FormatUint(v.Id, 10),
+			Data: []graphMLData{
+				graphMLData{Key: "lon", Value: strconv.FormatFloat(v.Longitude, 'f', -1, 64)},
+				graphMLData{Key: "lat", Value: strconv.FormatFloat(v.Latitude, 'f', -1, 64)},
+			},
+		})
+	}
+
+	for _, e := range g.Edges {
+		data := []graphMLData{
+			graphMLData{Key: "way_id", Value: strconv.FormatUint(e.WayId, 10)},
+			graphMLData{Key: "highway", Value: e.Highway},
+			graphMLData{Key: "length", Value: strconv.FormatFloat(e.Length, 'f', 2, 64)},
+			graphMLData{Key: "speed", Value: strconv.FormatFloat(e.Speed, 'f', -1, 64)},
+			graphMLData{Key: "duration", Value: strconv.FormatFloat(e.Duration(), 'f', 2, 64)},
+		}
+		source := strconv.FormatUint(e.Source, 10)
+		target := strconv.FormatUint(e.Target, 10)
+		if e.Forward {
+			doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Id: strconv.FormatUint(e.Id, 10) + "f", Source: source, Target: target, Data: data})
+		}
+		if e.Backward {
+			doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Id: strconv.FormatUint(e.Id, 10) + "b", Source: target, Target: source, Data: data})
+		}
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return errors.Wrap(err, "Error writing GraphML header")
+	}
+	enc := xml.NewEncoder(w)
+	if pretty {
+		enc.Indent("", "    ")
+	}
+	err = enc.Encode(doc)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding GraphML")
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
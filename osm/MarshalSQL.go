@@ -0,0 +1,283 @@
+package osm
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+// SQL_COLUMNS is the columns of the tables of sql outputs that are not tags.  Keys to keep cannot have these names, since they are also columns.
+var SQL_COLUMNS = []string{"id", "version", "changeset", "uid", "user", "timestamp", "tags", "nodes", "members", "geom"}
+
+// MarshalSQL writes the elements of the planet selected for the output as SQL for PostgreSQL and PostGIS, which can be piped to psql.
+// The elements are selected the same as MarshalPlanet, so the output's filter and drop flags apply.
+// The nodes, ways, and relations are written to the tables {prefix}_nodes, {prefix}_ways, and {prefix}_relations, each with a DROP TABLE IF EXISTS and a CREATE TABLE statement,
+// a COPY FROM stdin block with one row per element, and a GiST index on the geometry, so the output can be loaded again to replace the tables.
+// Tables of dropped element types are not written.
+// The output is wrapped in a transaction and creates the postgis extension, and the hstore extension if the tags are an hstore.
+//	- id, version, changeset, uid, user, and timestamp are the attributes of the element.  Attributes dropped by the output are left out.
+//	- each key to keep of the output is a text column with the value of the tag with that key.
+//	- tags is the other tags of the element as an hstore or jsonb, the same as osm2pgsql.  Tags with a key to keep or drop are left out.
+//	- nodes is the array of node ids of a way.
+//	- members is the type, ref, and role of each member of a relation as jsonb.
+//	- geom is the geometry as hex-encoded EWKB with the output's SRID, 4326 or 3857, or null if the element has no geometry.  See elementGeometry.
+// Ways with missing nodes and relations that cannot be assembled into geometries are written with a null geometry and returned as incomplete ways and relations.
+// Returns the incomplete ways, the incomplete relations, and an error if any.
+func MarshalSQL(output *Output, planet *Planet) ([]*IncompleteWay, []*IncompleteRelation, error) {
+
+	var dfl_cache *dfl.Cache
+	if output.Filter.HasExpression() && output.Filter.UseCache {
+		dfl_cache = dfl.NewCache()
+	}
+
+	incomplete_ways := make([]*IncompleteWay, 0)
+	incomplete := make([]*IncompleteRelation, 0)
+
+	nodes, ways, relations, err := SelectOutputElements(planet, output, dfl_cache)
+	if err != nil {
+		return incomplete_ways, incomplete, err
+	}
+
+	w, _, err := OpenOutputWriter(output)
+	if err != nil {
+		return incomplete_ways, incomplete, err
+	}
+
+	area_rules := output.GetAreaRules()
+	srid := strconv.Itoa(output.SQLSrid)
+
+	columns := func(element_type string) []sqlColumn {
+		c := []sqlColumn{sqlColumn{Name: "id", Type: "bigint PRIMARY KEY"}}
+		if !output.DropVersion {
+			c = append(c, sqlColumn{Name: "version", Type: "integer"})
+		}
+		if !output.DropChangeset {
+			c = append(c, sqlColumn{Name: "changeset", Type: "bigint"})
+		}
+		if !output.DropUserId {
+			c = append(c, sqlColumn{Name: "uid", Type: "bigint"})
+		}
+		if !output.DropUserName {
+			c = append(c, sqlColumn{Name: "user", Type: "text"})
+		}
+		if !output.DropTimestamp {
+			c = append(c, sqlColumn{Name: "timestamp", Type: "timestamptz"})
+		}
+		for _, key := range output.KeysToKeep {
+			c = append(c, sqlColumn{Name: key, Type: "text"})
+		}
+		c = append(c, sqlColumn{Name: "tags", Type: output.SQLTags})
+		switch element_type {
+		case "node":
+			c = append(c, sqlColumn{Name: "geom", Type: "geometry(Point, " + srid + ")"})
+		case "way":
+			c = append(c, sqlColumn{Name: "nodes", Type: "bigint[]"})
+			c = append(c, sqlColumn{Name: "geom", Type: "geometry(Geometry, " + srid + ")"})
+		case "relation":
+			c = append(c, sqlColumn{Name: "members", Type: "jsonb"})
+			c = append(c, sqlColumn{Name: "geom", Type: "geometry(Geometry, " + srid + ")"})
+		}
+		return c
+	}
+
+	values := func(element_type string, element interface{}, te *TaggedElement) ([]interface{}, error) {
+		v := []interface{}{te.Id}
+		if !output.DropVersion {
+			v = append(v, te.Version)
+		}
+		if !output.DropChangeset {
+			v = append(v, te.Changeset)
+		}
+		if !output.DropUserId {
+			v = append(v, te.UserId)
+		}
+		if !output.DropUserName {
+			if user, ok := planet.UserNames[te.UserId]; ok {
+				v = append(v, user)
+			} else {
+				v = append(v, nil)
+			}
+		}
+		if !output.DropTimestamp {
+			if te.Timestamp == nil {
+				v = append(v, nil)
+			} else {
+				v = append(v, te.Timestamp.UTC().Format(time.RFC3339))
+			}
+		}
+
+		tags := FilterTags(planet.Tags.Slice(te.GetTagsIndex()), []string{}, output.KeysToDrop)
+		for _, key := range output.KeysToKeep {
+			var value interface{}
+			for _, t := range tags {
+				if t.Key == key {
+					value = t.Value
+					break
+				}
+			}
+			v = append(v, value)
+		}
+		// The tags column only has the tags that are not promoted to columns.
+		tags = FilterTags(tags, []string{}, output.KeysToKeep)
+		if output.SQLTags == "jsonb" {
+			m := make(map[string]string, len(tags))
+			for _, t := range tags {
+				m[t.Key] = t.Value
+			}
+			b, err := json.Marshal(m)
+			if err != nil {
+				return v, errors.Wrap(err, "Error marshalling tags of "+element_type+" "+fmt.Sprint(te.Id)+" as JSON")
+			}
+			v = append(v, string(b))
+		} else {
+			m := make(map[string]interface{}, len(tags))
+			for _, t := range tags {
+				m[t.Key] = t.Value
+			}
+			v = append(v, MarshalHStore(m))
+		}
+
+		switch e := element.(type) {
+		case *Way:
+			refs := make([]string, 0, len(e.NodeReferences))
+			for _, nr := range e.NodeReferences {
+				refs = append(refs, fmt.Sprint(nr.Reference))
+			}
+			v = append(v, "{"+strings.Join(refs, ",")+"}")
+		case *Relation:
+			members := make([]osmjsonMember, 0, len(e.Members))
+			for _, m := range e.Members {
+				members = append(members, osmjsonMember{Type: m.Type, Reference: int64(m.Reference), Role: m.Role})
+			}
+			b, err := json.Marshal(members)
+			if err != nil {
+				return v, errors.Wrap(err, "Error marshalling members of relation "+fmt.Sprint(te.Id)+" as JSON")
+			}
+			v = append(v, string(b))
+		}
+
+		geometry, err := planet.elementGeometry(element, area_rules)
+		if err != nil {
+			switch x := err.(type) {
+			case *IncompleteWay:
+				incomplete_ways = append(incomplete_ways, x)
+			case *IncompleteRelation:
+				incomplete = append(incomplete, x)
+			default:
+				return v, err
+			}
+		}
+		if geometry == nil {
+			v = append(v, nil)
+		} else {
+			wkb, err := geojsonToWKB(geometry, output.SQLSrid)
+			if err != nil {
+				return v, errors.Wrap(err, "Error encoding geometry of "+element_type+" "+fmt.Sprint(te.Id)+" as EWKB")
+			}
+			v = append(v, strings.ToUpper(hex.EncodeToString(wkb)))
+		}
+
+		return v, nil
+	}
+
+	table := func(element_type string, name string, elements int, element func(i int) (interface{}, *TaggedElement)) error {
+		c := columns(element_type)
+		definitions := make([]string, 0, len(c))
+		names := make([]string, 0, len(c))
+		for _, column := range c {
+			definitions = append(definitions, "\t"+sqlQuoteIdentifier(column.Name)+" "+column.Type)
+			names = append(names, sqlQuoteIdentifier(column.Name))
+		}
+		table_name := sqlQuoteIdentifier(name)
+		_, err := w.WriteString("DROP TABLE IF EXISTS " + table_name + ";\n\nCREATE TABLE " + table_name + " (\n" + strings.Join(definitions, ",\n") + "\n);\n\n")
+		if err != nil {
+			return err
+		}
+		_, err = w.WriteString("COPY " + table_name + " (" + strings.Join(names, ", ") + ") FROM stdin;\n")
+		if err != nil {
+			return err
+		}
+		for i := 0; i < elements; i++ {
+			e, te := element(i)
+			v, err := values(element_type, e, te)
+			if err != nil {
+				return err
+			}
+			row := make([]string, 0, len(v))
+			for _, x := range v {
+				row = append(row, sqlCopyValue(x))
+			}
+			_, err = w.WriteString(strings.Join(row, "\t") + "\n")
+			if err != nil {
+				return errors.Wrap(err, "Error writing "+element_type+" "+fmt.Sprint(te.Id)+" to sql output")
+			}
+		}
+		_, err = w.WriteString("\\.\n\nCREATE INDEX ON " + table_name + " USING GIST (\"geom\");\n\n")
+		return err
+	}
+
+	_, err = w.WriteString("BEGIN;\n\nCREATE EXTENSION IF NOT EXISTS postgis;\n")
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error writing sql output")
+	}
+	if output.SQLTags == "hstore" {
+		_, err = w.WriteString("CREATE EXTENSION IF NOT EXISTS hstore;\n")
+		if err != nil {
+			return incomplete_ways, incomplete, errors.Wrap(err, "Error writing sql output")
+		}
+	}
+	_, err = w.WriteString("\n")
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error writing sql output")
+	}
+
+	if !output.DropNodes {
+		err = table("node", output.SQLPrefix+"_nodes", len(nodes), func(i int) (interface{}, *TaggedElement) {
+			return nodes[i], &nodes[i].TaggedElement
+		})
+		if err != nil {
+			return incomplete_ways, incomplete, errors.Wrap(err, "Error writing table of nodes to sql output")
+		}
+	}
+
+	if !output.DropWays {
+		err = table("way", output.SQLPrefix+"_ways", len(ways), func(i int) (interface{}, *TaggedElement) {
+			return ways[i], &ways[i].TaggedElement
+		})
+		if err != nil {
+			return incomplete_ways, incomplete, errors.Wrap(err, "Error writing table of ways to sql output")
+		}
+	}
+
+	if !output.DropRelations {
+		err = table("relation", output.SQLPrefix+"_relations", len(relations), func(i int) (interface{}, *TaggedElement) {
+			return relations[i], &relations[i].TaggedElement
+		})
+		if err != nil {
+			return incomplete_ways, incomplete, errors.Wrap(err, "Error writing table of relations to sql output")
+		}
+	}
+
+	_, err = w.WriteString("COMMIT;\n")
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error writing sql output")
+	}
+
+	err = w.Close()
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error closing writer for sql output.")
+	}
+
+	return incomplete_ways, incomplete, nil
+}
@@ -0,0 +1,163 @@
+package osm
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testSQLPlanet = `<?xml version="1.0" encoding="UTF-8"?>
+<osm version="0.6" generator="test">
+  <node id="1" lat="38.8977" lon="-77.0365">
+    <tag k="name" v="Café&#9;&quot;Zoë&quot;&#10;Annex"/>
+    <tag k="highway" v="bus_stop"/>
+    <tag k="amenity" v="cafe"/>
+    <tag k="addr:street" v="Pennsylvania Avenue"/>
+  </node>
+  <node id="2" lat="38.9" lon="-77.04">
+    <tag k="shop" v="bakery"/>
+  </node>
+  <way id="10"><nd ref="1"/><nd ref="99"/><tag k="highway" v="service"/></way>
+</osm>
+`
+
+// readTestSQLTable returns the rows of the COPY block of the table in the sql output, with each row as a map of column name to value as written.
+func readTestSQLTable(t *testing.T, sql string, table string) []map[string]string {
+	start := "COPY " + sqlQuoteIdentifier(table) + " ("
+	i := strings.Index(sql, start)
+	if i == -1 {
+		t.Fatalf("Expected a COPY block for table %s, got %q.", table, sql)
+	}
+	lines := strings.Split(sql[i+len(start):], "\n")
+	columns := strings.Split(strings.TrimSuffix(lines[0], ") FROM stdin;"), ", ")
+	rows := make([]map[string]string, 0)
+	for _, line := range lines[1:] {
+		if line == "\\." {
+			return rows
+		}
+		values := strings.Split(line, "\t")
+		if len(values) != len(columns) {
+			t.Fatalf("Expected %d values in row of table %s, got %q.", len(columns), table, line)
+		}
+		row := map[string]string{}
+		for j, column := range columns {
+			row[strings.Trim(column, "\"")] = values[j]
+		}
+		rows = append(rows, row)
+	}
+	t.Fatalf("Expected the COPY block of table %s to end with \\., got %q.", table, sql)
+	return rows
+}
+
+func TestMarshalSQL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "osm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	input_path := filepath.Join(dir, "planet.osm")
+	err = ioutil.WriteFile(input_path, []byte(testSQLPlanet), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output_path := filepath.Join(dir, "planet.sql")
+
+	c := &Config{
+		InputConfigs:  []InputConfig{InputConfig{Uri: input_path}},
+		OutputConfigs: []OutputConfig{OutputConfig{Uri: output_path, KeysToKeep: []string{"name", "highway"}, KeysToDrop: []string{"addr:street"}}},
+	}
+	err = c.Init(map[string]interface{}{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Inputs[0].KeysToKeep) != 0 {
+		t.Fatalf("Expected the input to keep every key for the tags column of the sql output, got %v.", c.Inputs[0].KeysToKeep)
+	}
+
+	p := NewPlanet()
+	err = c.Inputs[0].Open(4096, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = UnmarshalPlanet(p, c.Inputs[0], nil)
+	c.Inputs[0].Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	incomplete_ways, incomplete, err := MarshalSQL(c.Outputs[0], p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(incomplete_ways) != 1 || incomplete_ways[0].Id != 10 {
+		t.Fatalf("Expected way 10 to be incomplete, got %v.", incomplete_ways)
+	}
+	if len(incomplete) != 0 {
+		t.Fatalf("Expected no incomplete relations, got %v.", incomplete)
+	}
+
+	b, err := ioutil.ReadFile(output_path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql := string(b)
+
+	for _, table := range []string{"osm_nodes", "osm_ways", "osm_relations"} {
+		if !strings.Contains(sql, "DROP TABLE IF EXISTS "+sqlQuoteIdentifier(table)+";\n\nCREATE TABLE "+sqlQuoteIdentifier(table)+" (") {
+			t.Fatalf("Expected table %s to be dropped if it exists before it is created, got %q.", table, sql)
+		}
+	}
+
+	nodes := readTestSQLTable(t, sql, "osm_nodes")
+	testCases := []struct {
+		name    string
+		highway string
+		tags    string
+	}{
+		{name: "Café\\t\"Zoë\"\\nAnnex", highway: "bus_stop", tags: "\"amenity\"=>\"cafe\""},
+		{name: "\\N", highway: "\\N", tags: "\"shop\"=>\"bakery\""},
+	}
+	if len(nodes) != len(testCases) {
+		t.Fatalf("Expected %d nodes, got %v.", len(testCases), nodes)
+	}
+	for i, tc := range testCases {
+		if nodes[i]["name"] != tc.name {
+			t.Fatalf("Expected name column %q, got %q.", tc.name, nodes[i]["name"])
+		}
+		if nodes[i]["highway"] != tc.highway {
+			t.Fatalf("Expected highway column %q, got %q.", tc.highway, nodes[i]["highway"])
+		}
+		if nodes[i]["tags"] != tc.tags {
+			t.Fatalf("Expected tags column %q, got %q.", tc.tags, nodes[i]["tags"])
+		}
+	}
+
+	ways := readTestSQLTable(t, sql, "osm_ways")
+	if len(ways) != 1 || ways[0]["nodes"] != "{1,99}" || ways[0]["geom"] != "\\N" {
+		t.Fatalf("Expected way 10 with nodes {1,99} and a null geometry, got %v.", ways)
+	}
+}
+
+func TestSQLCopyValue(t *testing.T) {
+	testCases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{value: nil, expected: "\\N"},
+		{value: "\\N", expected: "\\\\N"},
+		{value: "a\tb", expected: "a\\tb"},
+		{value: "a\nb\r\n", expected: "a\\nb\\r\\n"},
+		{value: "C:\\osm", expected: "C:\\\\osm"},
+		{value: "\"quoted\"", expected: "\"quoted\""},
+		{value: uint64(42), expected: "42"},
+	}
+	for _, tc := range testCases {
+		actual := sqlCopyValue(tc.value)
+		if actual != tc.expected {
+			t.Fatalf("Expected %q for %q, got %q.", tc.expected, tc.value, actual)
+		}
+	}
+}
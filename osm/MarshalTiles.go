@@ -0,0 +1,134 @@
+// +build !js
+
+package osm
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// MarshalTiles cuts the features of the planet that pass the output filter into Mapbox Vector Tiles for each zoom level from output.MinZoom to output.MaxZoom.
+// The features are written to a single layer named output.Layer, with ids that encode the element type.  See GetTileFeatures, cutTileFeature, and TileFeature.FeatureId.
+// If the output path ends in .mbtiles, then the tiles are written to an MBTiles file, otherwise to a directory tree built from the path, e.g., tiles/{z}/{x}/{y}.pbf.
+// Returns the ways and relations that could not be converted into features, and an error if any.
+func MarshalTiles(output *Output, planet *Planet) ([]*IncompleteWay, []*IncompleteRelation, error) {
+
+	if output.Scheme != "file" {
+		return make([]*IncompleteWay, 0), make([]*IncompleteRelation, 0), errors.New("Tiles can only be written to local files, not " + output.Uri)
+	}
+
+	features, incomplete_ways, incomplete, err := planet.GetTileFeatures(output)
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error getting features from planet")
+	}
+
+	var w TileWriter
+	if strings.HasSuffix(output.PathExpanded, ".mbtiles") {
+		w, err = NewMBTilesWriter(output.PathExpanded, tileMetadata(output, features))
+		if err != nil {
+			return incomplete_ways, incomplete, err
+		}
+	} else {
+		w = NewDirectoryTileWriter(output.PathExpanded)
+	}
+
+	layers := map[[3]int]*VectorTileLayer{}
+	for _, f := range features {
+		cutTileFeature(f, output.MinZoom, output.MaxZoom, output.TileExtent, output.TileBuffer, output.Simplify, func(z int, x int, y int, geometry_type int, geometry []uint64) {
+			l, ok := layers[[3]int{z, x, y}]
+			if !ok {
+				l = NewVectorTileLayer(output.Layer, output.TileExtent)
+				layers[[3]int{z, x, y}] = l
+			}
+			l.AddFeature(f.FeatureId(), geometry_type, geometry, f.Properties)
+		})
+	}
+
+	tiles := make([][3]int, 0, len(layers))
+	for t := range layers {
+		tiles = append(tiles, t)
+	}
+	sort.Slice(tiles, func(i, j int) bool {
+		for k := range tiles[i] {
+			if tiles[i][k] != tiles[j][k] {
+				return tiles[i][k] < tiles[j][k]
+			}
+		}
+		return false
+	})
+
+	for _, t := range tiles {
+		err := w.WriteTile(t[0], t[1], t[2], EncodeVectorTile(layers[t]))
+		if err != nil {
+			w.Close()
+			return incomplete_ways, incomplete, errors.Wrap(err, "Error writing tile "+strconv.Itoa(t[0])+"/"+strconv.Itoa(t[1])+"/"+strconv.Itoa(t[2]))
+		}
+	}
+
+	err = w.Close()
+	if err != nil {
+		return incomplete_ways, incomplete, errors.Wrap(err, "Error closing tiles "+output.Uri)
+	}
+
+	return incomplete_ways, incomplete, nil
+}
+
+// tileMetadata returns the metadata of an MBTiles file for the features, including the bounds of the features and the vector_layers json.
+func tileMetadata(output *Output, features []*TileFeature) map[string]string {
+
+	bounds := NewBounds(180.0, 90.0, -180.0, -90.0)
+	fields := map[string]string{}
+	for _, f := range features {
+		for _, line := range append(append([][][]float64{f.Points}, f.Lines...), polygonOuterRings(f.Polygons)...) {
+			for _, c := range line {
+				bounds.ExtendPoint(c[0], c[1])
+			}
+		}
+		for k := range f.Properties {
+			fields[k] = "String"
+		}
+	}
+	if len(features) == 0 {
+		bounds = NewBounds(-180.0, -MAX_MERCATOR_LATITUDE, 180.0, MAX_MERCATOR_LATITUDE)
+	}
+
+	vector_layers, _ := json.Marshal(map[string]interface{}{
+		"vector_layers": []map[string]interface{}{
+			map[string]interface{}{
+				"id":      output.Layer,
+				"fields":  fields,
+				"minzoom": output.MinZoom,
+				"maxzoom": output.MaxZoom,
+			},
+		},
+	})
+
+	name := filepath.Base(output.PathExpanded)
+	return map[string]string{
+		"name":    strings.TrimSuffix(name, filepath.Ext(name)),
+		"format":  "pbf",
+		"type":    "overlay",
+		"minzoom": strconv.Itoa(output.MinZoom),
+		"maxzoom": strconv.Itoa(output.MaxZoom),
+		"bounds":  bounds.BoundingBox(),
+		"json":    string(vector_layers),
+	}
+}
+
+// polygonOuterRings returns the outer ring of each polygon.
+func polygonOuterRings(polygons [][][][]float64) [][][]float64 {
+	rings := make([][][]float64, 0, len(polygons))
+	for _, p := range polygons {
+		if len(p) > 0 {
+			rings = append(rings, p[0])
+		}
+	}
+	return rings
+}
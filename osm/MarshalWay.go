@@ -3,7 +3,7 @@ package osm
 import (
 	"encoding/xml"
 	"fmt"
-	//"strconv"
+	"strconv"
 	"time"
 )
 
@@ -13,22 +13,22 @@ import (
 
 func MarshalWay(encoder *xml.Encoder, planet *Planet, output_config *Output, w *Way) error {
 	attrs := []xml.Attr{
-		xml.Attr{Name: xml.Name{Space: "", Local: "id"}, Value: fmt.Sprint(w.Id)},
+		xml.Attr{Name: xml.Name{Space: "", Local: "id"}, Value: strconv.FormatInt(w.SignedId(), 10)},
 	}
 	if !output_config.DropVersion {
 		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "version"}, Value: fmt.Sprint(w.Version)})
 	}
-	if !output_config.DropTimestamp {
+	if !output_config.DropTimestamp && w.Timestamp != nil {
 		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "timestamp"}, Value: w.Timestamp.Format(time.RFC3339)})
 	}
 	if !output_config.DropChangeset {
 		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "changeset"}, Value: fmt.Sprint(w.Changeset)})
 	}
-	if !output_config.DropUserName {
+	if !output_config.DropUserId {
 		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "uid"}, Value: fmt.Sprint(w.UserId)})
 	}
 	if !output_config.DropUserName {
-		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "user"}, Value: fmt.Sprint(w.UserName)})
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "user"}, Value: fmt.Sprint(planet.UserNames[w.UserId])})
 	}
 	token_way := xml.StartElement{
 		Name: xml.Name{Space: "", Local: "way"},
@@ -42,7 +42,7 @@ func MarshalWay(encoder *xml.Encoder, planet *Planet, output_config *Output, w *
 		token_nr := xml.StartElement{
 			Name: xml.Name{Space: "", Local: "nd"},
 			Attr: []xml.Attr{
-				xml.Attr{Name: xml.Name{Space: "", Local: "ref"}, Value: fmt.Sprint(nr.Reference)},
+				xml.Attr{Name: xml.Name{Space: "", Local: "ref"}, Value: strconv.FormatInt(w.SignedReference(nr.Reference), 10)},
 			},
 		}
 		err = encoder.EncodeToken(token_nr)
@@ -0,0 +1,34 @@
+package osm
+
+import (
+	"fmt"
+)
+
+const (
+	MERGE_ERROR            = "error"            // return an error if an element is present in multiple inputs
+	MERGE_FIRST            = "first"            // keep the element read first
+	MERGE_NEWEST_VERSION   = "newest-version"   // keep the element with the highest version
+	MERGE_NEWEST_TIMESTAMP = "newest-timestamp" // keep the element with the latest timestamp
+)
+
+// MERGE_POLICIES is the list of supported policies for resolving elements present in multiple inputs.
+var MERGE_POLICIES = []string{MERGE_ERROR, MERGE_FIRST, MERGE_NEWEST_VERSION, MERGE_NEWEST_TIMESTAMP}
+
+// MergeConflict describes an element that was present in multiple inputs and how it was resolved.
+type MergeConflict struct {
+	Type            string // the type of the element: node, way, or relation
+	Id              uint64 // the id of the element
+	Uri             string // the uri of the input with the duplicate element
+	ExistingVersion uint16 // the version of the element already in the planet
+	IncomingVersion uint16 // the version of the duplicate element
+	Replaced        bool   // true if the duplicate element replaced the element already in the planet
+}
+
+// String returns a description of the conflict.
+func (c *MergeConflict) String() string {
+	resolution := "kept existing version " + fmt.Sprint(c.ExistingVersion)
+	if c.Replaced {
+		resolution = "replaced version " + fmt.Sprint(c.ExistingVersion) + " with version " + fmt.Sprint(c.IncomingVersion)
+	}
+	return c.Type + " " + fmt.Sprint(c.Id) + " from " + c.Uri + " is a duplicate, " + resolution + "."
+}
@@ -0,0 +1,93 @@
+package osm
+
+import (
+	"fmt"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// replaceElement returns true if the incoming element should replace the existing element with the same id under the merge policy.
+// Ties keep the existing element, so the result only depends on the order of the inputs.
+func replaceElement(policy string, existing *Element, incoming *Element) bool {
+	switch policy {
+	case MERGE_NEWEST_VERSION:
+		return incoming.Version > existing.Version
+	case MERGE_NEWEST_TIMESTAMP:
+		if incoming.Timestamp == nil {
+			return false
+		}
+		if existing.Timestamp == nil {
+			return true
+		}
+		if incoming.Timestamp.Equal(*existing.Timestamp) {
+			return incoming.Version > existing.Version
+		}
+		return incoming.Timestamp.After(*existing.Timestamp)
+	}
+	return false
+}
+
+// MergeNode adds the node to the planet, resolving a node with the same id using the input's merge policy.
+// If the node is a duplicate, then the conflict is appended to the planet's conflicts.
+// Returns an error if the merge policy is "error" and the node is a duplicate.
+func (p *Planet) MergeNode(input *Input, n *Node) error {
+	i, ok := p.nodesIndex[n.Id]
+	if !ok {
+		return p.AddNode(n)
+	}
+	if input.MergePolicy == MERGE_ERROR {
+		return errors.New("Node with id " + fmt.Sprint(n.Id) + " from " + input.Uri + " already exists in the planet.")
+	}
+	c := &MergeConflict{Type: "node", Id: n.Id, Uri: input.Uri, ExistingVersion: p.Nodes[i].Version, IncomingVersion: n.Version}
+	if replaceElement(input.MergePolicy, &p.Nodes[i].Element, &n.Element) {
+		p.Nodes[i] = n
+		c.Replaced = true
+		p.rtreeValid = false
+	}
+	p.Conflicts = append(p.Conflicts, c)
+	return nil
+}
+
+// MergeWay adds the way to the planet, resolving a way with the same id using the input's merge policy.
+// If the way is a duplicate, then the conflict is appended to the planet's conflicts.
+// Returns an error if the merge policy is "error" and the way is a duplicate.
+func (p *Planet) MergeWay(input *Input, w *Way) error {
+	i, ok := p.waysIndex[w.Id]
+	if !ok {
+		return p.AddWay(w)
+	}
+	if input.MergePolicy == MERGE_ERROR {
+		return errors.New("Way with id " + fmt.Sprint(w.Id) + " from " + input.Uri + " already exists in the planet.")
+	}
+	c := &MergeConflict{Type: "way", Id: w.Id, Uri: input.Uri, ExistingVersion: p.Ways[i].Version, IncomingVersion: w.Version}
+	if replaceElement(input.MergePolicy, &p.Ways[i].Element, &w.Element) {
+		p.Ways[i] = w
+		c.Replaced = true
+		p.rtreeValid = false
+	}
+	p.Conflicts = append(p.Conflicts, c)
+	return nil
+}
+
+// MergeRelation adds the relation to the planet, resolving a relation with the same id using the input's merge policy.
+// If the relation is a duplicate, then the conflict is appended to the planet's conflicts.
+// Returns an error if the merge policy is "error" and the relation is a duplicate.
+func (p *Planet) MergeRelation(input *Input, r *Relation) error {
+	i, ok := p.relationsIndex[r.Id]
+	if !ok {
+		return p.AddRelation(r)
+	}
+	if input.MergePolicy == MERGE_ERROR {
+		return errors.New("Relation with id " + fmt.Sprint(r.Id) + " from " + input.Uri + " already exists in the planet.")
+	}
+	c := &MergeConflict{Type: "relation", Id: r.Id, Uri: input.Uri, ExistingVersion: p.Relations[i].Version, IncomingVersion: r.Version}
+	if replaceElement(input.MergePolicy, &p.Relations[i].Element, &r.Element) {
+		p.Relations[i] = r
+		c.Replaced = true
+		p.rtreeValid = false
+	}
+	p.Conflicts = append(p.Conflicts, c)
+	return nil
+}
@@ -0,0 +1,114 @@
+package osm
+
+import (
+	"math"
+	"sort"
+)
+
+// NEAREST_RADIUS is the half height in degrees of the first bounding box searched for the elements nearest to a point.  See Planet.Nearest.
+const NEAREST_RADIUS = 0.01
+
+// Nearest returns up to k nodes, ways, and relations nearest to the point that pass the filter, ordered by distance.
+// The elements are *Node, *Way, or *Relation.  If the filter is nil, then every element is a candidate.
+// Distance is the haversine distance to the nearest point of the bounding box of each element, so ways and relations are as near as their bounding boxes.
+// The R-tree is searched in bounding boxes around the point that double in size until they contain k elements nearer than any element outside of the box.
+// Builds the R-tree if the planet changed since it was last built.
+// Returns the elements and an error if any.
+func (p *Planet) Nearest(lon float64, lat float64, k int, fi *Filter) ([]interface{}, error) {
+
+	elements := make([]interface{}, 0, k)
+	if k <= 0 {
+		return elements, nil
+	}
+
+	tree := p.rtree()
+
+	type candidate struct {
+		element  interface{}
+		distance float64
+	}
+
+	for radius := NEAREST_RADIUS; ; radius *= 2 {
+
+		b, whole := nearestSearchBounds(lon, lat, radius)
+		rect, err := b.Rect()
+		if err != nil {
+			return elements, err
+		}
+
+		candidates := make([]candidate, 0)
+		for _, obj := range tree.SearchIntersect(rect) {
+			keep := true
+			c := candidate{}
+			switch e := obj.(type) {
+			case *Node:
+				if fi != nil {
+					keep, err = KeepNode(p, fi, e, nil)
+				}
+				c = candidate{element: e, distance: Haversine(lon, lat, e.Longitude, e.Latitude)}
+			case *SpatialElement:
+				switch x := e.Element.(type) {
+				case *Way:
+					if fi != nil {
+						keep, err = KeepWay(p, fi, x, nil)
+					}
+				case *Relation:
+					if fi != nil {
+						keep, err = KeepRelation(p, fi, x, nil)
+					}
+				}
+				extent := e.Extent
+				c = candidate{
+					element: e.Element,
+					distance: Haversine(
+						lon,
+						lat,
+						math.Max(extent.MinimumLongitude, math.Min(lon, extent.MaximumLongitude)),
+						math.Max(extent.MinimumLatitude, math.Min(lat, extent.MaximumLatitude))),
+				}
+			default:
+				continue
+			}
+			if err != nil {
+				return elements, err
+			}
+			if keep {
+				candidates = append(candidates, c)
+			}
+		}
+
+		sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].distance < candidates[j].distance })
+
+		// Every element nearer than the radius, as a distance on the surface of the Earth, is in the bounding box.
+		if !whole && (len(candidates) < k || candidates[k-1].distance > radius*math.Pi/180.0*EARTH_RADIUS) {
+			continue
+		}
+
+		for i := 0; i < len(candidates) && i < k; i++ {
+			elements = append(elements, candidates[i].element)
+		}
+		return elements, nil
+	}
+}
+
+// nearestSearchBounds returns the bounding box that contains every point within radius degrees of arc of the point,
+// and whether the box covers the whole world.
+// The longitude of the box is widened away from the equator, since meridians converge towards the poles.
+// If the circle contains a pole or crosses the antimeridian, then the box spans every longitude.
+func nearestSearchBounds(lon float64, lat float64, radius float64) (*Bounds, bool) {
+	minlat := math.Max(lat-radius, -90)
+	maxlat := math.Min(lat+radius, 90)
+	if minlat == -90 && maxlat == 90 {
+		return NewBounds(-180, -90, 180, 90), true
+	}
+	d := radius * math.Pi / 180.0
+	phi := lat * math.Pi / 180.0
+	if minlat == -90 || maxlat == 90 || math.Sin(d) >= math.Cos(phi) {
+		return NewBounds(-180, minlat, 180, maxlat), false
+	}
+	dlon := math.Asin(math.Sin(d)/math.Cos(phi)) * 180.0 / math.Pi
+	if lon-dlon < -180 || lon+dlon > 180 {
+		return NewBounds(-180, minlat, 180, maxlat), false
+	}
+	return NewBounds(lon-dlon, minlat, lon+dlon, maxlat), false
+}
@@ -0,0 +1,124 @@
+package osm
+
+import (
+	"sync"
+	"testing"
+)
+
+// newTestArcticPlanet returns a planet near the 80th parallel, where a degree of longitude is much shorter than a degree of latitude.
+func newTestArcticPlanet(t *testing.T) *Planet {
+	p := NewPlanet()
+	for _, x := range []struct {
+		id  uint64
+		lon float64
+		lat float64
+	}{
+		{id: 1, lon: 0.5, lat: 80},
+		{id: 2, lon: 0, lat: 80.2},
+		{id: 3, lon: 10, lat: 80},
+		{id: 4, lon: 10, lat: 81},
+	} {
+		n := &Node{Longitude: x.lon, Latitude: x.lat}
+		n.Id = x.id
+		err := p.AddNode(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := NewWay()
+	w.Id = 10
+	w.NodeReferences = []NodeReference{NodeReference{Reference: 3}, NodeReference{Reference: 4}}
+	err := p.AddWay(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// elementIds returns the ids of the nodes, ways, and relations.
+func elementIds(elements []interface{}) []uint64 {
+	ids := make([]uint64, 0, len(elements))
+	for _, e := range elements {
+		switch x := e.(type) {
+		case *Node:
+			ids = append(ids, x.Id)
+		case *Way:
+			ids = append(ids, x.Id)
+		case *Relation:
+			ids = append(ids, x.Id)
+		}
+	}
+	return ids
+}
+
+func TestNearest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		lon      float64
+		lat      float64
+		k        int
+		expected []uint64
+	}{
+		{name: "haversine order", lon: 0, lat: 80, k: 2, expected: []uint64{1, 2}},
+		{name: "way bounding box", lon: 10.1, lat: 80.5, k: 1, expected: []uint64{10}},
+		{name: "far away", lon: -120, lat: -45, k: 1, expected: []uint64{2}},
+		{name: "more than the planet", lon: 0, lat: 80, k: 10, expected: []uint64{1, 2, 3, 10, 4}},
+		{name: "none", lon: 0, lat: 80, k: 0, expected: []uint64{}},
+	}
+	p := newTestArcticPlanet(t)
+	for _, tc := range testCases {
+		elements, err := p.Nearest(tc.lon, tc.lat, tc.k, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		actual := elementIds(elements)
+		if len(actual) != len(tc.expected) {
+			t.Fatalf("%s: expected %v, got %v.", tc.name, tc.expected, actual)
+		}
+		for i := range tc.expected {
+			if actual[i] != tc.expected[i] {
+				t.Fatalf("%s: expected %v, got %v.", tc.name, tc.expected, actual)
+			}
+		}
+	}
+}
+
+func TestNearestConcurrent(t *testing.T) {
+	p := newTestArcticPlanet(t)
+	var wg sync.WaitGroup
+	errs := make(chan error, 8)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := p.Nearest(0, 80, 1, nil); err != nil {
+				errs <- err
+			}
+			if _, _, _, err := p.QueryBBox(Bounds{MinimumLongitude: -1, MinimumLatitude: 79, MaximumLongitude: 1, MaximumLatitude: 81}); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryBBox(t *testing.T) {
+	p := newTestArcticPlanet(t)
+
+	nodes, ways, relations, err := p.QueryBBox(Bounds{MinimumLongitude: 9, MinimumLatitude: 80.4, MaximumLongitude: 11, MaximumLatitude: 80.6})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 0 || len(ways) != 1 || ways[0].Id != 10 || len(relations) != 0 {
+		t.Fatalf("Expected way 10, got %d nodes, %d ways, and %d relations.", len(nodes), len(ways), len(relations))
+	}
+
+	_, _, _, err = p.QueryBBox(Bounds{MinimumLongitude: 11, MinimumLatitude: 80, MaximumLongitude: 9, MaximumLatitude: 81})
+	if err == nil {
+		t.Fatal("Expected an error for bounds with the minimum longitude greater than the maximum longitude.")
+	}
+}
@@ -1,12 +1,7 @@
 package osm
 
-import (
-	"math"
-)
-
 import (
 	"github.com/dhconnelly/rtreego"
-	"github.com/golang/geo/s1"
 )
 
 type Node struct {
@@ -16,19 +11,18 @@ type Node struct {
 }
 
 func (n Node) Bounds() *rtreego.Rect {
-	rect, err := rtreego.NewRect(rtreego.Point([]float64{n.Longitude, n.Latitude}), []float64{0, 0})
+	rect, err := rtreego.NewRect(rtreego.Point([]float64{n.Longitude, n.Latitude}), []float64{RTREE_TOLERANCE, RTREE_TOLERANCE})
 	if err != nil {
 		panic(err)
 	}
 	return rect
 }
 
+// Tile returns the [x, y] coordinates of the XYZ tile at zoom level z that contains the node.
+// Latitudes beyond the limits of the Web Mercator projection are clamped to the first or last row of tiles.  See projectTile and pixelTile.
 func (n Node) Tile(z int) []int {
-	x := int((180 + n.Longitude) * (math.Pow(2, float64(z)) / 360))
-
-	lat_rad := s1.Angle(n.Latitude).Radians()
-	y := int((1.0 - math.Log(math.Tan(lat_rad)+(1/math.Cos(lat_rad)))/math.Pi) / 2.0 * math.Pow(2, float64(z)))
-
+	px, py := projectTile(n.Longitude, n.Latitude, z, 1)
+	x, y := pixelTile(px, py, z, 1)
 	return []int{x, y}
 }
 
@@ -0,0 +1,434 @@
+package osm
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	O5M_NODE      = 0x10 // node dataset
+	O5M_WAY       = 0x11 // way dataset
+	O5M_RELATION  = 0x12 // relation dataset
+	O5M_BOUNDS    = 0xdb // bounding box dataset
+	O5M_TIMESTAMP = 0xdc // file timestamp dataset
+	O5M_HEADER    = 0xe0 // header dataset
+	O5M_SYNC      = 0xee // sync dataset
+	O5M_JUMP      = 0xef // jump dataset
+	O5M_EOF       = 0xfe // end of file
+	O5M_RESET     = 0xff // reset of delta counters and string table
+)
+
+// O5M_MAX_DATASET_SIZE is the maximum size of a single o5m dataset in bytes.
+const O5M_MAX_DATASET_SIZE = 64 * 1024 * 1024
+
+// o5mMemberTypes maps the o5m relation member type characters to OSM member types.
+var o5mMemberTypes = map[byte]string{
+	'0': "node",
+	'1': "way",
+	'2': "relation",
+}
+
+// O5MDecoder decodes OSM elements from an o5m (.o5m) or o5c (.o5c) stream.
+// Coordinates, ids, timestamps, changesets, and member references are delta coded
+// and strings are resolved through the stream's string reference table.
+//	- https://wiki.openstreetmap.org/wiki/O5m
+type O5MDecoder struct {
+	reader    *bufio.Reader
+	Change    bool       // true if the stream is an o5c change file, available after the first call to Decode
+	Bounds    *Bounds    // the bounding box of the stream, if any
+	Timestamp *time.Time // the file timestamp of the stream, if any
+	deleted   bool       // true if the last decoded element was a deletion
+	strings   *o5mStringTable
+	id        int64
+	longitude int64
+	latitude  int64
+	timestamp int64
+	changeset int64
+	refs      [3]int64 // delta counters for node, way, and relation references
+}
+
+// NewO5MDecoder returns a new O5MDecoder reading from r.
+func NewO5MDecoder(r io.Reader) *O5MDecoder {
+	d := &O5MDecoder{
+		reader:  bufio.NewReader(r),
+		strings: newO5MStringTable(),
+	}
+	d.strings.index = nil
+	return d
+}
+
+// reset clears the delta counters and the string table.
+func (d *O5MDecoder) reset() {
+	d.id = 0
+	d.longitude = 0
+	d.latitude = 0
+	d.timestamp = 0
+	d.changeset = 0
+	d.refs = [3]int64{0, 0, 0}
+	d.strings.Reset()
+	d.strings.index = nil
+}
+
+// Deleted returns true if the element most recently returned by Decode is a deletion.
+// Only o5c change files contain deletions.  A deleted element only has its id and version information set.
+func (d *O5MDecoder) Deleted() bool {
+	return d.deleted
+}
+
+// Decode returns the next element in the stream as a *Node, *Way, or *Relation.
+// The returned element's Tags, UserId, and UserName are set from the stream.
+// Returns io.EOF when there are no more elements.
+func (d *O5MDecoder) Decode() (interface{}, error) {
+	for {
+		datasetType, err := d.reader.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			return nil, errors.Wrap(err, "Error reading o5m dataset type")
+		}
+
+		switch datasetType {
+		case O5M_RESET:
+			d.reset()
+			continue
+		case O5M_EOF:
+			return nil, io.EOF
+		}
+
+		if datasetType >= 0xf0 {
+			// datasets from 0xf0 to 0xff do not have a length
+			continue
+		}
+
+		data, err := d.readDataset()
+		if err != nil {
+			return nil, err
+		}
+
+		switch datasetType {
+		case O5M_NODE:
+			return d.decodeNode(data)
+		case O5M_WAY:
+			return d.decodeWay(data)
+		case O5M_RELATION:
+			return d.decodeRelation(data)
+		case O5M_HEADER:
+			switch string(data) {
+			case "o5m2":
+				d.Change = false
+			case "o5c2":
+				d.Change = true
+			default:
+				return nil, errors.New("Unknown o5m header " + strconv.Quote(string(data)) + ".")
+			}
+		case O5M_BOUNDS:
+			r := newProtoReader(data)
+			values := make([]int64, 4)
+			for i := range values {
+				values[i], err = r.ReadSint64()
+				if err != nil {
+					return nil, errors.Wrap(err, "Error decoding o5m bounding box")
+				}
+			}
+			d.Bounds = &Bounds{
+				MinimumLongitude: float64(values[0]) / 1e7,
+				MinimumLatitude:  float64(values[1]) / 1e7,
+				MaximumLongitude: float64(values[2]) / 1e7,
+				MaximumLatitude:  float64(values[3]) / 1e7,
+			}
+		case O5M_TIMESTAMP:
+			x, err := newProtoReader(data).ReadSint64()
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding o5m file timestamp")
+			}
+			t := time.Unix(x, 0).UTC()
+			d.Timestamp = &t
+		}
+	}
+}
+
+// readDataset reads the length and the contents of the dataset.
+func (d *O5MDecoder) readDataset() ([]byte, error) {
+	length, err := readO5MUvarint(d.reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading o5m dataset length")
+	}
+	if length > O5M_MAX_DATASET_SIZE {
+		return nil, errors.New("o5m dataset size " + strconv.FormatUint(length, 10) + " is larger than the maximum.")
+	}
+	data := make([]byte, length)
+	_, err = io.ReadFull(d.reader, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error reading o5m dataset")
+	}
+	return data, nil
+}
+
+// readString reads a string from the dataset or from the string table.
+func (d *O5MDecoder) readString(r *protoReader) (string, error) {
+	if r.pos >= len(r.data) {
+		return "", errors.New("Unexpected end of o5m dataset while reading string.")
+	}
+	if r.data[r.pos] != 0 {
+		ref, err := r.ReadVarint()
+		if err != nil {
+			return "", err
+		}
+		s, ok := d.strings.Get(ref)
+		if !ok {
+			return "", errors.New("Invalid o5m string reference " + strconv.FormatUint(ref, 10) + ".")
+		}
+		return s, nil
+	}
+	r.pos += 1
+	end := bytes.IndexByte(r.data[r.pos:], 0)
+	if end == -1 {
+		return "", errors.New("Unterminated o5m string.")
+	}
+	s := string(r.data[r.pos : r.pos+end])
+	r.pos += end + 1
+	d.strings.Add(s, len(s))
+	return s, nil
+}
+
+// readStringPair reads a pair of strings from the dataset or from the string table.
+func (d *O5MDecoder) readStringPair(r *protoReader) (string, string, error) {
+	if r.pos >= len(r.data) {
+		return "", "", errors.New("Unexpected end of o5m dataset while reading string pair.")
+	}
+	if r.data[r.pos] != 0 {
+		ref, err := r.ReadVarint()
+		if err != nil {
+			return "", "", err
+		}
+		s, ok := d.strings.Get(ref)
+		if !ok {
+			return "", "", errors.New("Invalid o5m string reference " + strconv.FormatUint(ref, 10) + ".")
+		}
+		i := strings.IndexByte(s, 0)
+		return s[:i], s[i+1:], nil
+	}
+	r.pos += 1
+	first := bytes.IndexByte(r.data[r.pos:], 0)
+	if first == -1 {
+		return "", "", errors.New("Unterminated o5m string pair.")
+	}
+	second := bytes.IndexByte(r.data[r.pos+first+1:], 0)
+	if second == -1 {
+		return "", "", errors.New("Unterminated o5m string pair.")
+	}
+	a := string(r.data[r.pos : r.pos+first])
+	b := string(r.data[r.pos+first+1 : r.pos+first+1+second])
+	r.pos += first + second + 2
+	d.strings.Add(a+"\x00"+b, len(a)+len(b))
+	return a, b, nil
+}
+
+// decodeElement decodes the id and version information common to all elements.
+func (d *O5MDecoder) decodeElement(r *protoReader, e *Element) error {
+	delta, err := r.ReadSint64()
+	if err != nil {
+		return errors.Wrap(err, "Error decoding o5m id")
+	}
+	d.id += delta
+	e.Id = uint64(d.id)
+
+	version, err := r.ReadVarint()
+	if err != nil {
+		return errors.Wrap(err, "Error decoding o5m version")
+	}
+	e.Version = uint16(version)
+	if version == 0 {
+		return nil
+	}
+
+	delta, err = r.ReadSint64()
+	if err != nil {
+		return errors.Wrap(err, "Error decoding o5m timestamp")
+	}
+	d.timestamp += delta
+	if d.timestamp == 0 {
+		return nil
+	}
+	t := time.Unix(d.timestamp, 0).UTC()
+	e.Timestamp = &t
+
+	delta, err = r.ReadSint64()
+	if err != nil {
+		return errors.Wrap(err, "Error decoding o5m changeset")
+	}
+	d.changeset += delta
+	e.Changeset = uint64(d.changeset)
+
+	uid, user, err := d.readStringPair(r)
+	if err != nil {
+		return errors.Wrap(err, "Error decoding o5m author")
+	}
+	if len(uid) > 0 {
+		x, err := newProtoReader([]byte(uid)).ReadVarint()
+		if err != nil {
+			return errors.Wrap(err, "Error decoding o5m uid")
+		}
+		e.UserId = x
+	}
+	e.UserName = user
+
+	return nil
+}
+
+// decodeTags decodes the tags at the end of a dataset.
+func (d *O5MDecoder) decodeTags(r *protoReader) ([]Tag, error) {
+	tags := make([]Tag, 0)
+	for r.More() {
+		k, v, err := d.readStringPair(r)
+		if err != nil {
+			return tags, errors.Wrap(err, "Error decoding o5m tag")
+		}
+		tags = append(tags, Tag{Key: k, Value: v})
+	}
+	return tags, nil
+}
+
+func (d *O5MDecoder) decodeNode(data []byte) (interface{}, error) {
+	r := newProtoReader(data)
+	n := NewNode()
+	err := d.decodeElement(r, &n.Element)
+	if err != nil {
+		return nil, err
+	}
+	d.deleted = !r.More()
+	if d.deleted {
+		return n, nil
+	}
+
+	delta, err := r.ReadSint64()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error decoding o5m longitude")
+	}
+	d.longitude += delta
+	delta, err = r.ReadSint64()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error decoding o5m latitude")
+	}
+	d.latitude += delta
+	n.Longitude = float64(d.longitude) / 1e7
+	n.Latitude = float64(d.latitude) / 1e7
+
+	n.Tags, err = d.decodeTags(r)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (d *O5MDecoder) decodeWay(data []byte) (interface{}, error) {
+	r := newProtoReader(data)
+	w := NewWay()
+	err := d.decodeElement(r, &w.Element)
+	if err != nil {
+		return nil, err
+	}
+	d.deleted = !r.More()
+	if d.deleted {
+		return w, nil
+	}
+
+	refs, err := r.ReadBytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error decoding o5m way references")
+	}
+	w.NodeReferences = make([]NodeReference, 0, len(refs)/2)
+	rr := newProtoReader(refs)
+	for rr.More() {
+		delta, err := rr.ReadSint64()
+		if err != nil {
+			return nil, errors.Wrap(err, "Error decoding o5m way reference")
+		}
+		d.refs[0] += delta
+		w.NodeReferences = append(w.NodeReferences, NodeReference{Reference: uint64(d.refs[0])})
+	}
+
+	w.Tags, err = d.decodeTags(r)
+	if err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (d *O5MDecoder) decodeRelation(data []byte) (interface{}, error) {
+	r := newProtoReader(data)
+	rel := &Relation{TaggedElement: TaggedElement{Element: Element{}}}
+	err := d.decodeElement(r, &rel.Element)
+	if err != nil {
+		return nil, err
+	}
+	d.deleted = !r.More()
+	if d.deleted {
+		return rel, nil
+	}
+
+	refs, err := r.ReadBytes()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error decoding o5m relation members")
+	}
+	rel.Members = make([]RelationMember, 0)
+	rr := newProtoReader(refs)
+	for rr.More() {
+		delta, err := rr.ReadSint64()
+		if err != nil {
+			return nil, errors.Wrap(err, "Error decoding o5m relation member reference")
+		}
+		typeRole, err := d.readString(rr)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error decoding o5m relation member role")
+		}
+		if len(typeRole) == 0 {
+			return nil, errors.New("Missing o5m relation member type.")
+		}
+		memberType, ok := o5mMemberTypes[typeRole[0]]
+		if !ok {
+			return nil, errors.New("Unknown o5m relation member type " + strconv.Quote(typeRole[:1]) + ".")
+		}
+		i := int(typeRole[0] - '0')
+		d.refs[i] += delta
+		rel.Members = append(rel.Members, RelationMember{
+			Type:      memberType,
+			Reference: uint64(d.refs[i]),
+			Role:      typeRole[1:],
+		})
+	}
+
+	rel.Tags, err = d.decodeTags(r)
+	if err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// readO5MUvarint reads an unsigned varint from the reader.
+func readO5MUvarint(r io.ByteReader) (uint64, error) {
+	x := uint64(0)
+	for shift, i := uint(0), 0; i < protoMaxVarintLn; shift, i = shift+7, i+1 {
+		b, err := r.ReadByte()
+		if err != nil {
+			if err == io.EOF {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		x |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return x, nil
+		}
+	}
+	return 0, errors.New("Varint is too long.")
+}
@@ -0,0 +1,319 @@
+package osm
+
+import (
+	"io"
+	"reflect"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// O5MEncoder encodes OSM elements to an o5m (.o5m) or o5c (.o5c) stream.
+// Tags are resolved through the planet's TagsCache and the output's drop flags and keys are applied.
+// The o5m format only stores metadata for elements with a version, so DropVersion drops all metadata.
+//	- https://wiki.openstreetmap.org/wiki/O5m
+type O5MEncoder struct {
+	writer    io.Writer
+	planet    *Planet
+	output    *Output
+	change    bool // write an o5c change file rather than an o5m file
+	strings   *o5mStringTable
+	dataset   byte // type of the last dataset written
+	id        int64
+	longitude int64
+	latitude  int64
+	timestamp int64
+	changeset int64
+	refs      [3]int64 // delta counters for node, way, and relation references
+	buffer    *protoWriter
+}
+
+// NewO5MEncoder returns a new O5MEncoder writing to w.  If change is true, then writes an o5c change file.
+func NewO5MEncoder(w io.Writer, planet *Planet, output *Output, change bool) *O5MEncoder {
+	return &O5MEncoder{
+		writer:  w,
+		planet:  planet,
+		output:  output,
+		change:  change,
+		strings: newO5MStringTable(),
+		buffer:  newProtoWriter(1024),
+	}
+}
+
+// reset writes a reset byte and clears the delta counters and the string table.
+func (e *O5MEncoder) reset() error {
+	e.id = 0
+	e.longitude = 0
+	e.latitude = 0
+	e.timestamp = 0
+	e.changeset = 0
+	e.refs = [3]int64{0, 0, 0}
+	e.strings.Reset()
+	e.dataset = O5M_RESET
+	_, err := e.writer.Write([]byte{O5M_RESET})
+	if err != nil {
+		return errors.Wrap(err, "Error writing o5m reset")
+	}
+	return nil
+}
+
+// writeDataset writes the dataset type, length, and data to the underlying writer.
+func (e *O5MEncoder) writeDataset(datasetType byte, data []byte) error {
+	header := newProtoWriter(12)
+	header.data = append(header.data, datasetType)
+	header.appendVarint(uint64(len(data)))
+	for _, b := range [][]byte{header.Bytes(), data} {
+		_, err := e.writer.Write(b)
+		if err != nil {
+			return errors.Wrap(err, "Error writing o5m dataset")
+		}
+	}
+	e.dataset = datasetType
+	return nil
+}
+
+// begin starts a dataset of the given element type, writing a reset if the type changed.
+func (e *O5MEncoder) begin(datasetType byte) error {
+	if e.dataset != datasetType {
+		err := e.reset()
+		if err != nil {
+			return err
+		}
+	}
+	e.buffer.Reset()
+	return nil
+}
+
+// writeString writes the string, or its reference if it is in the string table.
+// String pairs are written as the two strings joined by a null byte.
+func (e *O5MEncoder) writeString(w *protoWriter, s string, length int) {
+	ref := e.strings.Reference(s)
+	if ref > 0 {
+		w.appendVarint(ref)
+		return
+	}
+	w.data = append(w.data, 0)
+	w.data = append(w.data, s...)
+	w.data = append(w.data, 0)
+	e.strings.Add(s, length)
+}
+
+// writeStringPair writes the pair of strings, or its reference if it is in the string table.
+func (e *O5MEncoder) writeStringPair(w *protoWriter, a string, b string) {
+	e.writeString(w, a+"\x00"+b, len(a)+len(b))
+}
+
+// writeElement writes the id and version information common to all elements.
+func (e *O5MEncoder) writeElement(w *protoWriter, el *Element) {
+	w.appendSint64(el.SignedId() - e.id)
+	e.id = el.SignedId()
+
+	if e.output.DropVersion || el.Version == 0 {
+		w.appendVarint(0)
+		return
+	}
+	w.appendVarint(uint64(el.Version))
+
+	timestamp := int64(0)
+	if !e.output.DropTimestamp && el.Timestamp != nil {
+		timestamp = el.Timestamp.Unix()
+	}
+	w.appendSint64(timestamp - e.timestamp)
+	e.timestamp = timestamp
+	if timestamp == 0 {
+		return
+	}
+
+	changeset := int64(0)
+	if !e.output.DropChangeset {
+		changeset = int64(el.Changeset)
+	}
+	w.appendSint64(changeset - e.changeset)
+	e.changeset = changeset
+
+	uid := ""
+	if !e.output.DropUserId && el.UserId != 0 {
+		x := newProtoWriter(5)
+		x.appendVarint(el.UserId)
+		uid = string(x.Bytes())
+	}
+	user := ""
+	if !e.output.DropUserName {
+		user = el.UserName
+		if len(user) == 0 {
+			user = e.planet.UserNames[el.UserId]
+		}
+	}
+	e.writeStringPair(w, uid, user)
+}
+
+// writeTags writes the tags of the element that are kept by the output.
+func (e *O5MEncoder) writeTags(w *protoWriter, te *TaggedElement) {
+	for _, t := range FilterTags(e.planet.Tags.Slice(te.GetTagsIndex()), e.output.KeysToKeep, e.output.KeysToDrop) {
+		e.writeStringPair(w, t.Key, t.Value)
+	}
+}
+
+// WriteHeader writes the header, file timestamp, and bounding box.  Must be called before encoding any elements.
+func (e *O5MEncoder) WriteHeader() error {
+	err := e.reset()
+	if err != nil {
+		return err
+	}
+
+	if e.change {
+		err = e.writeDataset(O5M_HEADER, []byte("o5c2"))
+	} else {
+		err = e.writeDataset(O5M_HEADER, []byte("o5m2"))
+	}
+	if err != nil {
+		return err
+	}
+
+	if !e.output.DropTimestamp && !e.planet.Timestamp.IsZero() {
+		w := newProtoWriter(8)
+		w.appendSint64(e.planet.Timestamp.Unix())
+		err := e.writeDataset(O5M_TIMESTAMP, w.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	b := e.planet.Bounds
+	if b.MinimumLongitude != 0 || b.MinimumLatitude != 0 || b.MaximumLongitude != 0 || b.MaximumLatitude != 0 {
+		w := newProtoWriter(20)
+		w.appendSint64(toFixedPoint(b.MinimumLongitude, 1e7))
+		w.appendSint64(toFixedPoint(b.MinimumLatitude, 1e7))
+		w.appendSint64(toFixedPoint(b.MaximumLongitude, 1e7))
+		w.appendSint64(toFixedPoint(b.MaximumLatitude, 1e7))
+		err := e.writeDataset(O5M_BOUNDS, w.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EncodeNode writes the node as a node dataset, or as a deletion if the node is not visible.
+func (e *O5MEncoder) EncodeNode(n *Node) error {
+	if !n.IsVisible() {
+		return e.EncodeDelete(n)
+	}
+
+	err := e.begin(O5M_NODE)
+	if err != nil {
+		return err
+	}
+
+	e.writeElement(e.buffer, &n.Element)
+	longitude := toFixedPoint(n.Longitude, 1e7)
+	latitude := toFixedPoint(n.Latitude, 1e7)
+	e.buffer.appendSint64(longitude - e.longitude)
+	e.buffer.appendSint64(latitude - e.latitude)
+	e.longitude = longitude
+	e.latitude = latitude
+	e.writeTags(e.buffer, &n.TaggedElement)
+
+	return e.writeDataset(O5M_NODE, e.buffer.Bytes())
+}
+
+// EncodeWay writes the way as a way dataset, or as a deletion if the way is not visible.
+func (e *O5MEncoder) EncodeWay(w *Way) error {
+	if !w.IsVisible() {
+		return e.EncodeDelete(w)
+	}
+
+	err := e.begin(O5M_WAY)
+	if err != nil {
+		return err
+	}
+
+	e.writeElement(e.buffer, &w.Element)
+	refs := newProtoWriter(len(w.NodeReferences) * 2)
+	for _, nr := range w.NodeReferences {
+		refs.appendSint64(w.SignedReference(nr.Reference) - e.refs[0])
+		e.refs[0] = w.SignedReference(nr.Reference)
+	}
+	e.buffer.appendVarint(uint64(refs.Len()))
+	e.buffer.data = append(e.buffer.data, refs.Bytes()...)
+	e.writeTags(e.buffer, &w.TaggedElement)
+
+	return e.writeDataset(O5M_WAY, e.buffer.Bytes())
+}
+
+// EncodeRelation writes the relation as a relation dataset, or as a deletion if the relation is not visible.
+func (e *O5MEncoder) EncodeRelation(r *Relation) error {
+	if !r.IsVisible() {
+		return e.EncodeDelete(r)
+	}
+
+	err := e.begin(O5M_RELATION)
+	if err != nil {
+		return err
+	}
+
+	e.writeElement(e.buffer, &r.Element)
+	members := newProtoWriter(len(r.Members) * 4)
+	for _, rm := range r.Members {
+		i := 0
+		switch rm.Type {
+		case "node":
+			i = 0
+		case "way":
+			i = 1
+		case "relation":
+			i = 2
+		default:
+			return errors.New("Unknown member type " + rm.Type + " for relation.")
+		}
+		members.appendSint64(r.SignedReference(rm.Reference) - e.refs[i])
+		e.refs[i] = r.SignedReference(rm.Reference)
+		typeRole := string('0'+byte(i)) + rm.Role
+		e.writeString(members, typeRole, len(typeRole))
+	}
+	e.buffer.appendVarint(uint64(members.Len()))
+	e.buffer.data = append(e.buffer.data, members.Bytes()...)
+	e.writeTags(e.buffer, &r.TaggedElement)
+
+	return e.writeDataset(O5M_RELATION, e.buffer.Bytes())
+}
+
+// EncodeDelete writes the deletion of the *Node, *Way, or *Relation.
+// A deletion only has the id and version information of the element, so the decoder reports it as deleted.
+func (e *O5MEncoder) EncodeDelete(element interface{}) error {
+	datasetType := byte(0)
+	var el *Element
+	switch x := element.(type) {
+	case *Node:
+		datasetType = O5M_NODE
+		el = &x.Element
+	case *Way:
+		datasetType = O5M_WAY
+		el = &x.Element
+	case *Relation:
+		datasetType = O5M_RELATION
+		el = &x.Element
+	default:
+		return errors.New("Cannot encode deletion of element of type " + reflect.TypeOf(element).String() + ".")
+	}
+
+	err := e.begin(datasetType)
+	if err != nil {
+		return err
+	}
+
+	e.writeElement(e.buffer, el)
+
+	return e.writeDataset(datasetType, e.buffer.Bytes())
+}
+
+// Close writes the end of file byte.  Does not close the underlying writer.
+func (e *O5MEncoder) Close() error {
+	_, err := e.writer.Write([]byte{O5M_EOF})
+	if err != nil {
+		return errors.Wrap(err, "Error writing o5m end of file")
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package osm
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+// decodeTestO5M decodes every element in the o5m or o5c stream and whether each one is a deletion.
+func decodeTestO5M(t *testing.T, r io.Reader) ([]interface{}, []bool) {
+	d := NewO5MDecoder(r)
+	elements := make([]interface{}, 0)
+	deleted := make([]bool, 0)
+	for {
+		element, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		elements = append(elements, element)
+		deleted = append(deleted, d.Deleted())
+	}
+	return elements, deleted
+}
+
+func TestO5MEncoderRoundTrip(t *testing.T) {
+	p := newTestPlanet(t)
+
+	buf := new(bytes.Buffer)
+	e := NewO5MEncoder(buf, p, newTestOutput(), false)
+	err := e.WriteHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range p.Nodes {
+		err = e.EncodeNode(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = e.EncodeWay(p.Ways[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = e.EncodeRelation(p.Relations[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = e.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	elements, deleted := decodeTestO5M(t, buf)
+	if len(elements) != 4 {
+		t.Fatalf("Expected 4 elements, got %d.", len(elements))
+	}
+	for i, d := range deleted {
+		if d {
+			t.Fatalf("Expected element %d not to be a deletion.", i)
+		}
+	}
+
+	for i, expected := range p.Nodes {
+		n := elements[i].(*Node)
+		if n.Id != expected.Id || n.Longitude != expected.Longitude || n.Latitude != expected.Latitude {
+			t.Fatalf("Expected node %d at %v,%v, got node %d at %v,%v.", expected.Id, expected.Longitude, expected.Latitude, n.Id, n.Longitude, n.Latitude)
+		}
+		if n.Version != 3 || n.Changeset != 100 || n.UserId != 7 || n.UserName != "mapper" || n.Timestamp == nil || !n.Timestamp.Equal(*expected.Timestamp) {
+			t.Fatalf("Expected the metadata of node %d to round trip, got %+v.", n.Id, n.Element)
+		}
+		tags := p.Tags.Slice(expected.TagsIndex)
+		if len(n.Tags) != 1 || n.Tags[0] != tags[0] {
+			t.Fatalf("Expected node %d to be tagged %v, got %v.", n.Id, tags, n.Tags)
+		}
+	}
+
+	w := elements[2].(*Way)
+	if w.Id != 10 || len(w.NodeReferences) != 2 || w.NodeReferences[0].Reference != 2 || w.NodeReferences[1].Reference != 1 {
+		t.Fatalf("Expected way 10 with nodes 2 and 1, got way %d with %v.", w.Id, w.NodeReferences)
+	}
+
+	r := elements[3].(*Relation)
+	if r.Id != 20 || len(r.Members) != 2 {
+		t.Fatalf("Expected relation 20 with 2 members, got relation %d with %v.", r.Id, r.Members)
+	}
+	for i, m := range p.Relations[0].Members {
+		if r.Members[i] != m {
+			t.Fatalf("Expected member %d to be %v, got %v.", i, m, r.Members[i])
+		}
+	}
+}
+
+func TestO5MEncoderDeletions(t *testing.T) {
+	p := newTestPlanet(t)
+
+	buf := new(bytes.Buffer)
+	e := NewO5MEncoder(buf, p, newTestOutput(), true)
+	err := e.WriteHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = e.EncodeNode(p.Nodes[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, element := range []interface{}{p.Nodes[1], p.Ways[0], p.Relations[0]} {
+		err = e.EncodeDelete(element)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = e.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	elements, deleted := decodeTestO5M(t, bytes.NewReader(buf.Bytes()))
+	if len(elements) != 4 {
+		t.Fatalf("Expected 4 elements, got %d.", len(elements))
+	}
+
+	testCases := []struct {
+		id      uint64
+		version uint16
+		deleted bool
+	}{
+		{id: 1, version: 3, deleted: false},
+		{id: 2, version: 3, deleted: true},
+		{id: 10, version: 2, deleted: true},
+		{id: 20, version: 1, deleted: true},
+	}
+	for i, tc := range testCases {
+		var el *Element
+		switch x := elements[i].(type) {
+		case *Node:
+			el = &x.Element
+		case *Way:
+			el = &x.Element
+		case *Relation:
+			el = &x.Element
+		}
+		if el.Id != tc.id || el.Version != tc.version {
+			t.Fatalf("Expected element %d version %d, got element %d version %d.", tc.id, tc.version, el.Id, el.Version)
+		}
+		if deleted[i] != tc.deleted {
+			t.Fatalf("Expected deletion of element %d to be %t, got %t.", tc.id, tc.deleted, deleted[i])
+		}
+	}
+
+	// The decoded deletions are encoded as deletions again.
+	out := new(bytes.Buffer)
+	e = NewO5MEncoder(out, p, newTestOutput(), true)
+	err = e.WriteHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, element := range elements {
+		if deleted[i] {
+			err = e.EncodeDelete(element)
+		} else {
+			err = e.EncodeNode(element.(*Node))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = e.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundtrip, roundtripDeleted := decodeTestO5M(t, out)
+	if len(roundtrip) != len(elements) {
+		t.Fatalf("Expected %d elements, got %d.", len(elements), len(roundtrip))
+	}
+	for i := range roundtrip {
+		if reflect.TypeOf(roundtrip[i]) != reflect.TypeOf(elements[i]) || roundtripDeleted[i] != deleted[i] {
+			t.Fatalf("Expected element %d to round trip as %T with deletion %t.", i, elements[i], deleted[i])
+		}
+	}
+
+	err = e.EncodeDelete("node")
+	if err == nil {
+		t.Fatal("Expected an error when deleting a string.")
+	}
+}
+
+func TestO5MEncoderHistory(t *testing.T) {
+	p := newTestPlanet(t)
+	visible := false
+	p.Ways[0].Visible = &visible
+
+	buf := new(bytes.Buffer)
+	err := MarshalElements(NewO5MEncoder(buf, p, newTestOutput(), false), p.Nodes, p.Ways, p.Relations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	elements, deleted := decodeTestO5M(t, buf)
+	expected := []bool{false, false, true, false}
+	if len(deleted) != len(expected) {
+		t.Fatalf("Expected %d elements, got %d.", len(expected), len(elements))
+	}
+	for i := range expected {
+		if deleted[i] != expected[i] {
+			t.Fatalf("Expected deletion of element %d to be %t, got %t.", i, expected[i], deleted[i])
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package osm
+
+const (
+	O5M_STRING_TABLE_SIZE     = 15000 // number of strings remembered by the o5m string table
+	O5M_STRING_TABLE_MAX_SIZE = 250   // maximum length in bytes of a string or string pair stored in the table
+)
+
+// o5mStringTable is the table of recently used strings and string pairs in an o5m stream.
+// A string is referenced by its position relative to the most recently added string, which has reference 1.
+type o5mStringTable struct {
+	values []string
+	index  map[string]int // map of string to count when it was last added, only used when encoding
+	count  int            // total number of strings added since the last reset
+}
+
+func newO5MStringTable() *o5mStringTable {
+	return &o5mStringTable{
+		values: make([]string, O5M_STRING_TABLE_SIZE),
+		index:  map[string]int{},
+		count:  0,
+	}
+}
+
+// Reset clears the table.
+func (t *o5mStringTable) Reset() {
+	t.index = map[string]int{}
+	t.count = 0
+}
+
+// Add adds the string to the table, if it is short enough to be stored.
+func (t *o5mStringTable) Add(s string, length int) {
+	if length > O5M_STRING_TABLE_MAX_SIZE {
+		return
+	}
+	t.values[t.count%O5M_STRING_TABLE_SIZE] = s
+	if t.index != nil {
+		t.index[s] = t.count
+	}
+	t.count += 1
+}
+
+// Get returns the string at the given reference.
+func (t *o5mStringTable) Get(ref uint64) (string, bool) {
+	if ref == 0 || ref > O5M_STRING_TABLE_SIZE || int(ref) > t.count {
+		return "", false
+	}
+	return t.values[(t.count-int(ref))%O5M_STRING_TABLE_SIZE], true
+}
+
+// Reference returns the reference of the string in the table, or 0 if the string is not in the table.
+func (t *o5mStringTable) Reference(s string) uint64 {
+	i, ok := t.index[s]
+	if !ok {
+		return 0
+	}
+	ref := t.count - i
+	if ref > O5M_STRING_TABLE_SIZE {
+		return 0
+	}
+	return uint64(ref)
+}
@@ -0,0 +1,156 @@
+package osm
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// OSMJSONDecoder decodes OSM elements from an OSM JSON (.osm.json) stream, as written by Overpass and the OSM API.
+// The elements are decoded one at a time from the elements array, so the whole stream is never held in memory.
+// Elements of other types, such as the areas and counts returned by Overpass, are skipped.
+//	- https://wiki.openstreetmap.org/wiki/OSM_JSON
+//	- https://wiki.openstreetmap.org/wiki/Overpass_API/Output_Formats#JSON
+type OSMJSONDecoder struct {
+	decoder   *json.Decoder
+	started   bool       // true if the start of the top-level object has been read
+	inArray   bool       // true if the decoder is inside the elements array
+	Version   string     // the version of the stream, if read before the current element
+	Generator string     // the generator of the stream, if read before the current element
+	Timestamp *time.Time // the timestamp of the stream or the timestamp_osm_base of Overpass, if read before the current element
+	Bounds    *Bounds    // the bounds of the stream, if read before the current element
+}
+
+// NewOSMJSONDecoder returns a new OSMJSONDecoder reading from r.
+func NewOSMJSONDecoder(r io.Reader) *OSMJSONDecoder {
+	return &OSMJSONDecoder{decoder: json.NewDecoder(r)}
+}
+
+// Decode returns the next element in the stream as a *Node, *Way, or *Relation.
+// The returned element's Tags, UserId, and UserName are set from the stream.
+// Returns io.EOF when there are no more elements.
+func (d *OSMJSONDecoder) Decode() (interface{}, error) {
+
+	if !d.started {
+		t, err := d.decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			return nil, errors.Wrap(err, "Error decoding OSM JSON")
+		}
+		if delim, ok := t.(json.Delim); !ok || delim != '{' {
+			return nil, errors.New("OSM JSON is not an object.")
+		}
+		d.started = true
+	}
+
+	for {
+
+		if d.inArray {
+			if d.decoder.More() {
+				oe := &osmjsonElement{}
+				err := d.decoder.Decode(oe)
+				if err != nil {
+					return nil, errors.Wrap(err, "Error decoding OSM JSON element")
+				}
+				if oe.Type != "node" && oe.Type != "way" && oe.Type != "relation" {
+					continue
+				}
+				return oe.element()
+			}
+			_, err := d.decoder.Token() // the end of the elements array
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding OSM JSON")
+			}
+			d.inArray = false
+			continue
+		}
+
+		if !d.decoder.More() {
+			return nil, io.EOF
+		}
+
+		t, err := d.decoder.Token()
+		if err != nil {
+			return nil, errors.Wrap(err, "Error decoding OSM JSON")
+		}
+		key, ok := t.(string)
+		if !ok {
+			return nil, errors.New("OSM JSON object has an invalid key.")
+		}
+
+		switch key {
+		case "elements":
+			t, err := d.decoder.Token()
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding OSM JSON")
+			}
+			if delim, ok := t.(json.Delim); !ok || delim != '[' {
+				return nil, errors.New("OSM JSON elements is not an array.")
+			}
+			d.inArray = true
+		case "bounds":
+			b := struct {
+				MinimumLongitude float64 `json:"minlon"`
+				MinimumLatitude  float64 `json:"minlat"`
+				MaximumLongitude float64 `json:"maxlon"`
+				MaximumLatitude  float64 `json:"maxlat"`
+			}{}
+			err := d.decoder.Decode(&b)
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding OSM JSON bounds")
+			}
+			d.Bounds = &Bounds{
+				MinimumLongitude: b.MinimumLongitude,
+				MinimumLatitude:  b.MinimumLatitude,
+				MaximumLongitude: b.MaximumLongitude,
+				MaximumLatitude:  b.MaximumLatitude,
+			}
+		case "osm3s":
+			osm3s := struct {
+				TimestampOSMBase string `json:"timestamp_osm_base"`
+			}{}
+			err := d.decoder.Decode(&osm3s)
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding OSM JSON osm3s")
+			}
+			if len(osm3s.TimestampOSMBase) > 0 && d.Timestamp == nil {
+				ts, err := time.Parse(time.RFC3339, osm3s.TimestampOSMBase)
+				if err != nil {
+					return nil, errors.Wrap(err, "Error parsing OSM JSON timestamp_osm_base")
+				}
+				d.Timestamp = &ts
+			}
+		default:
+			value := json.RawMessage{}
+			err := d.decoder.Decode(&value)
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding OSM JSON "+key)
+			}
+			switch key {
+			case "version":
+				d.Version = strings.Trim(string(value), "\"")
+			case "generator":
+				generator := ""
+				if json.Unmarshal(value, &generator) == nil {
+					d.Generator = generator
+				}
+			case "timestamp":
+				timestamp := ""
+				if json.Unmarshal(value, &timestamp) == nil && len(timestamp) > 0 {
+					ts, err := time.Parse(time.RFC3339, timestamp)
+					if err != nil {
+						return nil, errors.Wrap(err, "Error parsing OSM JSON timestamp")
+					}
+					d.Timestamp = &ts
+				}
+			}
+		}
+	}
+}
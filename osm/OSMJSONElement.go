@@ -0,0 +1,93 @@
+package osm
+
+import (
+	"sort"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// osmjsonMember is a member of a relation in OSM JSON.
+type osmjsonMember struct {
+	Type      string `json:"type"`
+	Reference int64  `json:"ref"`
+	Role      string `json:"role"`
+}
+
+// osmjsonElement is a node, way, or relation in the elements array of OSM JSON, as written by Overpass and the OSM API.
+// Ids are signed, since placeholder elements are written with negative ids.  See Element.SignedId.
+//	- https://wiki.openstreetmap.org/wiki/OSM_JSON
+type osmjsonElement struct {
+	Type      string            `json:"type"`
+	Id        int64             `json:"id"`
+	Latitude  *float64          `json:"lat,omitempty"`
+	Longitude *float64          `json:"lon,omitempty"`
+	Timestamp string            `json:"timestamp,omitempty"`
+	Version   uint16            `json:"version,omitempty"`
+	Changeset uint64            `json:"changeset,omitempty"`
+	UserName  string            `json:"user,omitempty"`
+	UserId    uint64            `json:"uid,omitempty"`
+	Visible   *bool             `json:"visible,omitempty"`
+	Nodes     []int64           `json:"nodes,omitempty"`
+	Members   []osmjsonMember   `json:"members,omitempty"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+// element returns the OSM JSON element as a *Node, *Way, or *Relation with its Tags, UserId, and UserName set.
+// Returns an error if the type is unknown or the timestamp cannot be parsed.
+func (oe *osmjsonElement) element() (interface{}, error) {
+
+	te := TaggedElement{
+		Element: Element{
+			Id:        uint64(oe.Id),
+			Version:   oe.Version,
+			Changeset: oe.Changeset,
+			UserId:    oe.UserId,
+			UserName:  oe.UserName,
+			Visible:   oe.Visible,
+		},
+	}
+
+	if len(oe.Timestamp) > 0 {
+		ts, err := time.Parse(time.RFC3339, oe.Timestamp)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error parsing "+oe.Type+" timestamp")
+		}
+		te.Timestamp = &ts
+	}
+
+	tags := make([]Tag, 0, len(oe.Tags))
+	for k, v := range oe.Tags {
+		tags = append(tags, Tag{Key: k, Value: v})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Key < tags[j].Key })
+	te.SetTags(tags)
+
+	switch oe.Type {
+	case "node":
+		n := &Node{TaggedElement: te}
+		if oe.Longitude != nil {
+			n.Longitude = *oe.Longitude
+		}
+		if oe.Latitude != nil {
+			n.Latitude = *oe.Latitude
+		}
+		return n, nil
+	case "way":
+		w := &Way{TaggedElement: te, NodeReferences: make([]NodeReference, 0, len(oe.Nodes))}
+		for _, ref := range oe.Nodes {
+			w.NodeReferences = append(w.NodeReferences, NodeReference{Reference: uint64(ref)})
+		}
+		return w, nil
+	case "relation":
+		r := &Relation{TaggedElement: te, Members: make([]RelationMember, 0, len(oe.Members))}
+		for _, m := range oe.Members {
+			r.Members = append(r.Members, RelationMember{Type: m.Type, Reference: uint64(m.Reference), Role: m.Role})
+		}
+		return r, nil
+	}
+
+	return nil, errors.New("Unknown OSM JSON element type " + oe.Type + ".")
+}
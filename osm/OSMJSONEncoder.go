@@ -0,0 +1,167 @@
+package osm
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// OSMJSONEncoder encodes OSM elements to an OSM JSON (.osm.json) stream, as read by Overpass and OSM API clients.
+// Tags and user names are resolved through the planet and the output's drop flags and keys are applied.
+//	- https://wiki.openstreetmap.org/wiki/OSM_JSON
+type OSMJSONEncoder struct {
+	writer io.Writer
+	planet *Planet
+	output *Output
+	count  int // number of elements written
+}
+
+// NewOSMJSONEncoder returns a new OSMJSONEncoder writing to w.  If the output is pretty, then writes each element on its own indented line.
+func NewOSMJSONEncoder(w io.Writer, planet *Planet, output *Output) *OSMJSONEncoder {
+	return &OSMJSONEncoder{
+		writer: w,
+		planet: planet,
+		output: output,
+	}
+}
+
+// WriteHeader writes the version, timestamp, and bounds and opens the elements array.  The bounds are skipped if they are not set.
+func (e *OSMJSONEncoder) WriteHeader() error {
+
+	header := "{"
+	if !e.output.DropVersion {
+		if _, err := strconv.ParseFloat(e.planet.Version, 64); err == nil {
+			header += "\"version\":" + e.planet.Version + ","
+		}
+	}
+	header += "\"generator\":\"go-osm\","
+	if !e.output.DropTimestamp && !e.planet.Timestamp.IsZero() {
+		header += "\"timestamp\":\"" + e.planet.Timestamp.Format(time.RFC3339) + "\","
+	}
+	b := e.planet.Bounds
+	if b.MinimumLongitude != 0 || b.MinimumLatitude != 0 || b.MaximumLongitude != 0 || b.MaximumLatitude != 0 {
+		header += "\"bounds\":{" +
+			"\"minlat\":" + strconv.FormatFloat(b.MinimumLatitude, 'f', 6, 64) + "," +
+			"\"minlon\":" + strconv.FormatFloat(b.MinimumLongitude, 'f', 6, 64) + "," +
+			"\"maxlat\":" + strconv.FormatFloat(b.MaximumLatitude, 'f', 6, 64) + "," +
+			"\"maxlon\":" + strconv.FormatFloat(b.MaximumLongitude, 'f', 6, 64) + "},"
+	}
+	header += "\"elements\":["
+
+	_, err := io.WriteString(e.writer, header)
+	if err != nil {
+		return errors.Wrap(err, "Error writing OSM JSON header.")
+	}
+	return nil
+}
+
+// element returns the attributes and tags of the element, with the output's drop flags and keys applied.
+func (e *OSMJSONEncoder) element(t string, te *TaggedElement) *osmjsonElement {
+	oe := &osmjsonElement{Type: t, Id: te.SignedId()}
+	if !e.output.DropVersion {
+		oe.Version = te.Version
+	}
+	if !e.output.DropTimestamp && te.Timestamp != nil {
+		oe.Timestamp = te.Timestamp.Format(time.RFC3339)
+	}
+	if !e.output.DropChangeset {
+		oe.Changeset = te.Changeset
+	}
+	if !e.output.DropUserId {
+		oe.UserId = te.UserId
+	}
+	if !e.output.DropUserName {
+		oe.UserName = e.planet.UserNames[te.UserId]
+	}
+	tags := FilterTags(e.planet.Tags.Slice(te.GetTagsIndex()), e.output.KeysToKeep, e.output.KeysToDrop)
+	if len(tags) > 0 {
+		oe.Tags = make(map[string]string, len(tags))
+		for _, tag := range tags {
+			oe.Tags[tag.Key] = tag.Value
+		}
+	}
+	return oe
+}
+
+// write writes the element to the elements array.
+func (e *OSMJSONEncoder) write(oe *osmjsonElement) error {
+
+	var b []byte
+	var err error
+	if e.output.Pretty {
+		b, err = json.MarshalIndent(oe, "  ", "  ")
+	} else {
+		b, err = json.Marshal(oe)
+	}
+	if err != nil {
+		return errors.Wrap(err, "Error marshalling "+oe.Type+" as OSM JSON.")
+	}
+
+	separator := ","
+	if e.count == 0 {
+		separator = ""
+	}
+	if e.output.Pretty {
+		separator += "\n  "
+	}
+
+	_, err = io.WriteString(e.writer, separator)
+	if err != nil {
+		return errors.Wrap(err, "Error writing OSM JSON.")
+	}
+	_, err = e.writer.Write(b)
+	if err != nil {
+		return errors.Wrap(err, "Error writing OSM JSON.")
+	}
+
+	e.count += 1
+	return nil
+}
+
+// EncodeNode writes the node to the elements array.
+func (e *OSMJSONEncoder) EncodeNode(n *Node) error {
+	oe := e.element("node", &n.TaggedElement)
+	lon := n.Longitude
+	lat := n.Latitude
+	oe.Longitude = &lon
+	oe.Latitude = &lat
+	return e.write(oe)
+}
+
+// EncodeWay writes the way to the elements array.
+func (e *OSMJSONEncoder) EncodeWay(w *Way) error {
+	oe := e.element("way", &w.TaggedElement)
+	oe.Nodes = make([]int64, 0, len(w.NodeReferences))
+	for _, nr := range w.NodeReferences {
+		oe.Nodes = append(oe.Nodes, w.SignedReference(nr.Reference))
+	}
+	return e.write(oe)
+}
+
+// EncodeRelation writes the relation to the elements array.
+func (e *OSMJSONEncoder) EncodeRelation(r *Relation) error {
+	oe := e.element("relation", &r.TaggedElement)
+	oe.Members = make([]osmjsonMember, 0, len(r.Members))
+	for _, m := range r.Members {
+		oe.Members = append(oe.Members, osmjsonMember{Type: m.Type, Reference: r.SignedReference(m.Reference), Role: m.Role})
+	}
+	return e.write(oe)
+}
+
+// Close closes the elements array and the top-level object.  Does not close the underlying writer.
+func (e *OSMJSONEncoder) Close() error {
+	footer := "]}\n"
+	if e.output.Pretty && e.count > 0 {
+		footer = "\n]}\n"
+	}
+	_, err := io.WriteString(e.writer, footer)
+	if err != nil {
+		return errors.Wrap(err, "Error writing OSM JSON.")
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package osm
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// encodeTestOSMJSON returns the planet encoded as OSM JSON and decoded as generic JSON.
+func encodeTestOSMJSON(t *testing.T, p *Planet) map[string]interface{} {
+	buf := new(bytes.Buffer)
+	e, err := NewElementEncoder(buf, p, newTestOutput(), "osmjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = MarshalElements(e, p.Nodes, p.Ways, p.Relations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := map[string]interface{}{}
+	err = json.Unmarshal(buf.Bytes(), &m)
+	if err != nil {
+		t.Fatalf("Expected valid JSON, got %s: %v", buf.String(), err)
+	}
+	return m
+}
+
+func TestOSMJSONEncoderBounds(t *testing.T) {
+	testCases := []struct {
+		name     string
+		bounds   Bounds
+		expected bool
+	}{
+		{name: "zero", bounds: Bounds{}, expected: false},
+		{name: "set", bounds: Bounds{MinimumLongitude: -77.1, MinimumLatitude: 38.8, MaximumLongitude: -76.9, MaximumLatitude: 39}, expected: true},
+		{name: "crossing the origin", bounds: Bounds{MinimumLongitude: -1, MinimumLatitude: -1, MaximumLongitude: 0, MaximumLatitude: 0}, expected: true},
+	}
+	for _, tc := range testCases {
+		p := newTestPlanet(t)
+		p.Bounds = tc.bounds
+		m := encodeTestOSMJSON(t, p)
+		b, ok := m["bounds"].(map[string]interface{})
+		if ok != tc.expected {
+			t.Fatalf("%s: expected bounds %t, got %v.", tc.name, tc.expected, m["bounds"])
+		}
+		if ok && b["minlon"] != tc.bounds.MinimumLongitude {
+			t.Fatalf("%s: expected minlon %v, got %v.", tc.name, tc.bounds.MinimumLongitude, b["minlon"])
+		}
+		if elements, ok := m["elements"].([]interface{}); !ok || len(elements) != 4 {
+			t.Fatalf("%s: expected 4 elements, got %v.", tc.name, m["elements"])
+		}
+	}
+}
+
+func TestOSMJSONEncoderPlaceholderIds(t *testing.T) {
+	p := newTestPlanet(t)
+	for _, n := range p.Nodes {
+		n.Placeholder = true
+	}
+	p.Ways[0].Placeholder = true
+
+	m := encodeTestOSMJSON(t, p)
+	elements := m["elements"].([]interface{})
+	expected := []struct {
+		id   float64
+		refs []float64
+	}{
+		{id: -1},
+		{id: -2},
+		{id: -10, refs: []float64{-2, -1}},
+		{id: 20, refs: []float64{10, 1}},
+	}
+	for i, x := range expected {
+		element := elements[i].(map[string]interface{})
+		if element["id"] != x.id {
+			t.Fatalf("Expected element %d to have id %v, got %v.", i, x.id, element["id"])
+		}
+		refs := make([]interface{}, 0)
+		if nodes, ok := element["nodes"].([]interface{}); ok {
+			refs = nodes
+		} else if members, ok := element["members"].([]interface{}); ok {
+			for _, member := range members {
+				refs = append(refs, member.(map[string]interface{})["ref"])
+			}
+		}
+		if len(refs) != len(x.refs) {
+			t.Fatalf("Expected element %d to reference %v, got %v.", i, x.refs, refs)
+		}
+		for j := range x.refs {
+			if refs[j] != x.refs[j] {
+				t.Fatalf("Expected element %d to reference %v, got %v.", i, x.refs, refs)
+			}
+		}
+	}
+}
+
+func TestOSMJSONDecoder(t *testing.T) {
+	text := `{"version":0.6,"generator":"Overpass API","osm3s":{"copyright":"ODbL"},"elements":[
+		{"type":"node","id":1,"lat":38.8977,"lon":-77.0365,"tags":{"amenity":"cafe"}},
+		{"type":"way","id":10,"nodes":[1,2],"tags":{"highway":"residential"}},
+		{"type":"relation","id":20,"members":[{"type":"way","ref":10,"role":"forward"}],"tags":{"type":"route"}}
+	]}`
+	s, err := NewScanner(strings.NewReader(text), "osmjson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]uint64, 0)
+	for s.Scan() {
+		if n := s.Node(); n != nil {
+			ids = append(ids, n.Id)
+			if n.Longitude != -77.0365 || len(n.Tags) != 1 || n.Tags[0].Value != "cafe" {
+				t.Fatalf("Expected node 1 at -77.0365 tagged amenity=cafe, got %v with %v.", n.Longitude, n.Tags)
+			}
+		} else if w := s.Way(); w != nil {
+			ids = append(ids, w.Id)
+			if len(w.NodeReferences) != 2 || w.NodeReferences[1].Reference != 2 {
+				t.Fatalf("Expected way 10 to reference nodes 1 and 2, got %v.", w.NodeReferences)
+			}
+		} else if r := s.Relation(); r != nil {
+			ids = append(ids, r.Id)
+			if len(r.Members) != 1 || r.Members[0].Reference != 10 || r.Members[0].Role != "forward" {
+				t.Fatalf("Expected relation 20 with forward way 10, got %v.", r.Members)
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[1] != 10 || ids[2] != 20 {
+		t.Fatalf("Expected elements 1, 10, and 20, got %v.", ids)
+	}
+}
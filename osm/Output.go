@@ -1,8 +1,9 @@
 package osm
 
 import (
-//"fmt"
-//"os"
+	//"fmt"
+	//"os"
+	"strings"
 )
 
 import (
@@ -20,11 +21,51 @@ import (
 	"github.com/spatialcurrent/go-dfl/dfl"
 )
 
+// DEFAULT_RELATION_TYPES is the types of relations converted into features, if an output does not list any.
+var DEFAULT_RELATION_TYPES = []string{"multipolygon", "boundary"}
+
+// DEFAULT_TILE_LAYER is the name of the layer in vector tiles, if an output does not set one.
+var DEFAULT_TILE_LAYER = "osm"
+
+// DEFAULT_TILE_EXTENT is the width and height of vector tiles in pixels, if an output does not set one.
+var DEFAULT_TILE_EXTENT = 4096
+
+// DEFAULT_CSV_COLUMNS is the columns of a csv or tsv output, if the output does not list any.
+var DEFAULT_CSV_COLUMNS = []string{"id", "type", "lon", "lat", "wkt", "other_tags"}
+
+// DEFAULT_SQL_SRID is the SRID of the geometries of sql outputs, if the output does not set one.
+var DEFAULT_SQL_SRID = 4326
+
+// DEFAULT_SQL_TAGS is the type of the tags column of sql outputs, if the output does not set one.
+var DEFAULT_SQL_TAGS = "hstore"
+
+// DEFAULT_SQL_PREFIX is the prefix of the names of the tables of sql outputs, if the output does not set one.
+var DEFAULT_SQL_PREFIX = "osm"
+
+// MAX_ZOOM is the highest zoom level of vector tiles.
+const MAX_ZOOM = 24
+
 // Output is a struct for holding all the configuration describing an output destination
 type Output struct {
 	*PlanetResource
-	WaysToNodes bool `hcl:"ways_to_nodes"` // convert ways into nodes
-	Pretty      bool `hcl:"pretty"`        // write pretty output (newlines and tabs for .osm XML)
+	Format        string      `hcl:"format"`         // format of the output: osm, pbf, o5m, o5c, osmjson, csv, tsv, parquet, or sql.  Inferred from the uri if not set.
+	WaysToNodes   bool        `hcl:"ways_to_nodes"`  // convert ways into nodes
+	Pretty        bool        `hcl:"pretty"`         // write pretty output (newlines and tabs for .osm XML)
+	RelationTypes []string    `hcl:"relation_types"` // types of relations converted into features, e.g., multipolygon, boundary, route, or route_master
+	AreaRules     AreaRules   `hcl:"area_rules"`     // rules for deciding whether closed ways are areas or lines
+	MinZoom       int         `hcl:"min_zoom"`       // minimum zoom level of vector tiles
+	MaxZoom       int         `hcl:"max_zoom"`       // maximum zoom level of vector tiles
+	Layer         string      `hcl:"layer"`          // name of the layer in vector tiles
+	TileExtent    int         `hcl:"tile_extent"`    // width and height of vector tiles in pixels
+	TileBuffer    int         `hcl:"tile_buffer"`    // pixels around each vector tile that features are clipped to
+	Simplify      float64     `hcl:"simplify"`       // tolerance in pixels for simplifying lines and polygons in vector tiles
+	Columns       []string    `hcl:"columns"`        // columns of csv and tsv outputs: id, type, lon, lat, wkt, other_tags, or a tag key
+	GDALIniUri    string      `hcl:"gdal_ini"`       // uri to a GDAL osmconf.ini file describing the layers of the output
+	GDALLayers    []string    `hcl:"gdal_layers"`    // GDAL layers written to the output: points, lines, multipolygons, multilinestrings, or other_relations
+	GDAL          *GDALConfig `hcl:"-"`              // the layers and settings of the GDAL OSM driver, loaded from gdal_ini or the defaults of GDAL
+	SQLSrid       int         `hcl:"sql_srid"`       // SRID of the geometries of sql outputs: 4326 or 3857
+	SQLTags       string      `hcl:"sql_tags"`       // type of the tags column of sql outputs: hstore or jsonb
+	SQLPrefix     string      `hcl:"sql_prefix"`     // prefix of the names of the tables of sql outputs, e.g., osm for osm_nodes, osm_ways, and osm_relations
 }
 
 func (o *Output) Init(globals map[string]interface{}, ctx map[string]interface{}, funcs *dfl.FunctionMap) error {
@@ -49,16 +90,133 @@ func (o *Output) Init(globals map[string]interface{}, ctx map[string]interface{}
 				o.Pretty = v.(bool)
 			}
 		}
+
+		if v, ok := globals["relation_types"]; ok {
+			switch v.(type) {
+			case []string:
+				o.RelationTypes = v.([]string)
+			case string:
+				o.RelationTypes = ParseSliceString(v.(string))
+			}
+		}
+	}
+
+	if len(o.Layer) == 0 {
+		o.Layer = DEFAULT_TILE_LAYER
+	}
+
+	if o.TileExtent == 0 {
+		o.TileExtent = DEFAULT_TILE_EXTENT
+	}
+
+	if o.SQLSrid == 0 {
+		o.SQLSrid = DEFAULT_SQL_SRID
+	}
+
+	if len(o.SQLTags) == 0 {
+		o.SQLTags = DEFAULT_SQL_TAGS
+	}
+
+	if len(o.SQLPrefix) == 0 {
+		o.SQLPrefix = DEFAULT_SQL_PREFIX
+	}
+
+	if o.GDAL == nil {
+		if len(o.GDALIniUri) > 0 {
+			gdal, err := LoadGDALConfig(o.GDALIniUri)
+			if err != nil {
+				return err
+			}
+			o.GDAL = gdal
+		} else if len(o.GDALLayers) > 0 {
+			o.GDAL = NewDefaultGDALConfig()
+		}
 	}
 
 	return nil
 }
 
+// IsTileset returns true if the output is written as vector tiles, i.e., the path ends in .mbtiles or is a template for a directory tree of tiles, such as tiles/{z}/{x}/{y}.pbf.
+func (o Output) IsTileset() bool {
+	return strings.HasSuffix(o.Path, ".mbtiles") || (strings.Contains(o.Path, "{z}") && strings.Contains(o.Path, "{x}") && strings.Contains(o.Path, "{y}"))
+}
+
+// IsTable returns true if the output is written as a table of features, i.e., the format is csv or tsv.
+func (o Output) IsTable() bool {
+	format := o.Format
+	if len(format) == 0 {
+		format = InferFormat(o.Uri)
+	}
+	return format == "csv" || format == "tsv"
+}
+
+// IsParquet returns true if the output is written as a Parquet file of elements, i.e., the format is parquet.
+func (o Output) IsParquet() bool {
+	format := o.Format
+	if len(format) == 0 {
+		format = InferFormat(o.Uri)
+	}
+	return format == "parquet"
+}
+
+// IsSQL returns true if the output is written as SQL for PostgreSQL and PostGIS, i.e., the format is sql.
+func (o Output) IsSQL() bool {
+	format := o.Format
+	if len(format) == 0 {
+		format = InferFormat(o.Uri)
+	}
+	return format == "sql"
+}
+
+// IsGDAL returns true if the output is written as the layers of the GDAL OSM driver, i.e., the output has a GDAL ini file or lists GDAL layers.
+func (o Output) IsGDAL() bool {
+	return o.GDAL != nil
+}
+
+// GetGDALLayers returns the GDAL layers written to the output.
+// If the output does not list any layers, then returns every layer if the uri contains {layer}, otherwise the points layer.
+func (o Output) GetGDALLayers() []string {
+	if len(o.GDALLayers) > 0 {
+		return o.GDALLayers
+	}
+	if strings.Contains(o.Uri, "{layer}") {
+		return GDAL_LAYERS
+	}
+	return []string{"points"}
+}
+
+// GetColumns returns the columns of a csv or tsv output.
+// If the output does not list any columns, then returns DEFAULT_CSV_COLUMNS.
+func (o Output) GetColumns() []string {
+	if len(o.Columns) == 0 {
+		return DEFAULT_CSV_COLUMNS
+	}
+	return o.Columns
+}
+
 // HasDrop returns true if any property of elements will be dropped in the output.
 func (o Output) HasDrop() bool {
 	return o.DropWays || o.DropRelations || o.DropVersion || o.DropChangeset || o.DropTimestamp || o.DropUserId || o.DropUserName
 }
 
+// HasRelationType returns true if relations with the given type are converted into features for the output, otherwise false.
+// If the output does not list any relation types, then uses DEFAULT_RELATION_TYPES.
+func (o Output) HasRelationType(t string) bool {
+	if len(o.RelationTypes) == 0 {
+		return stringSliceContains(DEFAULT_RELATION_TYPES, t)
+	}
+	return stringSliceContains(o.RelationTypes, t)
+}
+
+// GetAreaRules returns the rules for deciding whether closed ways are areas or lines.
+// If the output does not list any area rules, then returns DEFAULT_AREA_RULES.
+func (o Output) GetAreaRules() AreaRules {
+	if len(o.AreaRules) == 0 {
+		return DEFAULT_AREA_RULES
+	}
+	return o.AreaRules
+}
+
 // HasKeysToKeep returns true if there are keys to keep in the output, otherwise false.
 func (o Output) HasKeysToKeep() bool {
 	return len(o.KeysToKeep) > 0
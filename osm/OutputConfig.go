@@ -1,23 +1,38 @@
 package osm
 
 type OutputConfig struct {
-	Uri           string   `hcl:"uri"`            // resource URI
-	DropNodes     bool     `hcl:"drop_nodes"`     // drop nodes
-	DropWays      bool     `hcl:"drop_ways"`      // drop ways
-	DropRelations bool     `hcl:"drop_relations"` // drop relations
-	DropVersion   bool     `hcl:"drop_version"`   // drop version numbers
-	DropChangeset bool     `hcl:"drop_changeset"` // drop changeset id
-	DropTimestamp bool     `hcl:"drop_timestamp"` // drop last modified timestamp
-	DropUserId    bool     `hcl:"drop_user_id"`   // drop the id of the user that last modified an element
-	DropUserName  bool     `hcl:"drop_user_name"` // drop the name of the user that last modified an element
-	KeysToKeep    []string `hcl:"keep_keys"`      // slice of keys to keep from read elements.  This is not a filter.
-	KeysToDrop    []string `hcl:"drop_keys"`      // slice of keys to drop from read elements.  This is not a filter.
-	WaysToNodes   bool     `hcl:"ways_to_nodes"`  // convert ways into nodes
-	Filter        *Filter  `hcl:"filter"`         // filter input
-	Pretty        bool     `hcl:"pretty"`         // write pretty output (newlines and tabs for .osm XML)
+	Uri           string    `hcl:"uri"`            // resource URI
+	Format        string    `hcl:"format"`         // format of the output: osm, pbf, o5m, o5c, osmjson, csv, tsv, parquet, or sql.  Inferred from the uri if not set.
+	DropNodes     bool      `hcl:"drop_nodes"`     // drop nodes
+	DropWays      bool      `hcl:"drop_ways"`      // drop ways
+	DropRelations bool      `hcl:"drop_relations"` // drop relations
+	DropVersion   bool      `hcl:"drop_version"`   // drop version numbers
+	DropChangeset bool      `hcl:"drop_changeset"` // drop changeset id
+	DropTimestamp bool      `hcl:"drop_timestamp"` // drop last modified timestamp
+	DropUserId    bool      `hcl:"drop_user_id"`   // drop the id of the user that last modified an element
+	DropUserName  bool      `hcl:"drop_user_name"` // drop the name of the user that last modified an element
+	KeysToKeep    []string  `hcl:"keep_keys"`      // slice of keys to keep from read elements.  This is not a filter.
+	KeysToDrop    []string  `hcl:"drop_keys"`      // slice of keys to drop from read elements.  This is not a filter.
+	WaysToNodes   bool      `hcl:"ways_to_nodes"`  // convert ways into nodes
+	Filter        *Filter   `hcl:"filter"`         // filter input
+	Pretty        bool      `hcl:"pretty"`         // write pretty output (newlines and tabs for .osm XML)
+	RelationTypes []string  `hcl:"relation_types"` // types of relations converted into features, e.g., multipolygon, boundary, route, or route_master
+	AreaRules     AreaRules `hcl:"area_rules"`     // rules for deciding whether closed ways are areas or lines
+	MinZoom       int       `hcl:"min_zoom"`       // minimum zoom level of vector tiles
+	MaxZoom       int       `hcl:"max_zoom"`       // maximum zoom level of vector tiles
+	Layer         string    `hcl:"layer"`          // name of the layer in vector tiles
+	TileExtent    int       `hcl:"tile_extent"`    // width and height of vector tiles in pixels
+	TileBuffer    int       `hcl:"tile_buffer"`    // pixels around each vector tile that features are clipped to
+	Simplify      float64   `hcl:"simplify"`       // tolerance in pixels for simplifying lines and polygons in vector tiles
+	Columns       []string  `hcl:"columns"`        // columns of csv and tsv outputs: id, type, lon, lat, wkt, other_tags, or a tag key
+	GDALIniUri    string    `hcl:"gdal_ini"`       // uri to a GDAL osmconf.ini file describing the layers of the output
+	GDALLayers    []string  `hcl:"gdal_layers"`    // GDAL layers written to the output: points, lines, multipolygons, multilinestrings, or other_relations
+	SQLSrid       int       `hcl:"sql_srid"`       // SRID of the geometries of sql outputs: 4326 or 3857
+	SQLTags       string    `hcl:"sql_tags"`       // type of the tags column of sql outputs: hstore or jsonb
+	SQLPrefix     string    `hcl:"sql_prefix"`     // prefix of the names of the tables of sql outputs, e.g., osm for osm_nodes, osm_ways, and osm_relations
 }
 
-func NewOutputConfig(uri string, filter *Filter, drop_nodes, drop_ways, drop_relations, drop_version, drop_changeset, drop_timestamp, drop_uid, drop_user, ways_to_nodes, pretty bool) OutputConfig {
+func NewOutputConfig(uri string, filter *Filter, drop_nodes, drop_ways, drop_relations, drop_version, drop_changeset, drop_timestamp, drop_uid, drop_user, ways_to_nodes, pretty bool, relation_types []string) OutputConfig {
 	return OutputConfig{
 		Uri:           uri,
 		Filter:        filter,
@@ -31,5 +46,6 @@ func NewOutputConfig(uri string, filter *Filter, drop_nodes, drop_ways, drop_rel
 		DropUserName:  drop_user,
 		WaysToNodes:   ways_to_nodes,
 		Pretty:        pretty,
+		RelationTypes: relation_types,
 	}
 }
@@ -0,0 +1,71 @@
+package osm
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// OutputWriter is a buffered writer for an output.
+// Closing the OutputWriter flushes the buffer and closes any compression writer and file underneath.
+type OutputWriter struct {
+	*bufio.Writer
+	closers []io.Closer // closed in order after the buffer is flushed
+}
+
+// Close flushes the buffer and closes the underlying writers.
+func (w *OutputWriter) Close() error {
+	err := w.Flush()
+	if err != nil {
+		return errors.Wrap(err, "Error flushing writer.")
+	}
+	for _, c := range w.closers {
+		err := c.Close()
+		if err != nil {
+			return errors.Wrap(err, "Error closing writer.")
+		}
+	}
+	return nil
+}
+
+// OpenOutputWriter opens the output for writing.
+// Supports stdout, stderr, and files with the .osm, .osm.gz, .osm.pbf, .o5m, .o5c, .osc, .osc.gz, .osm.json, .osm.json.gz, .csv, .csv.gz, .tsv, .tsv.gz, .parquet, .geojson, .geojson.gz, .geojsonl, .geojsonl.gz, .graphml, .graphml.gz, .sql, and .sql.gz extensions.
+// Returns the writer, the format of the output, and an error if any.  The format is the output's Format, if set, otherwise inferred from the uri.
+func OpenOutputWriter(output *Output) (*OutputWriter, string, error) {
+
+	format := output.Format
+	if len(format) == 0 {
+		format = InferFormat(output.Uri)
+	}
+
+	if output.Uri == "stdout" {
+		return &OutputWriter{Writer: bufio.NewWriter(os.Stdout), closers: []io.Closer{}}, format, nil
+	} else if output.Uri == "stderr" {
+		return &OutputWriter{Writer: bufio.NewWriter(os.Stderr), closers: []io.Closer{}}, format, nil
+	} else if output.Scheme != "file" {
+		return nil, "", errors.New("unknown output_uri " + output.Uri)
+	}
+
+	path := output.PathExpanded
+	if !(strings.HasSuffix(path, ".osm") || strings.HasSuffix(path, ".osm.gz") || strings.HasSuffix(path, ".osm.pbf") || strings.HasSuffix(path, ".o5m") || strings.HasSuffix(path, ".o5c") || strings.HasSuffix(path, ".osc") || strings.HasSuffix(path, ".osc.gz") || InferFormat(path) == "osmjson" || InferFormat(path) == "csv" || InferFormat(path) == "tsv" || InferFormat(path) == "parquet" || InferFormat(path) == "geojson" || InferFormat(path) == "geojsonl" || InferFormat(path) == "graphml" || InferFormat(path) == "sql") {
+		return nil, "", errors.New("Invalid extension for output " + output.Uri)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, "", errors.Wrap(err, "error opening file to write to disk at "+path)
+	}
+
+	if strings.HasSuffix(path, ".gz") {
+		gw := gzip.NewWriter(f)
+		return &OutputWriter{Writer: bufio.NewWriter(gw), closers: []io.Closer{gw, f}}, format, nil
+	}
+
+	return &OutputWriter{Writer: bufio.NewWriter(f), closers: []io.Closer{f}}, format, nil
+}
@@ -0,0 +1,237 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"strconv"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	PBF_MAX_BLOB_HEADER_SIZE = 64 * 1024        // maximum size of a BlobHeader in bytes
+	PBF_MAX_BLOB_SIZE        = 32 * 1024 * 1024 // maximum size of an uncompressed Blob in bytes
+)
+
+// PBF_SUPPORTED_FEATURES is the list of required features that the PBF decoder understands.
+// See PBF_REQUIRED_FEATURES for the features written by the PBF encoder.
+var PBF_SUPPORTED_FEATURES = []string{
+	"OsmSchema-V0.6",
+	"DenseNodes",
+	"HistoricalInformation",
+}
+
+// PBFDecoder decodes OSM elements from an OSM PBF (.osm.pbf) stream.
+//	- https://wiki.openstreetmap.org/wiki/PBF_Format
+type PBFDecoder struct {
+	reader   io.Reader
+	Header   *PBFHeader    // the header block, available after the first call to Decode
+	elements []interface{} // elements decoded from the current block
+	position int           // position of the next element in elements
+}
+
+// NewPBFDecoder returns a new PBFDecoder reading from r.
+func NewPBFDecoder(r io.Reader) *PBFDecoder {
+	return &PBFDecoder{
+		reader:   r,
+		elements: make([]interface{}, 0),
+		position: 0,
+	}
+}
+
+// Decode returns the next element in the stream as a *Node, *Way, or *Relation.
+// The returned element's Tags, UserId, and UserName are set from the stream.
+// Returns io.EOF when there are no more elements.
+func (d *PBFDecoder) Decode() (interface{}, error) {
+	for d.position >= len(d.elements) {
+		blobType, data, err := d.readBlob()
+		if err != nil {
+			return nil, err
+		}
+		switch blobType {
+		case "OSMHeader":
+			h, err := UnmarshalPBFHeaderBlock(data)
+			if err != nil {
+				return nil, err
+			}
+			for _, feature := range h.RequiredFeatures {
+				if !stringSliceContains(PBF_SUPPORTED_FEATURES, feature) {
+					return nil, errors.New("The OSM PBF required feature " + feature + " is not supported.")
+				}
+			}
+			d.Header = h
+		case "OSMData":
+			if d.Header == nil {
+				return nil, errors.New("OSM PBF data block found before header block.")
+			}
+			elements, err := UnmarshalPBFPrimitiveBlock(data)
+			if err != nil {
+				return nil, err
+			}
+			d.elements = elements
+			d.position = 0
+		}
+	}
+	e := d.elements[d.position]
+	d.elements[d.position] = nil
+	d.position += 1
+	return e, nil
+}
+
+// readBlob reads the next BlobHeader and Blob from the stream.
+// Returns the blob type, the uncompressed blob data, and an error if any.
+func (d *PBFDecoder) readBlob() (string, []byte, error) {
+
+	sizeBytes := make([]byte, 4)
+	_, err := io.ReadFull(d.reader, sizeBytes)
+	if err != nil {
+		if err == io.EOF {
+			return "", nil, io.EOF
+		}
+		return "", nil, errors.Wrap(err, "Error reading OSM PBF blob header size")
+	}
+
+	headerSize := binary.BigEndian.Uint32(sizeBytes)
+	if headerSize > PBF_MAX_BLOB_HEADER_SIZE {
+		return "", nil, errors.New("OSM PBF blob header size " + strconv.Itoa(int(headerSize)) + " is larger than the maximum.")
+	}
+
+	headerBytes := make([]byte, headerSize)
+	_, err = io.ReadFull(d.reader, headerBytes)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Error reading OSM PBF blob header")
+	}
+
+	blobType := ""
+	blobSize := uint64(0)
+	r := newProtoReader(headerBytes)
+	for r.More() {
+		field, wireType, err := r.ReadKey()
+		if err != nil {
+			return "", nil, errors.Wrap(err, "Error decoding OSM PBF blob header")
+		}
+		switch field {
+		case 1:
+			b, err := r.ReadBytes()
+			if err != nil {
+				return "", nil, errors.Wrap(err, "Error decoding OSM PBF blob type")
+			}
+			blobType = string(b)
+		case 3:
+			blobSize, err = r.ReadVarint()
+			if err != nil {
+				return "", nil, errors.Wrap(err, "Error decoding OSM PBF blob size")
+			}
+		default:
+			err = r.Skip(wireType)
+			if err != nil {
+				return "", nil, errors.Wrap(err, "Error decoding OSM PBF blob header")
+			}
+		}
+	}
+
+	if blobSize > PBF_MAX_BLOB_SIZE {
+		return "", nil, errors.New("OSM PBF blob size " + strconv.FormatUint(blobSize, 10) + " is larger than the maximum.")
+	}
+
+	blobBytes := make([]byte, blobSize)
+	_, err = io.ReadFull(d.reader, blobBytes)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Error reading OSM PBF blob")
+	}
+
+	data, err := UnmarshalPBFBlob(blobBytes)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return blobType, data, nil
+}
+
+// UnmarshalPBFBlob decodes a Blob message and returns the uncompressed data.
+// Supports raw and zlib compressed blobs.  A zlib compressed blob must declare its raw size,
+// which cannot be larger than PBF_MAX_BLOB_SIZE, and must inflate to exactly that many bytes.
+func UnmarshalPBFBlob(blob []byte) ([]byte, error) {
+
+	rawSize := uint64(0)
+	var compressed []byte
+	r := newProtoReader(blob)
+	for r.More() {
+		field, wireType, err := r.ReadKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "Error decoding OSM PBF blob")
+		}
+		switch field {
+		case 1:
+			raw, err := r.ReadBytes()
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding raw OSM PBF blob")
+			}
+			if len(raw) > PBF_MAX_BLOB_SIZE {
+				return nil, errors.New("OSM PBF blob size " + strconv.Itoa(len(raw)) + " is larger than the maximum.")
+			}
+			return raw, nil
+		case 2:
+			rawSize, err = r.ReadVarint()
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding OSM PBF blob raw size")
+			}
+		case 3:
+			compressed, err = r.ReadBytes()
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding zlib OSM PBF blob")
+			}
+		case 4:
+			return nil, errors.New("LZMA compressed OSM PBF blobs are not supported.")
+		case 5:
+			return nil, errors.New("bzip2 compressed OSM PBF blobs are not supported.")
+		case 6:
+			return nil, errors.New("LZ4 compressed OSM PBF blobs are not supported.")
+		case 7:
+			return nil, errors.New("ZSTD compressed OSM PBF blobs are not supported.")
+		default:
+			err = r.Skip(wireType)
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding OSM PBF blob")
+			}
+		}
+	}
+
+	if compressed == nil {
+		return nil, errors.New("OSM PBF blob does not contain any data.")
+	}
+
+	// The raw size is checked after reading every field, since protobuf fields can be in any order.
+	if rawSize == 0 {
+		return nil, errors.New("zlib OSM PBF blob does not declare its raw size.")
+	}
+	if rawSize > PBF_MAX_BLOB_SIZE {
+		return nil, errors.New("OSM PBF blob raw size " + strconv.FormatUint(rawSize, 10) + " is larger than the maximum.")
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating zlib reader for OSM PBF blob")
+	}
+	data := make([]byte, rawSize)
+	_, err = io.ReadFull(zr, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error decompressing zlib OSM PBF blob of raw size "+strconv.FormatUint(rawSize, 10))
+	}
+	n, err := zr.Read(make([]byte, 1))
+	if n > 0 {
+		return nil, errors.New("zlib OSM PBF blob inflates to more than its raw size " + strconv.FormatUint(rawSize, 10) + ".")
+	}
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "Error decompressing zlib OSM PBF blob")
+	}
+	err = zr.Close()
+	if err != nil {
+		return nil, errors.Wrap(err, "Error closing zlib reader for OSM PBF blob")
+	}
+	return data, nil
+}
@@ -0,0 +1,213 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+)
+
+// testProto is a minimal protocol buffer message builder used to hand-build OSM PBF streams.
+type testProto []byte
+
+func (p testProto) varint(x uint64) testProto {
+	for x >= 0x80 {
+		p = append(p, byte(x)|0x80)
+		x >>= 7
+	}
+	return append(p, byte(x))
+}
+
+func (p testProto) key(field int, wireType int) testProto {
+	return p.varint(uint64(field<<3 | wireType))
+}
+
+func (p testProto) uint(field int, x uint64) testProto {
+	return p.key(field, 0).varint(x)
+}
+
+func (p testProto) sint(field int, x int64) testProto {
+	return p.uint(field, uint64((x<<1)^(x>>63)))
+}
+
+func (p testProto) bytes(field int, b []byte) testProto {
+	return append(p.key(field, 2).varint(uint64(len(b))), b...)
+}
+
+func (p testProto) packed(field int, values ...uint64) testProto {
+	b := testProto{}
+	for _, x := range values {
+		b = b.varint(x)
+	}
+	return p.bytes(field, b)
+}
+
+func (p testProto) packedSint(field int, values ...int64) testProto {
+	b := testProto{}
+	for _, x := range values {
+		b = b.varint(uint64((x << 1) ^ (x >> 63)))
+	}
+	return p.bytes(field, b)
+}
+
+// testPBFZlib returns data compressed with zlib.
+func testPBFZlib(t *testing.T, data []byte) []byte {
+	buf := new(bytes.Buffer)
+	zw := zlib.NewWriter(buf)
+	_, err := zw.Write(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = zw.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// testPBFFileBlock returns a BlobHeader and zlib compressed Blob framed as in an OSM PBF file.
+func testPBFFileBlock(t *testing.T, blobType string, data []byte) []byte {
+	blob := testProto{}.uint(2, uint64(len(data))).bytes(3, testPBFZlib(t, data))
+	header := testProto{}.bytes(1, []byte(blobType)).uint(3, uint64(len(blob)))
+	size := make([]byte, 4)
+	binary.BigEndian.PutUint32(size, uint32(len(header)))
+	return append(append(size, header...), blob...)
+}
+
+// testPBFFile returns an OSM PBF file with a bbox, 2 tagged dense nodes, a way, and a relation.
+func testPBFFile(t *testing.T) []byte {
+	bbox := testProto{}.sint(1, -1000000000).sint(2, 2000000000).sint(3, 3000000000).sint(4, -4000000000)
+	header := testProto{}.bytes(1, bbox).bytes(4, []byte("OsmSchema-V0.6")).bytes(4, []byte("DenseNodes"))
+
+	// 0 is always the empty string
+	strs := testProto{}
+	for _, s := range []string{"", "amenity", "cafe", "highway", "residential", "type", "route", "stop"} {
+		strs = strs.bytes(1, []byte(s))
+	}
+	dense := testProto{}.
+		packedSint(1, 10, 1).
+		packedSint(8, 10000000, -5000000).
+		packedSint(9, 20000000, 10000000).
+		packed(10, 1, 2, 0, 0)
+	way := testProto{}.uint(1, 20).packed(2, 3).packed(3, 4).packedSint(8, 10, 1)
+	relation := testProto{}.uint(1, 30).packed(2, 5).packed(3, 6).packed(8, 7, 0).packedSint(9, 11, 9).packed(10, 0, 1)
+	group := testProto{}.bytes(2, dense).bytes(3, way).bytes(4, relation)
+	block := testProto{}.bytes(1, strs).bytes(2, group)
+
+	return append(testPBFFileBlock(t, "OSMHeader", header), testPBFFileBlock(t, "OSMData", block)...)
+}
+
+func TestPBFDecoder(t *testing.T) {
+	d := NewPBFDecoder(bytes.NewReader(testPBFFile(t)))
+
+	elements := make([]interface{}, 0)
+	for {
+		e, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		elements = append(elements, e)
+	}
+
+	if d.Header == nil || d.Header.Bounds == nil {
+		t.Fatal("Expected a header with bounds.")
+	}
+	if b := d.Header.Bounds; b.MinimumLongitude != -1 || b.MinimumLatitude != -4 || b.MaximumLongitude != 2 || b.MaximumLatitude != 3 {
+		t.Fatalf("Expected bounds -1,-4,2,3, got %v.", b)
+	}
+	if len(elements) != 4 {
+		t.Fatalf("Expected 4 elements, got %d.", len(elements))
+	}
+
+	n, ok := elements[1].(*Node)
+	if !ok {
+		t.Fatalf("Expected a node, got %T.", elements[1])
+	}
+	if n.Id != 11 || n.Latitude != 0.5 || n.Longitude != 3 {
+		t.Fatalf("Expected node 11 at 3,0.5, got node %d at %v,%v.", n.Id, n.Longitude, n.Latitude)
+	}
+	if n0 := elements[0].(*Node); len(n0.Tags) != 1 || n0.Tags[0].Key != "amenity" || n0.Tags[0].Value != "cafe" {
+		t.Fatalf("Expected node 10 to be tagged amenity=cafe, got %v.", n0.Tags)
+	}
+	if len(n.Tags) != 0 {
+		t.Fatalf("Expected node 11 to have no tags, got %v.", n.Tags)
+	}
+
+	w, ok := elements[2].(*Way)
+	if !ok {
+		t.Fatalf("Expected a way, got %T.", elements[2])
+	}
+	if w.Id != 20 || len(w.NodeReferences) != 2 || w.NodeReferences[0].Reference != 10 || w.NodeReferences[1].Reference != 11 {
+		t.Fatalf("Expected way 20 with nodes 10 and 11, got way %d with %v.", w.Id, w.NodeReferences)
+	}
+	if len(w.Tags) != 1 || w.Tags[0].Key != "highway" || w.Tags[0].Value != "residential" {
+		t.Fatalf("Expected way 20 to be tagged highway=residential, got %v.", w.Tags)
+	}
+
+	r, ok := elements[3].(*Relation)
+	if !ok {
+		t.Fatalf("Expected a relation, got %T.", elements[3])
+	}
+	expected := []RelationMember{
+		RelationMember{Type: "node", Reference: 11, Role: "stop"},
+		RelationMember{Type: "way", Reference: 20, Role: ""},
+	}
+	if r.Id != 30 || len(r.Members) != len(expected) {
+		t.Fatalf("Expected relation 30 with %d members, got relation %d with %v.", len(expected), r.Id, r.Members)
+	}
+	for i, m := range expected {
+		if r.Members[i] != m {
+			t.Fatalf("Expected member %d to be %v, got %v.", i, m, r.Members[i])
+		}
+	}
+}
+
+func TestPBFDecoderRequiredFeature(t *testing.T) {
+	header := testProto{}.bytes(4, []byte("OsmSchema-V0.6")).bytes(4, []byte("LocationsOnWays"))
+	d := NewPBFDecoder(bytes.NewReader(testPBFFileBlock(t, "OSMHeader", header)))
+	_, err := d.Decode()
+	if err == nil || !strings.Contains(err.Error(), "LocationsOnWays") {
+		t.Fatalf("Expected an error for the unsupported required feature, got %v.", err)
+	}
+}
+
+func TestUnmarshalPBFBlob(t *testing.T) {
+	data := []byte("OpenStreetMap")
+	compressed := testPBFZlib(t, data)
+	testCases := []struct {
+		name  string
+		blob  testProto
+		error string
+	}{
+		{name: "raw", blob: testProto{}.bytes(1, data)},
+		{name: "zlib", blob: testProto{}.uint(2, uint64(len(data))).bytes(3, compressed)},
+		{name: "zlib raw size after data", blob: testProto{}.bytes(3, compressed).uint(2, uint64(len(data)))},
+		{name: "empty", blob: testProto{}, error: "does not contain any data"},
+		{name: "zlib without raw size", blob: testProto{}.bytes(3, compressed), error: "does not declare its raw size"},
+		{name: "zlib raw size too small", blob: testProto{}.uint(2, uint64(len(data)-1)).bytes(3, compressed), error: "inflates to more than its raw size"},
+		{name: "zlib raw size too large", blob: testProto{}.uint(2, uint64(len(data)+1)).bytes(3, compressed), error: "Error decompressing"},
+		{name: "zlib raw size above maximum", blob: testProto{}.uint(2, PBF_MAX_BLOB_SIZE+1).bytes(3, compressed), error: "larger than the maximum"},
+		{name: "lzma", blob: testProto{}.bytes(4, data), error: "LZMA"},
+		{name: "zstd", blob: testProto{}.bytes(7, data), error: "ZSTD"},
+	}
+	for _, tc := range testCases {
+		out, err := UnmarshalPBFBlob(tc.blob)
+		if len(tc.error) > 0 {
+			if err == nil || !strings.Contains(err.Error(), tc.error) {
+				t.Fatalf("%s: expected error containing %q, got %v.", tc.name, tc.error, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if !bytes.Equal(out, data) {
+			t.Fatalf("%s: expected %q, got %q.", tc.name, data, out)
+		}
+	}
+}
@@ -0,0 +1,428 @@
+package osm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"io"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// PBF_REQUIRED_FEATURES is the list of required features written to the header of every PBF output.
+// HistoricalInformation is only added if the output contains deleted elements.  See PBFEncoder.WriteHeader.
+var PBF_REQUIRED_FEATURES = []string{
+	"OsmSchema-V0.6",
+	"DenseNodes",
+}
+
+// PBF_MAX_ENTITIES_PER_BLOCK is the maximum number of elements written to a single PrimitiveBlock.
+const PBF_MAX_ENTITIES_PER_BLOCK = 8000
+
+// PBFEncoder encodes OSM elements to an OSM PBF (.osm.pbf) stream.
+// Nodes are written as dense nodes.  Each block is zlib compressed.
+// Tags are resolved through the planet's TagsCache and the output's drop flags and keys are applied.
+//	- https://wiki.openstreetmap.org/wiki/PBF_Format
+type PBFEncoder struct {
+	writer       io.Writer
+	planet       *Planet
+	output       *Output
+	strings      []string       // string table of the current block
+	stringsIndex map[string]int // map of string to position in the string table
+	group        string         // type of elements in the current block: dense, ways, or relations
+	count        int            // number of elements in the current block
+	ids          []int64
+	lats         []int64
+	lons         []int64
+	keysValues   []uint64
+	versions     []uint64
+	timestamps   []int64
+	changesets   []int64
+	uids         []int64
+	userSids     []int64
+	visibles     []uint64
+	history      bool         // true if the output contains history, so the visible flag of each element is written
+	elements     *protoWriter // encoded ways or relations of the current block
+}
+
+// NewPBFEncoder returns a new PBFEncoder writing to w.
+func NewPBFEncoder(w io.Writer, planet *Planet, output *Output) *PBFEncoder {
+	e := &PBFEncoder{
+		writer:   w,
+		planet:   planet,
+		output:   output,
+		elements: newProtoWriter(1024 * 1024),
+	}
+	e.reset()
+	return e
+}
+
+// reset clears the state of the current block.
+func (e *PBFEncoder) reset() {
+	e.strings = []string{""}
+	e.stringsIndex = map[string]int{"": 0}
+	e.group = ""
+	e.count = 0
+	e.ids = make([]int64, 0, PBF_MAX_ENTITIES_PER_BLOCK)
+	e.lats = make([]int64, 0, PBF_MAX_ENTITIES_PER_BLOCK)
+	e.lons = make([]int64, 0, PBF_MAX_ENTITIES_PER_BLOCK)
+	e.keysValues = make([]uint64, 0, PBF_MAX_ENTITIES_PER_BLOCK)
+	e.versions = make([]uint64, 0, PBF_MAX_ENTITIES_PER_BLOCK)
+	e.timestamps = make([]int64, 0, PBF_MAX_ENTITIES_PER_BLOCK)
+	e.changesets = make([]int64, 0, PBF_MAX_ENTITIES_PER_BLOCK)
+	e.uids = make([]int64, 0, PBF_MAX_ENTITIES_PER_BLOCK)
+	e.userSids = make([]int64, 0, PBF_MAX_ENTITIES_PER_BLOCK)
+	e.visibles = make([]uint64, 0, PBF_MAX_ENTITIES_PER_BLOCK)
+	e.elements.Reset()
+}
+
+// stringId returns the position of s in the block's string table, adding it if needed.
+func (e *PBFEncoder) stringId(s string) uint64 {
+	if i, ok := e.stringsIndex[s]; ok {
+		return uint64(i)
+	}
+	e.strings = append(e.strings, s)
+	e.stringsIndex[s] = len(e.strings) - 1
+	return uint64(len(e.strings) - 1)
+}
+
+// tags returns the tags of the element that are kept by the output.
+func (e *PBFEncoder) tags(te *TaggedElement) []Tag {
+	return FilterTags(e.planet.Tags.Slice(te.GetTagsIndex()), e.output.KeysToKeep, e.output.KeysToDrop)
+}
+
+// userName returns the name of the user that last modified the element.
+func (e *PBFEncoder) userName(el *Element) string {
+	if len(el.UserName) > 0 {
+		return el.UserName
+	}
+	return e.planet.UserNames[el.UserId]
+}
+
+// hasInfo returns true if any metadata is written for each element.
+func (e *PBFEncoder) hasInfo() bool {
+	return e.history || !(e.output.DropVersion && e.output.DropTimestamp && e.output.DropChangeset && e.output.DropUserId && e.output.DropUserName)
+}
+
+// hasHistory returns true if any node, way, or relation in the planet is deleted, i.e., the planet contains history.
+func (e *PBFEncoder) hasHistory() bool {
+	for _, n := range e.planet.Nodes {
+		if !n.IsVisible() {
+			return true
+		}
+	}
+	for _, w := range e.planet.Ways {
+		if !w.IsVisible() {
+			return true
+		}
+	}
+	for _, r := range e.planet.Relations {
+		if !r.IsVisible() {
+			return true
+		}
+	}
+	return false
+}
+
+// begin starts a new group of the given type, flushing the current block if needed.
+func (e *PBFEncoder) begin(group string) error {
+	if (e.group != group && e.count > 0) || e.count >= PBF_MAX_ENTITIES_PER_BLOCK {
+		err := e.Flush()
+		if err != nil {
+			return err
+		}
+	}
+	e.group = group
+	return nil
+}
+
+// writeInfo writes an Info message for a way or relation.
+func (e *PBFEncoder) writeInfo(w *protoWriter, el *Element) {
+	if !e.hasInfo() {
+		return
+	}
+	info := newProtoWriter(32)
+	if !e.output.DropVersion {
+		info.WriteVarint(1, uint64(el.Version))
+	}
+	if !e.output.DropTimestamp && el.Timestamp != nil {
+		info.WriteVarint(2, uint64(el.Timestamp.Unix()))
+	}
+	if !e.output.DropChangeset {
+		info.WriteVarint(3, el.Changeset)
+	}
+	if !e.output.DropUserId {
+		info.WriteVarint(4, el.UserId)
+	}
+	if !e.output.DropUserName {
+		info.WriteVarint(5, e.stringId(e.userName(el)))
+	}
+	if e.history {
+		if el.IsVisible() {
+			info.WriteVarint(6, 1)
+		} else {
+			info.WriteVarint(6, 0)
+		}
+	}
+	w.WriteBytes(4, info.Bytes())
+}
+
+// writeTags writes the keys and values of the tags as packed string ids.
+func (e *PBFEncoder) writeTags(w *protoWriter, tags []Tag) {
+	keys := make([]uint64, len(tags))
+	values := make([]uint64, len(tags))
+	for i, t := range tags {
+		keys[i] = e.stringId(t.Key)
+		values[i] = e.stringId(t.Value)
+	}
+	w.WritePackedVarints(2, keys)
+	w.WritePackedVarints(3, values)
+}
+
+// WriteHeader writes the OSMHeader blob.  Must be called before encoding any elements.
+// The required features are PBF_REQUIRED_FEATURES, and HistoricalInformation if the planet contains deleted elements.
+// Files with HistoricalInformation also have the visible flag of each element.
+func (e *PBFEncoder) WriteHeader() error {
+	h := newProtoWriter(256)
+
+	b := e.planet.Bounds
+	if b.MinimumLongitude != 0 || b.MinimumLatitude != 0 || b.MaximumLongitude != 0 || b.MaximumLatitude != 0 {
+		bbox := newProtoWriter(48)
+		bbox.WriteSint64(1, toFixedPoint(b.MinimumLongitude, 1e9))
+		bbox.WriteSint64(2, toFixedPoint(b.MaximumLongitude, 1e9))
+		bbox.WriteSint64(3, toFixedPoint(b.MaximumLatitude, 1e9))
+		bbox.WriteSint64(4, toFixedPoint(b.MinimumLatitude, 1e9))
+		h.WriteBytes(1, bbox.Bytes())
+	}
+
+	for _, feature := range PBF_REQUIRED_FEATURES {
+		h.WriteString(4, feature)
+	}
+	e.history = e.hasHistory()
+	if e.history {
+		h.WriteString(4, "HistoricalInformation")
+	}
+
+	h.WriteString(16, "go-osm")
+
+	if !e.output.DropTimestamp && !e.planet.Timestamp.IsZero() {
+		h.WriteVarint(32, uint64(e.planet.Timestamp.Unix()))
+	}
+
+	return e.writeBlob("OSMHeader", h.Bytes())
+}
+
+// EncodeNode adds the node to the current block of dense nodes.
+func (e *PBFEncoder) EncodeNode(n *Node) error {
+	err := e.begin("dense")
+	if err != nil {
+		return err
+	}
+
+	e.ids = append(e.ids, n.SignedId())
+	e.lats = append(e.lats, toFixedPoint(n.Latitude, 1e7))
+	e.lons = append(e.lons, toFixedPoint(n.Longitude, 1e7))
+
+	for _, t := range e.tags(&n.TaggedElement) {
+		e.keysValues = append(e.keysValues, e.stringId(t.Key), e.stringId(t.Value))
+	}
+	e.keysValues = append(e.keysValues, 0)
+
+	e.versions = append(e.versions, uint64(n.Version))
+	if n.Timestamp != nil {
+		e.timestamps = append(e.timestamps, n.Timestamp.Unix())
+	} else {
+		e.timestamps = append(e.timestamps, 0)
+	}
+	e.changesets = append(e.changesets, int64(n.Changeset))
+	e.uids = append(e.uids, int64(n.UserId))
+	if !e.output.DropUserName {
+		e.userSids = append(e.userSids, int64(e.stringId(e.userName(&n.Element))))
+	} else {
+		e.userSids = append(e.userSids, 0)
+	}
+	if n.IsVisible() {
+		e.visibles = append(e.visibles, 1)
+	} else {
+		e.visibles = append(e.visibles, 0)
+	}
+
+	e.count += 1
+	return nil
+}
+
+// EncodeWay adds the way to the current block of ways.
+func (e *PBFEncoder) EncodeWay(w *Way) error {
+	err := e.begin("ways")
+	if err != nil {
+		return err
+	}
+
+	m := newProtoWriter(64 + len(w.NodeReferences)*4)
+	m.WriteVarint(1, uint64(w.SignedId()))
+	e.writeTags(m, e.tags(&w.TaggedElement))
+	e.writeInfo(m, &w.Element)
+	refs := make([]int64, len(w.NodeReferences))
+	last := int64(0)
+	for i, nr := range w.NodeReferences {
+		refs[i] = w.SignedReference(nr.Reference) - last
+		last = w.SignedReference(nr.Reference)
+	}
+	m.WritePackedSint64s(8, refs)
+
+	e.elements.WriteBytes(3, m.Bytes())
+	e.count += 1
+	return nil
+}
+
+// EncodeRelation adds the relation to the current block of relations.
+func (e *PBFEncoder) EncodeRelation(r *Relation) error {
+	err := e.begin("relations")
+	if err != nil {
+		return err
+	}
+
+	m := newProtoWriter(64 + len(r.Members)*8)
+	m.WriteVarint(1, uint64(r.SignedId()))
+	e.writeTags(m, e.tags(&r.TaggedElement))
+	e.writeInfo(m, &r.Element)
+	roles := make([]uint64, len(r.Members))
+	memids := make([]int64, len(r.Members))
+	types := make([]uint64, len(r.Members))
+	last := int64(0)
+	for i, rm := range r.Members {
+		roles[i] = e.stringId(rm.Role)
+		memids[i] = r.SignedReference(rm.Reference) - last
+		last = r.SignedReference(rm.Reference)
+		switch rm.Type {
+		case "node":
+			types[i] = 0
+		case "way":
+			types[i] = 1
+		case "relation":
+			types[i] = 2
+		default:
+			return errors.New("Unknown member type " + rm.Type + " for relation.")
+		}
+	}
+	m.WritePackedVarints(8, roles)
+	m.WritePackedSint64s(9, memids)
+	m.WritePackedVarints(10, types)
+
+	e.elements.WriteBytes(4, m.Bytes())
+	e.count += 1
+	return nil
+}
+
+// Flush writes the current block to the underlying writer, if it contains any elements.
+func (e *PBFEncoder) Flush() error {
+	if e.count == 0 {
+		return nil
+	}
+
+	group := newProtoWriter(e.elements.Len() + len(e.ids)*16)
+	switch e.group {
+	case "dense":
+		dense := newProtoWriter(len(e.ids) * 16)
+		dense.WritePackedSint64s(1, deltaEncode(e.ids))
+		if e.hasInfo() {
+			info := newProtoWriter(len(e.ids) * 8)
+			if !e.output.DropVersion {
+				info.WritePackedVarints(1, e.versions)
+			}
+			if !e.output.DropTimestamp {
+				info.WritePackedSint64s(2, deltaEncode(e.timestamps))
+			}
+			if !e.output.DropChangeset {
+				info.WritePackedSint64s(3, deltaEncode(e.changesets))
+			}
+			if !e.output.DropUserId {
+				info.WritePackedSint64s(4, deltaEncode(e.uids))
+			}
+			if !e.output.DropUserName {
+				info.WritePackedSint64s(5, deltaEncode(e.userSids))
+			}
+			if e.history {
+				info.WritePackedVarints(6, e.visibles)
+			}
+			dense.WriteBytes(5, info.Bytes())
+		}
+		dense.WritePackedSint64s(8, deltaEncode(e.lats))
+		dense.WritePackedSint64s(9, deltaEncode(e.lons))
+		if len(e.keysValues) > len(e.ids) {
+			dense.WritePackedVarints(10, e.keysValues)
+		}
+		group.WriteBytes(2, dense.Bytes())
+	default:
+		group.data = append(group.data, e.elements.Bytes()...)
+	}
+
+	stringTable := newProtoWriter(len(e.strings) * 8)
+	for _, s := range e.strings {
+		stringTable.WriteString(1, s)
+	}
+
+	block := newProtoWriter(stringTable.Len() + group.Len() + 16)
+	block.WriteBytes(1, stringTable.Bytes())
+	block.WriteBytes(2, group.Bytes())
+
+	err := e.writeBlob("OSMData", block.Bytes())
+	if err != nil {
+		return err
+	}
+
+	e.reset()
+	return nil
+}
+
+// Close flushes the current block.  Does not close the underlying writer.
+func (e *PBFEncoder) Close() error {
+	return e.Flush()
+}
+
+// writeBlob compresses the data and writes the BlobHeader and Blob to the underlying writer.
+func (e *PBFEncoder) writeBlob(blobType string, data []byte) error {
+	compressed := &bytes.Buffer{}
+	zw := zlib.NewWriter(compressed)
+	_, err := zw.Write(data)
+	if err != nil {
+		return errors.Wrap(err, "Error compressing OSM PBF blob")
+	}
+	err = zw.Close()
+	if err != nil {
+		return errors.Wrap(err, "Error compressing OSM PBF blob")
+	}
+
+	blob := newProtoWriter(compressed.Len() + 16)
+	blob.WriteVarint(2, uint64(len(data)))
+	blob.WriteBytes(3, compressed.Bytes())
+
+	header := newProtoWriter(32)
+	header.WriteString(1, blobType)
+	header.WriteVarint(3, uint64(blob.Len()))
+
+	size := make([]byte, 4)
+	binary.BigEndian.PutUint32(size, uint32(header.Len()))
+
+	for _, b := range [][]byte{size, header.Bytes(), blob.Bytes()} {
+		_, err := e.writer.Write(b)
+		if err != nil {
+			return errors.Wrap(err, "Error writing OSM PBF blob")
+		}
+	}
+
+	return nil
+}
+
+// deltaEncode returns the differences between consecutive values.
+func deltaEncode(values []int64) []int64 {
+	deltas := make([]int64, len(values))
+	last := int64(0)
+	for i, x := range values {
+		deltas[i] = x - last
+		last = x
+	}
+	return deltas
+}
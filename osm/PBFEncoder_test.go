@@ -0,0 +1,157 @@
+package osm
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+// newTestOutput returns an output that keeps every tag and attribute.
+func newTestOutput() *Output {
+	return &Output{PlanetResource: &PlanetResource{FilteredResource: &FilteredResource{Resource: &Resource{}}}}
+}
+
+// newTestPlanet returns a planet with 2 tagged nodes, a way between them, and a route relation.
+func newTestPlanet(t *testing.T) *Planet {
+	p := NewPlanet()
+	ts := time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, x := range []struct {
+		id  uint64
+		lon float64
+		lat float64
+		tag Tag
+	}{
+		{id: 1, lon: -77.0365, lat: 38.8977, tag: Tag{Key: "amenity", Value: "cafe"}},
+		{id: 2, lon: 2.2945, lat: -48.8584, tag: Tag{Key: "name", Value: "Café \"Zoë\""}},
+	} {
+		n := &Node{Longitude: x.lon, Latitude: x.lat}
+		n.Id = x.id
+		n.Version = 3
+		n.Timestamp = &ts
+		n.Changeset = 100
+		n.UserId = 7
+		n.UserName = "mapper"
+		n.TagsIndex = p.AddTags([]Tag{x.tag})
+		err := p.AddNode(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := NewWay()
+	w.Id = 10
+	w.Version = 2
+	w.Timestamp = &ts
+	w.NodeReferences = []NodeReference{NodeReference{Reference: 2}, NodeReference{Reference: 1}}
+	w.TagsIndex = p.AddTags([]Tag{Tag{Key: "highway", Value: "residential"}})
+	err := p.AddWay(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRelation()
+	r.Id = 20
+	r.Version = 1
+	r.Members = []RelationMember{
+		RelationMember{Type: "way", Reference: 10, Role: "forward"},
+		RelationMember{Type: "node", Reference: 1, Role: "stop"},
+	}
+	r.TagsIndex = p.AddTags([]Tag{Tag{Key: "type", Value: "route"}})
+	err = p.AddRelation(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return p
+}
+
+func TestPBFEncoderRoundTrip(t *testing.T) {
+	p := newTestPlanet(t)
+
+	buf := new(bytes.Buffer)
+	e := NewPBFEncoder(buf, p, newTestOutput())
+	err := e.WriteHeader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range p.Nodes {
+		err = e.EncodeNode(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, w := range p.Ways {
+		err = e.EncodeWay(w)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, r := range p.Relations {
+		err = e.EncodeRelation(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = e.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d := NewPBFDecoder(buf)
+	elements := make([]interface{}, 0)
+	for {
+		element, err := d.Decode()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		elements = append(elements, element)
+	}
+	if len(elements) != 4 {
+		t.Fatalf("Expected 4 elements, got %d.", len(elements))
+	}
+
+	for i, expected := range p.Nodes {
+		n, ok := elements[i].(*Node)
+		if !ok {
+			t.Fatalf("Expected a node, got %T.", elements[i])
+		}
+		if n.Id != expected.Id || n.Longitude != expected.Longitude || n.Latitude != expected.Latitude {
+			t.Fatalf("Expected node %d at %v,%v, got node %d at %v,%v.", expected.Id, expected.Longitude, expected.Latitude, n.Id, n.Longitude, n.Latitude)
+		}
+		if n.Version != 3 || n.Changeset != 100 || n.UserId != 7 || n.UserName != "mapper" || n.Timestamp == nil || !n.Timestamp.Equal(*expected.Timestamp) {
+			t.Fatalf("Expected the metadata of node %d to round trip, got %+v.", n.Id, n.Element)
+		}
+		tags := p.Tags.Slice(expected.TagsIndex)
+		if len(n.Tags) != 1 || n.Tags[0] != tags[0] {
+			t.Fatalf("Expected node %d to be tagged %v, got %v.", n.Id, tags, n.Tags)
+		}
+	}
+
+	w, ok := elements[2].(*Way)
+	if !ok {
+		t.Fatalf("Expected a way, got %T.", elements[2])
+	}
+	if w.Id != 10 || w.Version != 2 || len(w.NodeReferences) != 2 || w.NodeReferences[0].Reference != 2 || w.NodeReferences[1].Reference != 1 {
+		t.Fatalf("Expected way 10 version 2 with nodes 2 and 1, got way %d version %d with %v.", w.Id, w.Version, w.NodeReferences)
+	}
+	if len(w.Tags) != 1 || w.Tags[0].Key != "highway" || w.Tags[0].Value != "residential" {
+		t.Fatalf("Expected way 10 to be tagged highway=residential, got %v.", w.Tags)
+	}
+
+	r, ok := elements[3].(*Relation)
+	if !ok {
+		t.Fatalf("Expected a relation, got %T.", elements[3])
+	}
+	if r.Id != 20 || len(r.Members) != 2 {
+		t.Fatalf("Expected relation 20 with 2 members, got relation %d with %v.", r.Id, r.Members)
+	}
+	for i, m := range p.Relations[0].Members {
+		if r.Members[i] != m {
+			t.Fatalf("Expected member %d to be %v, got %v.", i, m, r.Members[i])
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package osm
+
+import (
+	"time"
+)
+
+// PBFHeader is the decoded HeaderBlock of an OSM PBF file.
+type PBFHeader struct {
+	Bounds               *Bounds    // the bounding box of the file, if any
+	RequiredFeatures     []string   // features required to correctly read the file
+	OptionalFeatures     []string   // optional features present in the file
+	WritingProgram       string     // the program that wrote the file
+	Source               string     // the source of the data
+	ReplicationTimestamp *time.Time // the replication timestamp, if any
+	ReplicationSequence  int64      // the replication sequence number, if any
+	ReplicationBaseUrl   string     // the replication base url, if any
+}
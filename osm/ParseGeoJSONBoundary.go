@@ -0,0 +1,131 @@
+package osm
+
+import (
+	"encoding/json"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// geojsonObject is a GeoJSON geometry, feature, or feature collection.
+type geojsonObject struct {
+	Type        string                 `json:"type"`
+	Id          interface{}            `json:"id,omitempty"`
+	Coordinates json.RawMessage        `json:"coordinates,omitempty"`
+	Geometry    *geojsonObject         `json:"geometry,omitempty"`
+	Geometries  []*geojsonObject       `json:"geometries,omitempty"`
+	Features    []*geojsonObject       `json:"features,omitempty"`
+	Properties  map[string]interface{} `json:"properties,omitempty"`
+}
+
+// rings returns the rings of the polygons in the object.
+func (o *geojsonObject) rings() ([][][]float64, error) {
+	switch o.Type {
+	case "Polygon":
+		polygon := make([][][]float64, 0)
+		err := json.Unmarshal(o.Coordinates, &polygon)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error parsing coordinates of GeoJSON Polygon")
+		}
+		return polygon, nil
+	case "MultiPolygon":
+		multipolygon := make([][][][]float64, 0)
+		err := json.Unmarshal(o.Coordinates, &multipolygon)
+		if err != nil {
+			return nil, errors.Wrap(err, "Error parsing coordinates of GeoJSON MultiPolygon")
+		}
+		rings := make([][][]float64, 0)
+		for _, polygon := range multipolygon {
+			rings = append(rings, polygon...)
+		}
+		return rings, nil
+	case "Feature":
+		if o.Geometry == nil {
+			return nil, errors.New("GeoJSON Feature has no geometry.")
+		}
+		return o.Geometry.rings()
+	case "FeatureCollection", "GeometryCollection":
+		children := o.Features
+		if o.Type == "GeometryCollection" {
+			children = o.Geometries
+		}
+		rings := make([][][]float64, 0)
+		for _, child := range children {
+			r, err := child.rings()
+			if err != nil {
+				return nil, err
+			}
+			rings = append(rings, r...)
+		}
+		return rings, nil
+	}
+	return nil, errors.New("GeoJSON type " + o.Type + " is not a Polygon or MultiPolygon.")
+}
+
+// parts returns the points, lines, and polygons of the geometry, including the members of multi-part geometries and geometry collections.
+func (o *geojsonObject) parts() ([][]float64, [][][]float64, [][][][]float64, error) {
+	points := make([][]float64, 0)
+	lines := make([][][]float64, 0)
+	polygons := make([][][][]float64, 0)
+	var err error
+	switch o.Type {
+	case "Point":
+		point := make([]float64, 0)
+		err = json.Unmarshal(o.Coordinates, &point)
+		points = append(points, point)
+	case "MultiPoint":
+		err = json.Unmarshal(o.Coordinates, &points)
+	case "LineString":
+		line := make([][]float64, 0)
+		err = json.Unmarshal(o.Coordinates, &line)
+		lines = append(lines, line)
+	case "MultiLineString":
+		err = json.Unmarshal(o.Coordinates, &lines)
+	case "Polygon":
+		polygon := make([][][]float64, 0)
+		err = json.Unmarshal(o.Coordinates, &polygon)
+		polygons = append(polygons, polygon)
+	case "MultiPolygon":
+		err = json.Unmarshal(o.Coordinates, &polygons)
+	case "GeometryCollection":
+		for _, child := range o.Geometries {
+			child_points, child_lines, child_polygons, err := child.parts()
+			if err != nil {
+				return points, lines, polygons, err
+			}
+			points = append(points, child_points...)
+			lines = append(lines, child_lines...)
+			polygons = append(polygons, child_polygons...)
+		}
+	default:
+		return points, lines, polygons, errors.New("GeoJSON type " + o.Type + " is not a geometry.")
+	}
+	if err != nil {
+		return points, lines, polygons, errors.Wrap(err, "Error parsing coordinates of GeoJSON "+o.Type)
+	}
+	return points, lines, polygons, nil
+}
+
+// ParseGeoJSONBoundary parses a GeoJSON Polygon or MultiPolygon into a Boundary.
+// The geometry can also be wrapped in a Feature, FeatureCollection, or GeometryCollection, in which case the polygons are combined.
+// Returns the boundary and an error if any.
+func ParseGeoJSONBoundary(data []byte) (*Boundary, error) {
+
+	o := &geojsonObject{}
+	err := json.Unmarshal(data, o)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error parsing GeoJSON boundary")
+	}
+
+	rings, err := o.rings()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rings) == 0 {
+		return nil, errors.New("GeoJSON boundary has no polygons.")
+	}
+
+	return NewBoundary(rings), nil
+}
@@ -0,0 +1,33 @@
+package osm
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ParseMaxSpeed parses the value of a maxspeed tag and returns the speed in kilometers per hour.
+// Supports plain numbers in kilometers per hour, numbers followed by km/h, kmh, or mph, and "walk".
+// If the tag has multiple values separated by semicolons, then uses the first value.
+// Returns false if the value is not a valid speed, e.g., "none" or a country-specific code such as "DE:urban".
+func ParseMaxSpeed(value string) (float64, bool) {
+	value = strings.TrimSpace(strings.SplitN(value, ";", 2)[0])
+	if value == "walk" {
+		return 5, true
+	}
+
+	multiplier := 1.0
+	if strings.HasSuffix(value, "mph") {
+		multiplier = 1.609344
+		value = strings.TrimSpace(strings.TrimSuffix(value, "mph"))
+	} else if strings.HasSuffix(value, "km/h") {
+		value = strings.TrimSpace(strings.TrimSuffix(value, "km/h"))
+	} else if strings.HasSuffix(value, "kmh") {
+		value = strings.TrimSpace(strings.TrimSuffix(value, "kmh"))
+	}
+
+	speed, err := strconv.ParseFloat(value, 64)
+	if err != nil || speed <= 0 {
+		return 0, false
+	}
+	return speed * multiplier, true
+}
@@ -0,0 +1,22 @@
+package osm
+
+// ParseOneway returns whether a way can be traveled forward, in the direction of its nodes, and backward, given its tags.
+// The oneway tag values yes, true, and 1 allow only forward travel and -1 and reverse allow only backward travel.
+// Without a oneway tag, motorways and roundabouts are oneway.  Reversible and alternating ways cannot be traveled in either direction, since the direction changes over time.
+func ParseOneway(tags map[string]interface{}) (bool, bool) {
+	if oneway, ok := tags["oneway"]; ok {
+		switch oneway {
+		case "yes", "true", "1":
+			return true, false
+		case "-1", "reverse":
+			return false, true
+		case "reversible", "alternating":
+			return false, false
+		}
+		return true, true
+	}
+	if tags["highway"] == "motorway" || tags["junction"] == "roundabout" || tags["junction"] == "circular" {
+		return true, false
+	}
+	return true, true
+}
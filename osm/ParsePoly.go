@@ -0,0 +1,72 @@
+package osm
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ParsePoly parses an Osmosis polygon filter file (.poly) into a Boundary.
+// The file starts with a name, followed by sections of "lon lat" lines that each end with END.
+// Sections with a name starting with "!" are holes.  The file ends with END.
+//	- https://wiki.openstreetmap.org/wiki/Osmosis/Polygon_Filter_File_Format
+// Returns the boundary and an error if any.
+func ParsePoly(r io.Reader) (*Boundary, error) {
+
+	rings := make([][][]float64, 0)
+
+	scanner := bufio.NewScanner(r)
+
+	// The first line is the name of the polygon.
+	if !scanner.Scan() {
+		return nil, errors.New("Polygon file is empty.")
+	}
+
+	var ring [][]float64
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 {
+			continue
+		}
+		if ring == nil {
+			if line == "END" {
+				return NewBoundary(rings), nil
+			}
+			// Start a new section.  Holes are handled by the even-odd rule, so the name is ignored.
+			ring = make([][]float64, 0)
+			continue
+		}
+		if line == "END" {
+			if len(ring) < 3 {
+				return nil, errors.New("Polygon section has " + strconv.Itoa(len(ring)) + " points, but requires at least 3.")
+			}
+			rings = append(rings, ring)
+			ring = nil
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, errors.New("Invalid polygon coordinate \"" + line + "\".")
+		}
+		lon, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid polygon longitude \""+fields[0]+"\".")
+		}
+		lat, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid polygon latitude \""+fields[1]+"\".")
+		}
+		ring = append(ring, []float64{lon, lat})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrap(err, "Error reading polygon file")
+	}
+
+	return nil, errors.New("Polygon file is missing the final END.")
+}
@@ -34,6 +34,9 @@ type Planet struct {
 	Tags           *TagsCache        `xml:"-"`
 	UserNames      map[uint64]string `xml:"-"` // map of UserName by UserId
 	Rtree          *rtreego.Rtree    `xml:"-"`
+	rtreeValid     bool              `xml:"-"` // false if the planet changed since the R-tree was built
+	rtreeMutex     *sync.Mutex       `xml:"-"` // guards building the R-tree.  A pointer, so methods with value receivers do not copy the lock.
+	Conflicts      []*MergeConflict  `xml:"-"` // elements present in multiple inputs and how they were resolved
 }
 
 func NewPlanet() *Planet {
@@ -48,6 +51,8 @@ func NewPlanet() *Planet {
 		Tags:           NewTagsCache(),
 		UserNames:      map[uint64]string{},
 		Rtree:          rtreego.NewTree(2, 25, 50),
+		rtreeMutex:     &sync.Mutex{},
+		Conflicts:      make([]*MergeConflict, 0),
 	}
 	return p
 }
@@ -56,44 +61,123 @@ func (p *Planet) Init() error {
 	return nil
 }
 
-func (p *Planet) WayToFeature(w *Way) graph.Feature {
+// WayToFeature converts a way to a graph.Feature.
+// A closed way with at least 4 nodes is converted to a Polygon if the area rules classify its tags as an area, otherwise the way is converted to a LineString.
+// Returns an *IncompleteWay as the error if the way references a node that is missing from the planet.
+func (p *Planet) WayToFeature(w *Way, rules AreaRules) (graph.Feature, error) {
 
-	coordinates := make([][]float64, 0)
-	for _, nr := range w.NodeReferences {
-		n := p.Nodes[p.nodesIndex[nr.Reference]]
-		coordinates = append(coordinates, []float64{n.Longitude, n.Latitude})
+	coordinates, err := p.WayCoordinates(w)
+	if err != nil {
+		return graph.Feature{}, err
 	}
 
-	if coordinates[0][0] == coordinates[len(coordinates)][0] && coordinates[0][1] == coordinates[len(coordinates)][1] {
+	properties := p.Tags.Map(w.TagsIndex)
+
+	if w.IsClosed() && rules.IsArea(properties) {
 		return graph.NewFeature(
 			w.GetId(),
-			p.Tags.Map(w.TagsIndex),
-			graph.NewPolygon(coordinates))
+			properties,
+			graph.NewPolygon(coordinates)), nil
 	}
 
 	return graph.NewFeature(
 		w.GetId(),
-		p.Tags.Map(w.TagsIndex),
-		graph.NewLine(coordinates))
+		properties,
+		graph.NewLine(coordinates)), nil
+}
+
+// WayCoordinates returns the [longitude, latitude] coordinates of the nodes of a way, in order.
+// Returns an *IncompleteWay as the error if the way references nodes that are missing from the planet.
+func (p *Planet) WayCoordinates(w *Way) ([][]float64, error) {
+	coordinates := make([][]float64, 0, len(w.NodeReferences))
+	missing_nodes := make([]uint64, 0)
+	for _, nr := range w.NodeReferences {
+		i, ok := p.nodesIndex[nr.Reference]
+		if !ok {
+			missing_nodes = append(missing_nodes, nr.Reference)
+			continue
+		}
+		n := p.Nodes[i]
+		coordinates = append(coordinates, []float64{n.Longitude, n.Latitude})
+	}
+	if len(missing_nodes) > 0 {
+		return coordinates, &IncompleteWay{Id: w.Id, MissingNodes: missing_nodes, Reason: "nodes are missing from the planet"}
+	}
+	return coordinates, nil
+}
+
+// RelationToFeature converts a relation to a graph.Feature.
+// Multipolygon and boundary relations are converted to MultiPolygon geometries.  See AssembleMultiPolygon.
+// Route and route_master relations are converted to MultiLineString geometries, with the ordered members as the "members" property.  See AssembleRoute.
+// Returns an *IncompleteRelation as the error if the relation cannot be assembled, and an error if the relation type is not supported.
+func (p *Planet) RelationToFeature(r *Relation) (graph.Feature, error) {
+	properties := p.Tags.Map(r.TagsIndex)
+	switch t := p.RelationType(r); t {
+	case "multipolygon", "boundary":
+		coordinates, err := p.AssembleMultiPolygon(r)
+		if err != nil {
+			return graph.Feature{}, err
+		}
+		return graph.NewFeature(r.GetId(), properties, graph.NewMultiPolygon(coordinates)), nil
+	case "route", "route_master":
+		coordinates, members, err := p.AssembleRoute(r)
+		if err != nil {
+			return graph.Feature{}, err
+		}
+		properties["members"] = members
+		return graph.NewFeature(r.GetId(), properties, graph.NewMultiLine(coordinates)), nil
+	default:
+		return graph.Feature{}, errors.New("Relation type " + t + " is not supported")
+	}
+}
+
+// RelationType returns the value of the type tag of the relation or a blank string if the relation has no type.
+func (p *Planet) RelationType(r *Relation) string {
+	if t, ok := p.Tags.Map(r.TagsIndex)["type"]; ok {
+		return fmt.Sprint(t)
+	}
+	return ""
+}
+
+// GetFeatures returns the nodes, ways, and relations in the planet that pass the output filter as features.
+// Only relations with a type selected by the output are converted.  See Output.HasRelationType.
+// Ways and relations that cannot be converted into features are not returned as features, but are returned as incomplete ways and relations.
+// Returns the features, the incomplete ways, the incomplete relations, and an error if any.
+func (p *Planet) GetFeatures(output *Output) ([]graph.Feature, []*IncompleteWay, []*IncompleteRelation, error) {
+	features := make([]graph.Feature, 0)
+	incomplete_ways, incomplete, err := p.WalkFeatures(output, func(element_type string, element interface{}, f graph.Feature) error {
+		features = append(features, f)
+		return nil
+	})
+	return features, incomplete_ways, incomplete, err
 }
 
-func (p *Planet) GetFeatures(output *Output) ([]graph.Feature, error) {
+// WalkFeatures converts the nodes, ways, and relations in the planet that pass the output filter into features and calls fn with each feature,
+// along with the type of the element it was converted from (node, way, or relation) and the element itself.
+// If the output converts ways to nodes, then the element of a way is the *Node it was converted into.  The features are selected the same as GetFeatures.
+// Ways and relations that cannot be converted are skipped and returned as incomplete ways and relations.
+// Stops at the first error returned by fn.  Returns the incomplete ways, the incomplete relations, and an error if any.
+func (p *Planet) WalkFeatures(output *Output, fn func(element_type string, element interface{}, f graph.Feature) error) ([]*IncompleteWay, []*IncompleteRelation, error) {
 
 	var dfl_cache *dfl.Cache
 	if output.Filter.HasExpression() && output.Filter.UseCache {
 		dfl_cache = dfl.NewCache()
 	}
 
-	features := make([]graph.Feature, 0)
+	incomplete_ways := make([]*IncompleteWay, 0)
+	incomplete := make([]*IncompleteRelation, 0)
 
 	if !output.DropNodes {
 		for _, n := range p.Nodes {
 			keep, err := KeepNode(p, output.Filter, n, dfl_cache)
 			if err != nil {
-				return features, errors.Wrap(err, "Error filtering node for FeatureCollection")
+				return incomplete_ways, incomplete, errors.Wrap(err, "Error filtering node for FeatureCollection")
 			}
 			if keep {
-				features = append(features, NodeToFeature(n, p.Tags))
+				err := fn("node", n, NodeToFeature(n, p.Tags))
+				if err != nil {
+					return incomplete_ways, incomplete, err
+				}
 			}
 		}
 	}
@@ -101,38 +185,81 @@ func (p *Planet) GetFeatures(output *Output) ([]graph.Feature, error) {
 	uid := p.maxId
 	//nodes := make([]Node, 0)
 	if !output.DropWays {
+		area_rules := output.GetAreaRules()
 		for _, w := range p.Ways {
 			keep, err := KeepWay(p, output.Filter, w, dfl_cache)
 			if err != nil {
-				return features, errors.Wrap(err, "Error filtering way for FeatureCollection.")
+				return incomplete_ways, incomplete, errors.Wrap(err, "Error filtering way for FeatureCollection.")
 			}
 			if keep {
 				if output.WaysToNodes {
 					n, err := p.ConvertWayToNode(w, uid)
 					if err != nil {
-						fmt.Println(errors.Wrap(err, "Error converting way "+fmt.Sprint(w.Id)+" to node.  Planet has "+fmt.Sprint(len(p.Nodes))+" nodes."))
+						incomplete_ways = append(incomplete_ways, &IncompleteWay{Id: w.Id, Reason: err.Error()})
 						continue
 					}
 					uid += 1
-					features = append(features, NodeToFeature(n, p.Tags))
+					err = fn("way", n, NodeToFeature(n, p.Tags))
+					if err != nil {
+						return incomplete_ways, incomplete, err
+					}
 				} else {
+					f, err := p.WayToFeature(w, area_rules)
+					if err != nil {
+						if iw, ok := err.(*IncompleteWay); ok {
+							incomplete_ways = append(incomplete_ways, iw)
+							continue
+						}
+						return incomplete_ways, incomplete, errors.Wrap(err, "Error converting way "+fmt.Sprint(w.Id)+" to feature.")
+					}
 					uid += 1
-					features = append(features, p.WayToFeature(w))
+					err = fn("way", w, f)
+					if err != nil {
+						return incomplete_ways, incomplete, err
+					}
+				}
+			}
+		}
+	}
+
+	if !output.DropRelations {
+		for _, r := range p.Relations {
+			if !output.HasRelationType(p.RelationType(r)) {
+				continue
+			}
+			keep, err := KeepRelation(p, output.Filter, r, dfl_cache)
+			if err != nil {
+				return incomplete_ways, incomplete, errors.Wrap(err, "Error filtering relation for FeatureCollection.")
+			}
+			if keep {
+				f, err := p.RelationToFeature(r)
+				if err != nil {
+					if ir, ok := err.(*IncompleteRelation); ok {
+						incomplete = append(incomplete, ir)
+						continue
+					}
+					return incomplete_ways, incomplete, errors.Wrap(err, "Error converting relation "+fmt.Sprint(r.Id)+" to feature.")
+				}
+				err = fn("relation", r, f)
+				if err != nil {
+					return incomplete_ways, incomplete, err
 				}
 			}
 		}
 	}
 
-	return features, nil
+	return incomplete_ways, incomplete, nil
 }
 
-func (p *Planet) GetFeatureCollection(output *Output) (graph.FeatureCollection, error) {
+// GetFeatureCollection returns the features in the planet as a graph.FeatureCollection.  See GetFeatures.
+// Returns the feature collection, the incomplete ways, the incomplete relations, and an error if any.
+func (p *Planet) GetFeatureCollection(output *Output) (graph.FeatureCollection, []*IncompleteWay, []*IncompleteRelation, error) {
 
-	features, err := p.GetFeatures(output)
+	features, incomplete_ways, incomplete, err := p.GetFeatures(output)
 	if err != nil {
-		return graph.FeatureCollection{}, errors.Wrap(err, "error getting features from planet")
+		return graph.FeatureCollection{}, incomplete_ways, incomplete, errors.Wrap(err, "error getting features from planet")
 	}
-	return graph.NewFeatureCollection(features), nil
+	return graph.NewFeatureCollection(features), incomplete_ways, incomplete, nil
 }
 
 func (p Planet) BoundingBox() string {
@@ -164,6 +291,7 @@ func (p *Planet) AddNode(n *Node) error {
 
 	p.Nodes = append(p.Nodes, n)
 	p.nodesIndex[n.Id] = len(p.Nodes) - 1
+	p.rtreeValid = false
 
 	if n.Id > p.maxId {
 		p.maxId = n.Id
@@ -181,6 +309,7 @@ func (p *Planet) AddWay(w *Way) error {
 
 	p.Ways = append(p.Ways, w)
 	p.waysIndex[w.Id] = len(p.Ways) - 1
+	p.rtreeValid = false
 
 	if w.Id > p.maxId {
 		p.maxId = w.Id
@@ -198,6 +327,7 @@ func (p *Planet) AddRelation(r *Relation) error {
 
 	p.Relations = append(p.Relations, r)
 	p.relationsIndex[r.Id] = len(p.Relations) - 1
+	p.rtreeValid = false
 
 	if r.Id > p.maxId {
 		p.maxId = r.Id
@@ -234,7 +364,8 @@ func (p *Planet) ConvertWayToNode(w *Way, way_node_id uint64) (*Node, error) {
 				UserId:    w.UserId,
 				UserName:  w.UserName,
 			},
-			Tags: w.Tags,
+			Tags:      w.Tags,
+			TagsIndex: w.TagsIndex,
 		},
 		Longitude: sum_lon / count,
 		Latitude:  sum_lat / count,
@@ -266,6 +397,7 @@ func (p *Planet) FilterNodes(fi *Filter, dfl_cache *dfl.Cache) error {
 			}
 		}
 		p.Nodes = nodes
+		p.rtreeValid = false
 
 	}
 
@@ -287,6 +419,7 @@ func (p *Planet) FilterWays(fi *Filter, dfl_cache *dfl.Cache) error {
 			}
 		}
 		p.Ways = ways
+		p.rtreeValid = false
 
 	}
 
@@ -310,10 +443,12 @@ func (p *Planet) Filter(fi *Filter, dfl_cache *dfl.Cache) error {
 
 func (p *Planet) DropWays() {
 	p.Ways = make([]*Way, 0)
+	p.rtreeValid = false
 }
 
 func (p *Planet) DropRelations() {
 	p.Relations = make([]*Relation, 0)
+	p.rtreeValid = false
 }
 
 /*
@@ -412,6 +547,7 @@ func (p *Planet) ConvertWaysToNodes(async bool) {
 	}
 
 	p.Ways = make([]*Way, 0)
+	p.rtreeValid = false
 
 }
 
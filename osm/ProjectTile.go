@@ -0,0 +1,37 @@
+package osm
+
+import (
+	"math"
+)
+
+// MAX_MERCATOR_LATITUDE is the northern limit of the Web Mercator projection used by XYZ tiles.  The southern limit is the negative.
+const MAX_MERCATOR_LATITUDE = 85.0511287798066
+
+// projectTile projects a longitude and latitude to Web Mercator pixel coordinates at zoom level z, with extent pixels per tile.
+// The origin is the top left corner of tile 0/0/0, so tile x, y covers the pixels from x*extent to (x+1)*extent and y*extent to (y+1)*extent.
+func projectTile(lon float64, lat float64, z int, extent int) (float64, float64) {
+	size := math.Pow(2, float64(z)) * float64(extent)
+	lat_rad := math.Max(-MAX_MERCATOR_LATITUDE, math.Min(MAX_MERCATOR_LATITUDE, lat)) * math.Pi / 180.0
+	x := (180.0 + lon) / 360.0 * size
+	y := (1.0 - math.Log(math.Tan(lat_rad)+(1/math.Cos(lat_rad)))/math.Pi) / 2.0 * size
+	return x, y
+}
+
+// pixelTile returns the x and y of the tile at zoom level z that contains the Web Mercator pixel coordinates, with extent pixels per tile.
+// Coordinates on or beyond the edges of the world are clamped to the first or last row or column of tiles.
+func pixelTile(px float64, py float64, z int, extent int) (int, int) {
+	tiles_max := math.Pow(2, float64(z)) - 1
+	x := math.Max(0, math.Min(tiles_max, math.Floor(px/float64(extent))))
+	y := math.Max(0, math.Min(tiles_max, math.Floor(py/float64(extent))))
+	return int(x), int(y)
+}
+
+// projectTileLine projects the coordinates of a line or ring to Web Mercator pixel coordinates at zoom level z.  See projectTile.
+func projectTileLine(line [][]float64, z int, extent int) [][]float64 {
+	projected := make([][]float64, 0, len(line))
+	for _, c := range line {
+		x, y := projectTile(c[0], c[1], z, extent)
+		projected = append(projected, []float64{x, y})
+	}
+	return projected
+}
@@ -0,0 +1,158 @@
+package osm
+
+import (
+	"strconv"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+const (
+	protoWireVarint  = 0
+	protoWire64Bit   = 1
+	protoWireBytes   = 2
+	protoWire32Bit   = 5
+	protoMaxVarintLn = 10
+)
+
+// protoReader is a minimal reader of the protocol buffers wire format.
+// It is used to decode OSM PBF blocks without generated code.
+type protoReader struct {
+	data []byte
+	pos  int
+}
+
+func newProtoReader(data []byte) *protoReader {
+	return &protoReader{data: data, pos: 0}
+}
+
+// More returns true if there is data left to read.
+func (r *protoReader) More() bool {
+	return r.pos < len(r.data)
+}
+
+// ReadVarint reads an unsigned base 128 varint.
+func (r *protoReader) ReadVarint() (uint64, error) {
+	x := uint64(0)
+	for shift, i := uint(0), 0; i < protoMaxVarintLn; shift, i = shift+7, i+1 {
+		if r.pos >= len(r.data) {
+			return 0, errors.New("Unexpected end of buffer while reading varint.")
+		}
+		b := r.data[r.pos]
+		r.pos += 1
+		x |= uint64(b&0x7f) << shift
+		if b < 0x80 {
+			return x, nil
+		}
+	}
+	return 0, errors.New("Varint is too long.")
+}
+
+// ReadSint64 reads a zigzag encoded signed varint.
+func (r *protoReader) ReadSint64() (int64, error) {
+	x, err := r.ReadVarint()
+	if err != nil {
+		return 0, err
+	}
+	return int64(x>>1) ^ -int64(x&1), nil
+}
+
+// ReadKey reads a field key and returns the field number and wire type.
+func (r *protoReader) ReadKey() (int, int, error) {
+	x, err := r.ReadVarint()
+	if err != nil {
+		return 0, 0, err
+	}
+	return int(x >> 3), int(x & 0x7), nil
+}
+
+// ReadBytes reads a length-delimited field.
+// The returned slice shares the underlying buffer.
+func (r *protoReader) ReadBytes() ([]byte, error) {
+	n, err := r.ReadVarint()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(r.data)-r.pos) < n {
+		return nil, errors.New("Unexpected end of buffer while reading length-delimited field.")
+	}
+	b := r.data[r.pos : r.pos+int(n)]
+	r.pos += int(n)
+	return b, nil
+}
+
+// Skip skips over the value of a field with the given wire type.
+func (r *protoReader) Skip(wireType int) error {
+	switch wireType {
+	case protoWireVarint:
+		_, err := r.ReadVarint()
+		return err
+	case protoWire64Bit:
+		if len(r.data)-r.pos < 8 {
+			return errors.New("Unexpected end of buffer while skipping 64-bit field.")
+		}
+		r.pos += 8
+		return nil
+	case protoWireBytes:
+		_, err := r.ReadBytes()
+		return err
+	case protoWire32Bit:
+		if len(r.data)-r.pos < 4 {
+			return errors.New("Unexpected end of buffer while skipping 32-bit field.")
+		}
+		r.pos += 4
+		return nil
+	}
+	return errors.New("Unsupported protocol buffer wire type " + strconv.Itoa(wireType) + ".")
+}
+
+// ReadPackedVarints reads a repeated varint field.
+// Supports both packed (length-delimited) and unpacked encodings.
+func (r *protoReader) ReadPackedVarints(wireType int, values []uint64) ([]uint64, error) {
+	if wireType == protoWireVarint {
+		x, err := r.ReadVarint()
+		if err != nil {
+			return values, err
+		}
+		return append(values, x), nil
+	}
+	b, err := r.ReadBytes()
+	if err != nil {
+		return values, err
+	}
+	pr := newProtoReader(b)
+	for pr.More() {
+		x, err := pr.ReadVarint()
+		if err != nil {
+			return values, err
+		}
+		values = append(values, x)
+	}
+	return values, nil
+}
+
+// ReadPackedSint64s reads a repeated zigzag encoded field.
+// Supports both packed (length-delimited) and unpacked encodings.
+func (r *protoReader) ReadPackedSint64s(wireType int, values []int64) ([]int64, error) {
+	if wireType == protoWireVarint {
+		x, err := r.ReadSint64()
+		if err != nil {
+			return values, err
+		}
+		return append(values, x), nil
+	}
+	b, err := r.ReadBytes()
+	if err != nil {
+		return values, err
+	}
+	pr := newProtoReader(b)
+	for pr.More() {
+		x, err := pr.ReadSint64()
+		if err != nil {
+			return values, err
+		}
+		values = append(values, x)
+	}
+	return values, nil
+}
@@ -0,0 +1,105 @@
+package osm
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// protoWriter is a minimal writer of the protocol buffers wire format.
+// It is used to encode OSM PBF blocks and vector tiles without generated code.
+type protoWriter struct {
+	data []byte
+}
+
+func newProtoWriter(capacity int) *protoWriter {
+	return &protoWriter{data: make([]byte, 0, capacity)}
+}
+
+// Bytes returns the encoded message.
+func (w *protoWriter) Bytes() []byte {
+	return w.data
+}
+
+// Len returns the length of the encoded message in bytes.
+func (w *protoWriter) Len() int {
+	return len(w.data)
+}
+
+// Reset clears the encoded message, but keeps the underlying buffer.
+func (w *protoWriter) Reset() {
+	w.data = w.data[:0]
+}
+
+func (w *protoWriter) appendVarint(x uint64) {
+	for x >= 0x80 {
+		w.data = append(w.data, byte(x)|0x80)
+		x >>= 7
+	}
+	w.data = append(w.data, byte(x))
+}
+
+func (w *protoWriter) appendSint64(x int64) {
+	w.appendVarint(uint64((x << 1) ^ (x >> 63)))
+}
+
+func (w *protoWriter) appendKey(field int, wireType int) {
+	w.appendVarint(uint64(field)<<3 | uint64(wireType))
+}
+
+// WriteVarint writes an unsigned varint field.
+func (w *protoWriter) WriteVarint(field int, x uint64) {
+	w.appendKey(field, protoWireVarint)
+	w.appendVarint(x)
+}
+
+// WriteSint64 writes a zigzag encoded signed varint field.
+func (w *protoWriter) WriteSint64(field int, x int64) {
+	w.appendKey(field, protoWireVarint)
+	w.appendVarint(uint64((x << 1) ^ (x >> 63)))
+}
+
+// WriteDouble writes a 64-bit floating point field.
+func (w *protoWriter) WriteDouble(field int, x float64) {
+	w.appendKey(field, protoWire64Bit)
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, math.Float64bits(x))
+	w.data = append(w.data, b...)
+}
+
+// WriteBytes writes a length-delimited field.
+func (w *protoWriter) WriteBytes(field int, b []byte) {
+	w.appendKey(field, protoWireBytes)
+	w.appendVarint(uint64(len(b)))
+	w.data = append(w.data, b...)
+}
+
+// WriteString writes a string as a length-delimited field.
+func (w *protoWriter) WriteString(field int, s string) {
+	w.appendKey(field, protoWireBytes)
+	w.appendVarint(uint64(len(s)))
+	w.data = append(w.data, s...)
+}
+
+// WritePackedVarints writes a packed repeated varint field.  Writes nothing if values is empty.
+func (w *protoWriter) WritePackedVarints(field int, values []uint64) {
+	if len(values) == 0 {
+		return
+	}
+	packed := newProtoWriter(len(values) * 2)
+	for _, x := range values {
+		packed.appendVarint(x)
+	}
+	w.WriteBytes(field, packed.Bytes())
+}
+
+// WritePackedSint64s writes a packed repeated zigzag encoded field.  Writes nothing if values is empty.
+func (w *protoWriter) WritePackedSint64s(field int, values []int64) {
+	if len(values) == 0 {
+		return
+	}
+	packed := newProtoWriter(len(values) * 2)
+	for _, x := range values {
+		packed.appendVarint(uint64((x << 1) ^ (x >> 63)))
+	}
+	w.WriteBytes(field, packed.Bytes())
+}
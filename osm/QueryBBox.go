@@ -0,0 +1,40 @@
+package osm
+
+import (
+	"sort"
+)
+
+// QueryBBox returns the nodes, ways, and relations in the planet whose bounding boxes intersect the given bounds, sorted by id.
+// Builds the R-tree if the planet changed since it was last built.
+// Returns an error if the bounds cannot be a rectangle.  See Bounds.Rect.
+func (p *Planet) QueryBBox(b Bounds) ([]*Node, []*Way, []*Relation, error) {
+
+	rect, err := b.Rect()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	nodes := make([]*Node, 0)
+	ways := make([]*Way, 0)
+	relations := make([]*Relation, 0)
+
+	for _, obj := range p.rtree().SearchIntersect(rect) {
+		switch e := obj.(type) {
+		case *Node:
+			nodes = append(nodes, e)
+		case *SpatialElement:
+			switch x := e.Element.(type) {
+			case *Way:
+				ways = append(ways, x)
+			case *Relation:
+				relations = append(relations, x)
+			}
+		}
+	}
+
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Id < nodes[j].Id })
+	sort.Slice(ways, func(i, j int) bool { return ways[i].Id < ways[j].Id })
+	sort.Slice(relations, func(i, j int) bool { return relations[i].Id < relations[j].Id })
+
+	return nodes, ways, relations, nil
+}
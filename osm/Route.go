@@ -0,0 +1,31 @@
+package osm
+
+import (
+	"github.com/spatialcurrent/go-graph/graph"
+)
+
+// Route is a path through a routing graph found by Router.Route.
+type Route struct {
+	Profile     string      // name of the routing profile
+	Nodes       []uint64    // ids of the nodes along the route, from the start to the end
+	Ways        []uint64    // ids of the ways along the route, without repeating consecutive ways
+	Coordinates [][]float64 // [longitude, latitude] of each node along the route
+	Distance    float64     // length of the route in meters
+	Duration    float64     // time to travel the route in seconds
+}
+
+// Feature returns the route as a graph.Feature with a LineString geometry, which serializes to GeoJSON.
+// The properties are the profile, distance in meters, duration in seconds, and the ids of the nodes at the start and end and the ways along the route.
+func (r *Route) Feature() graph.Feature {
+	properties := map[string]interface{}{
+		"profile":  r.Profile,
+		"distance": r.Distance,
+		"duration": r.Duration,
+		"ways":     r.Ways,
+	}
+	if len(r.Nodes) > 0 {
+		properties["from"] = r.Nodes[0]
+		properties["to"] = r.Nodes[len(r.Nodes)-1]
+	}
+	return graph.NewFeature("route", properties, graph.NewLine(r.Coordinates))
+}
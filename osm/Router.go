@@ -0,0 +1,243 @@
+package osm
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ROUTER_SNAP_RADIUS is the half width in degrees of the first bounding box searched for the node nearest to a point.  See Router.Snap.
+const ROUTER_SNAP_RADIUS = 0.001
+
+// routeSegment is the part of an edge between two consecutive nodes, in a direction that can be traveled.
+type routeSegment struct {
+	To       uint64  // id of the node at the end of the segment
+	Way      uint64  // id of the way the segment is part of
+	Length   float64 // length in meters
+	Duration float64 // time to travel in seconds
+}
+
+// routeState is a node reached by way of a way from the previous node, since turn restrictions depend on the way and direction a node is reached from.
+type routeState struct {
+	Node     uint64
+	Way      uint64
+	Previous uint64
+}
+
+type routeQueueItem struct {
+	State    routeState
+	Duration float64 // time to reach the state in seconds
+	Estimate float64 // time to reach the state plus the estimated time to the destination
+}
+
+// routeQueue is a priority queue of states ordered by estimate, for use with container/heap.
+type routeQueue []*routeQueueItem
+
+func (q routeQueue) Len() int            { return len(q) }
+func (q routeQueue) Less(i, j int) bool  { return q[i].Estimate < q[j].Estimate }
+func (q routeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *routeQueue) Push(x interface{}) { *q = append(*q, x.(*routeQueueItem)) }
+func (q *routeQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// Router finds the fastest routes through a routing graph with A* search.
+// Routes can start and end at any node of the edges of the graph, not only at the vertices.
+// Turn restrictions in the graph are respected, so routes never turn from a way onto another way where a restriction forbids it.
+type Router struct {
+	Graph        *RoutingGraph
+	planet       *Planet                       // planet the graph was built from, whose R-tree is searched to snap points to the graph
+	nodes        map[uint64][]float64          // [longitude, latitude] of every node of the graph's edges
+	segments     map[uint64][]routeSegment     // segments that can be traveled from each node
+	restrictions map[uint64][]*TurnRestriction // turn restrictions by via node
+	maxSpeed     float64                       // highest speed in the graph in kilometers per hour, used for the A* heuristic
+}
+
+// NewRouter returns a new Router for the graph built from the planet.  See BuildRoutingGraph.
+func NewRouter(p *Planet, g *RoutingGraph) *Router {
+
+	rt := &Router{
+		Graph:        g,
+		planet:       p,
+		nodes:        map[uint64][]float64{},
+		segments:     map[uint64][]routeSegment{},
+		restrictions: map[uint64][]*TurnRestriction{},
+	}
+
+	for _, e := range g.Edges {
+		if e.Speed > rt.maxSpeed {
+			rt.maxSpeed = e.Speed
+		}
+		for i, id := range e.Nodes {
+			rt.nodes[id] = e.Coordinates[i]
+			if i == 0 {
+				continue
+			}
+			a := e.Coordinates[i-1]
+			b := e.Coordinates[i]
+			length := Haversine(a[0], a[1], b[0], b[1])
+			duration := length / (e.Speed / 3.6)
+			if e.Forward {
+				rt.segments[e.Nodes[i-1]] = append(rt.segments[e.Nodes[i-1]], routeSegment{To: id, Way: e.WayId, Length: length, Duration: duration})
+			}
+			if e.Backward {
+				rt.segments[id] = append(rt.segments[id], routeSegment{To: e.Nodes[i-1], Way: e.WayId, Length: length, Duration: duration})
+			}
+		}
+	}
+
+	for _, tr := range g.Restrictions {
+		rt.restrictions[tr.Via] = append(rt.restrictions[tr.Via], tr)
+	}
+
+	return rt
+}
+
+// Snap returns the id of the node of the graph nearest to the longitude and latitude, and the distance to it in meters.
+// The nodes are queried from the R-tree of the planet in a bounding box around the point, starting at ROUTER_SNAP_RADIUS,
+// and the box is doubled until the nearest node of the graph in the box is nearer than the edges of the box.
+// Returns an error if the graph has no nodes.
+func (rt *Router) Snap(lon float64, lat float64) (uint64, float64, error) {
+	if len(rt.nodes) == 0 {
+		return 0, 0, errors.New("The routing graph has no nodes.")
+	}
+	for radius := ROUTER_SNAP_RADIUS; ; radius *= 2 {
+		nodes, _, _, err := rt.planet.QueryBBox(*NewBounds(lon-radius, lat-radius, lon+radius, lat+radius))
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "Error snapping "+fmt.Sprint(lon)+","+fmt.Sprint(lat)+" to the routing graph")
+		}
+		nearest := uint64(0)
+		distance := math.Inf(1)
+		for _, n := range nodes {
+			if _, ok := rt.nodes[n.Id]; !ok {
+				continue
+			}
+			d := Haversine(lon, lat, n.Longitude, n.Latitude)
+			if d < distance || (d == distance && n.Id < nearest) {
+				nearest = n.Id
+				distance = d
+			}
+		}
+		// A node outside the box cannot be nearer than the edges of the box.
+		edge := math.Min(Haversine(lon, lat, lon+radius, lat), Haversine(lon, lat, lon, lat+radius))
+		if distance <= edge || radius >= 360 {
+			if math.IsInf(distance, 1) {
+				return 0, 0, errors.New("No node of the routing graph is in the planet.")
+			}
+			return nearest, distance, nil
+		}
+	}
+}
+
+// allows returns true if the turn restrictions at the node allow turning from a way onto another way, otherwise false.
+// The turn arrives from the before node on the from way and leaves to the after node on the to way.
+// A route can always leave the node where it starts, which is reached from no way.
+func (rt *Router) allows(node uint64, from uint64, before uint64, to uint64, after uint64) bool {
+	if from == 0 {
+		return true
+	}
+	for _, tr := range rt.restrictions[node] {
+		if !tr.Allows(from, before, to, after) {
+			return false
+		}
+	}
+	return true
+}
+
+// Route returns the fastest route between the nodes of the graph nearest to the start and end points, each given as [longitude, latitude].
+// Returns an error if no route exists between the nodes.
+func (rt *Router) Route(from []float64, to []float64) (*Route, error) {
+
+	start, _, err := rt.Snap(from[0], from[1])
+	if err != nil {
+		return nil, err
+	}
+	end, _, err := rt.Snap(to[0], to[1])
+	if err != nil {
+		return nil, err
+	}
+
+	goal := rt.nodes[end]
+	heuristic := func(node uint64) float64 {
+		if rt.maxSpeed <= 0 {
+			return 0
+		}
+		c := rt.nodes[node]
+		return Haversine(c[0], c[1], goal[0], goal[1]) / (rt.maxSpeed / 3.6)
+	}
+
+	initial := routeState{Node: start}
+	durations := map[routeState]float64{initial: 0}
+	lengths := map[routeState]float64{initial: 0}
+	previous := map[routeState]routeState{}
+	done := map[routeState]bool{}
+
+	q := &routeQueue{&routeQueueItem{State: initial, Duration: 0, Estimate: heuristic(start)}}
+	var last *routeState
+	for q.Len() > 0 {
+		item := heap.Pop(q).(*routeQueueItem)
+		s := item.State
+		if done[s] {
+			continue
+		}
+		done[s] = true
+		if s.Node == end {
+			last = &s
+			break
+		}
+		for _, seg := range rt.segments[s.Node] {
+			if !rt.allows(s.Node, s.Way, s.Previous, seg.Way, seg.To) {
+				continue
+			}
+			next := routeState{Node: seg.To, Way: seg.Way, Previous: s.Node}
+			duration := item.Duration + seg.Duration
+			if d, ok := durations[next]; ok && d <= duration {
+				continue
+			}
+			durations[next] = duration
+			lengths[next] = lengths[s] + seg.Length
+			previous[next] = s
+			heap.Push(q, &routeQueueItem{State: next, Duration: duration, Estimate: duration + heuristic(seg.To)})
+		}
+	}
+
+	if last == nil {
+		return nil, errors.New("No route from node " + fmt.Sprint(start) + " to node " + fmt.Sprint(end) + " for profile " + rt.Graph.Profile + ".")
+	}
+
+	states := []routeState{*last}
+	for s := *last; s != initial; {
+		s = previous[s]
+		states = append(states, s)
+	}
+
+	r := &Route{
+		Profile:     rt.Graph.Profile,
+		Nodes:       make([]uint64, 0, len(states)),
+		Ways:        make([]uint64, 0),
+		Coordinates: make([][]float64, 0, len(states)),
+		Distance:    lengths[*last],
+		Duration:    durations[*last],
+	}
+	for i := len(states) - 1; i >= 0; i-- {
+		s := states[i]
+		r.Nodes = append(r.Nodes, s.Node)
+		r.Coordinates = append(r.Coordinates, rt.nodes[s.Node])
+		if s.Way != 0 && (len(r.Ways) == 0 || r.Ways[len(r.Ways)-1] != s.Way) {
+			r.Ways = append(r.Ways, s.Way)
+		}
+	}
+	if len(r.Coordinates) == 1 {
+		// The start and end snap to the same node, but a LineString needs at least two positions.
+		r.Coordinates = append(r.Coordinates, r.Coordinates[0])
+	}
+
+	return r, nil
+}
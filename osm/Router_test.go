@@ -0,0 +1,148 @@
+package osm
+
+import (
+	"math"
+	"testing"
+)
+
+// newTestRoutingPlanet returns a planet with a road from west to east, a side road north from its middle, and a longer way around to the end of the side road.
+//
+//	4 ---- 5
+//	|      |
+//	1 ---- 2 ---- 3
+func newTestRoutingPlanet(t *testing.T, side []Tag, restriction []Tag) *Planet {
+	p := NewPlanet()
+	addTestNode(t, p, 1, 0, 0)
+	addTestNode(t, p, 2, 0.01, 0)
+	addTestNode(t, p, 3, 0.02, 0)
+	addTestNode(t, p, 4, 0.01, 0.01)
+	addTestNode(t, p, 5, 0.02, 0.01)
+	addTestNode(t, p, 6, 1, 1)
+	addTestNode(t, p, 7, 1.01, 1)
+	residential := Tag{Key: "highway", Value: "residential"}
+	addTestWay(t, p, 10, []uint64{1, 2, 3}, []Tag{residential})
+	addTestWay(t, p, 11, []uint64{2, 4}, append([]Tag{residential}, side...))
+	addTestWay(t, p, 12, []uint64{3, 5, 4}, []Tag{residential})
+	addTestWay(t, p, 13, []uint64{6, 7}, []Tag{residential})
+	if len(restriction) > 0 {
+		r := NewRelation()
+		r.Id = 20
+		r.Members = []RelationMember{
+			RelationMember{Type: "way", Reference: 10, Role: "from"},
+			RelationMember{Type: "node", Reference: 2, Role: "via"},
+			RelationMember{Type: "way", Reference: 11, Role: "to"},
+		}
+		r.TagsIndex = p.AddTags(append([]Tag{Tag{Key: "type", Value: "restriction"}}, restriction...))
+		err := p.AddRelation(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return p
+}
+
+func TestRouterRoute(t *testing.T) {
+	direct := []uint64{1, 2, 4}
+	around := []uint64{1, 2, 3, 5, 4}
+	testCases := []struct {
+		name        string
+		profile     string
+		side        []Tag
+		restriction []Tag
+		nodes       []uint64
+		ways        []uint64
+	}{
+		{name: "shortest", profile: "car", nodes: direct, ways: []uint64{10, 11}},
+		{name: "oneway", profile: "car", side: []Tag{Tag{Key: "oneway", Value: "-1"}}, nodes: around, ways: []uint64{10, 12}},
+		{name: "oneway for cars", profile: "bike", side: []Tag{Tag{Key: "oneway", Value: "-1"}, Tag{Key: "oneway:bicycle", Value: "no"}}, nodes: direct, ways: []uint64{10, 11}},
+		{name: "no left turn", profile: "car", restriction: []Tag{Tag{Key: "restriction", Value: "no_left_turn"}}, nodes: around, ways: []uint64{10, 12}},
+		{name: "no left turn for cars", profile: "car", restriction: []Tag{Tag{Key: "restriction:motorcar", Value: "no_left_turn"}}, nodes: around, ways: []uint64{10, 12}},
+		{name: "no left turn except cars", profile: "car", restriction: []Tag{Tag{Key: "restriction", Value: "no_left_turn"}, Tag{Key: "except", Value: "bicycle;motorcar"}}, nodes: direct, ways: []uint64{10, 11}},
+		{name: "no left turn on foot", profile: "foot", restriction: []Tag{Tag{Key: "restriction", Value: "no_left_turn"}}, nodes: direct, ways: []uint64{10, 11}},
+		{name: "only left turn", profile: "car", restriction: []Tag{Tag{Key: "restriction", Value: "only_left_turn"}}, nodes: direct, ways: []uint64{10, 11}},
+	}
+	for _, tc := range testCases {
+		p := newTestRoutingPlanet(t, tc.side, tc.restriction)
+		g, err := p.BuildRoutingGraph(nil, ROUTING_PROFILES[tc.profile])
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		r, err := NewRouter(p, g).Route([]float64{0, 0}, []float64{0.0101, 0.0099})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if len(r.Nodes) != len(tc.nodes) || len(r.Ways) != len(tc.ways) {
+			t.Fatalf("%s: expected nodes %v on ways %v, got nodes %v on ways %v.", tc.name, tc.nodes, tc.ways, r.Nodes, r.Ways)
+		}
+		for i := range tc.nodes {
+			if r.Nodes[i] != tc.nodes[i] {
+				t.Fatalf("%s: expected nodes %v, got %v.", tc.name, tc.nodes, r.Nodes)
+			}
+		}
+		for i := range tc.ways {
+			if r.Ways[i] != tc.ways[i] {
+				t.Fatalf("%s: expected ways %v, got %v.", tc.name, tc.ways, r.Ways)
+			}
+		}
+		distance := 0.0
+		for i := 1; i < len(r.Coordinates); i++ {
+			distance += Haversine(r.Coordinates[i-1][0], r.Coordinates[i-1][1], r.Coordinates[i][0], r.Coordinates[i][1])
+		}
+		if math.Abs(r.Distance-distance) > 1e-6 {
+			t.Fatalf("%s: expected distance %v, got %v.", tc.name, distance, r.Distance)
+		}
+	}
+}
+
+func TestRouterRouteDuration(t *testing.T) {
+	p := newTestRoutingPlanet(t, []Tag{}, []Tag{})
+	g, err := p.BuildRoutingGraph(nil, ROUTING_PROFILES["car"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := NewRouter(p, g).Route([]float64{0, 0}, []float64{0.02, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Residential ways without a maxspeed tag are driven at 25 kilometers per hour.
+	if expected := r.Distance / (25 / 3.6); math.Abs(r.Duration-expected) > 1e-6 {
+		t.Fatalf("Expected duration %v, got %v.", expected, r.Duration)
+	}
+}
+
+func TestRouterNoRoute(t *testing.T) {
+	p := newTestRoutingPlanet(t, []Tag{}, []Tag{})
+	g, err := p.BuildRoutingGraph(nil, ROUTING_PROFILES["car"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewRouter(p, g).Route([]float64{0, 0}, []float64{1.01, 1})
+	if err == nil {
+		t.Fatal("Expected an error for points in parts of the graph that are not connected.")
+	}
+}
+
+func TestTurnRestrictionAllows(t *testing.T) {
+	testCases := []struct {
+		name        string
+		restriction *TurnRestriction
+		from        uint64
+		before      uint64
+		to          uint64
+		after       uint64
+		expected    bool
+	}{
+		{name: "no turn onto the to way", restriction: &TurnRestriction{From: 10, Via: 2, To: 11}, from: 10, before: 1, to: 11, after: 4, expected: false},
+		{name: "no turn onto another way", restriction: &TurnRestriction{From: 10, Via: 2, To: 11}, from: 10, before: 1, to: 10, after: 3, expected: true},
+		{name: "no turn from another way", restriction: &TurnRestriction{From: 10, Via: 2, To: 11}, from: 12, before: 5, to: 11, after: 4, expected: true},
+		{name: "only turn onto the to way", restriction: &TurnRestriction{From: 10, Via: 2, To: 11, Only: true}, from: 10, before: 1, to: 11, after: 4, expected: true},
+		{name: "only turn onto another way", restriction: &TurnRestriction{From: 10, Via: 2, To: 11, Only: true}, from: 10, before: 1, to: 10, after: 3, expected: false},
+		{name: "no u-turn", restriction: &TurnRestriction{From: 10, Via: 2, To: 10}, from: 10, before: 1, to: 10, after: 1, expected: false},
+		{name: "no u-turn continuing straight", restriction: &TurnRestriction{From: 10, Via: 2, To: 10}, from: 10, before: 1, to: 10, after: 3, expected: true},
+	}
+	for _, tc := range testCases {
+		if actual := tc.restriction.Allows(tc.from, tc.before, tc.to, tc.after); actual != tc.expected {
+			t.Fatalf("%s: expected %t, got %t.", tc.name, tc.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package osm
+
+// RoutingEdge is an edge of a RoutingGraph, the part of a way between two vertices.
+// The edge can be traveled from source to target if Forward is true, and from target to source if Backward is true.
+type RoutingEdge struct {
+	Id          uint64      // sequential id of the edge, starting at 1
+	WayId       uint64      // id of the way the edge is part of
+	Source      uint64      // id of the node at the start of the edge
+	Target      uint64      // id of the node at the end of the edge
+	Highway     string      // value of the highway tag of the way
+	Length      float64     // length in meters
+	Speed       float64     // speed in kilometers per hour
+	Forward     bool        // the edge can be traveled from source to target
+	Backward    bool        // the edge can be traveled from target to source
+	Nodes       []uint64    // ids of the nodes of the edge in order, including the source and target
+	Coordinates [][]float64 // [longitude, latitude] of each node of the edge, including the source and target
+}
+
+// Duration returns the time in seconds to travel the edge at its speed.
+func (e *RoutingEdge) Duration() float64 {
+	if e.Speed <= 0 {
+		return 0
+	}
+	return e.Length / (e.Speed / 3.6)
+}
@@ -0,0 +1,10 @@
+package osm
+
+// RoutingGraph is a graph of the ways in a planet that are routable by a profile.  See BuildRoutingGraph.
+type RoutingGraph struct {
+	Profile      string             // name of the routing profile
+	Vertices     []*RoutingVertex   // vertices sorted by node id
+	Edges        []*RoutingEdge     // edges in the order of the ways and their nodes
+	Restrictions []*TurnRestriction // turn restrictions that apply to the profile
+	Incomplete   []uint64           // ids of routable ways that were skipped, since they reference nodes missing from the planet
+}
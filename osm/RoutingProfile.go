@@ -0,0 +1,85 @@
+package osm
+
+import (
+	"fmt"
+)
+
+// RoutingProfile describes which ways a mode of travel can use, in which directions, and how fast.
+type RoutingProfile struct {
+	Name             string             // name of the profile, e.g., car, bike, or foot
+	Speeds           map[string]float64 // speed in kilometers per hour by value of the highway tag.  Ways with other values are not routable, unless explicitly allowed.
+	DefaultSpeed     float64            // speed in kilometers per hour of ways with another highway value that explicitly allow the profile, e.g., highway=footway with bicycle=yes
+	UseMaxSpeed      bool               // use the maxspeed tag, if valid, instead of the speed of the highway type
+	Oneway           bool               // respect the oneway tag.  See ParseOneway.
+	OnewayKey        string             // key of a tag that overrides the oneway tag for the profile, e.g., oneway:bicycle
+	AccessKeys       []string           // keys of access tags from the most general to the most specific, e.g., access, vehicle, bicycle
+	RestrictionModes []string           // modes of transport that turn restrictions apply to, e.g., motorcar.  If empty, then turn restrictions are ignored.
+}
+
+// access returns whether the tags allow the profile, and whether the most specific access tag for the profile explicitly allows it.
+// The most specific access tag that is present wins.  Only the values in ACCESS_VALUES allow access.
+func (rp *RoutingProfile) access(tags map[string]interface{}) (bool, bool) {
+	allowed := true
+	explicit := false
+	for i, k := range rp.AccessKeys {
+		v, ok := tags[k]
+		if !ok {
+			continue
+		}
+		if ACCESS_VALUES.Contains(fmt.Sprint(v)) {
+			allowed = true
+			explicit = i > 0 && i == len(rp.AccessKeys)-1
+		} else {
+			allowed = false
+			explicit = false
+		}
+	}
+	return allowed, explicit
+}
+
+// Speed returns the speed in kilometers per hour of a way with the given tags, and false if the way is not routable for the profile.
+func (rp *RoutingProfile) Speed(tags map[string]interface{}) (float64, bool) {
+	allowed, explicit := rp.access(tags)
+	if !allowed {
+		return 0, false
+	}
+	speed, ok := rp.Speeds[fmt.Sprint(tags["highway"])]
+	if !ok {
+		if _, ok := tags["highway"]; ok && explicit && rp.DefaultSpeed > 0 {
+			return rp.DefaultSpeed, true
+		}
+		return 0, false
+	}
+	if rp.UseMaxSpeed {
+		if maxspeed, ok := tags["maxspeed"]; ok {
+			if s, ok := ParseMaxSpeed(fmt.Sprint(maxspeed)); ok {
+				return s, true
+			}
+		}
+	}
+	return speed, true
+}
+
+// Directions returns whether a way with the given tags can be traveled forward, in the direction of its nodes, and backward by the profile.
+func (rp *RoutingProfile) Directions(tags map[string]interface{}) (bool, bool) {
+	if !rp.Oneway {
+		return true, true
+	}
+	if len(rp.OnewayKey) > 0 {
+		if v, ok := tags[rp.OnewayKey]; ok {
+			return ParseOneway(map[string]interface{}{"oneway": v})
+		}
+	}
+	return ParseOneway(tags)
+}
+
+// MaxSpeed returns the highest speed in kilometers per hour of the highway types of the profile.
+func (rp *RoutingProfile) MaxSpeed() float64 {
+	max := rp.DefaultSpeed
+	for _, s := range rp.Speeds {
+		if s > max {
+			max = s
+		}
+	}
+	return max
+}
@@ -0,0 +1,62 @@
+package osm
+
+// ROUTING_PROFILES is the built-in routing profiles by name: car, bike, and foot.
+var ROUTING_PROFILES = map[string]*RoutingProfile{
+	"car": &RoutingProfile{
+		Name:             "car",
+		Speeds:           HIGHWAY_SPEEDS,
+		UseMaxSpeed:      true,
+		Oneway:           true,
+		AccessKeys:       []string{"access", "vehicle", "motor_vehicle", "motorcar"},
+		RestrictionModes: []string{"motorcar", "motor_vehicle", "vehicle"},
+	},
+	"bike": &RoutingProfile{
+		Name: "bike",
+		Speeds: map[string]float64{
+			"cycleway":       18,
+			"primary":        15,
+			"primary_link":   15,
+			"secondary":      16,
+			"secondary_link": 16,
+			"tertiary":       16,
+			"tertiary_link":  16,
+			"unclassified":   16,
+			"residential":    16,
+			"living_street":  10,
+			"service":        14,
+			"road":           14,
+			"track":          12,
+			"path":           12,
+		},
+		DefaultSpeed:     8,
+		Oneway:           true,
+		OnewayKey:        "oneway:bicycle",
+		AccessKeys:       []string{"access", "vehicle", "bicycle"},
+		RestrictionModes: []string{"bicycle", "vehicle"},
+	},
+	"foot": &RoutingProfile{
+		Name: "foot",
+		Speeds: map[string]float64{
+			"footway":        5,
+			"pedestrian":     5,
+			"path":           5,
+			"steps":          2,
+			"cycleway":       5,
+			"track":          5,
+			"living_street":  5,
+			"residential":    5,
+			"service":        5,
+			"unclassified":   5,
+			"road":           5,
+			"tertiary":       5,
+			"tertiary_link":  5,
+			"secondary":      5,
+			"secondary_link": 5,
+			"primary":        5,
+			"primary_link":   5,
+		},
+		DefaultSpeed: 5,
+		Oneway:       false,
+		AccessKeys:   []string{"access", "foot"},
+	},
+}
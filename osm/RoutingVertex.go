@@ -0,0 +1,8 @@
+package osm
+
+// RoutingVertex is a vertex of a RoutingGraph.  Vertices are the nodes where routable ways end or meet.
+type RoutingVertex struct {
+	Id        uint64  // id of the node
+	Longitude float64 // longitude of the node
+	Latitude  float64 // latitude of the node
+}
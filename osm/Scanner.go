@@ -0,0 +1,96 @@
+package osm
+
+import (
+	"io"
+)
+
+// Scanner provides a convenient interface for reading the OSM elements in a stream, similar to bufio.Scanner.
+// Successive calls to Scan step through the elements, which are available as typed *Node, *Way, or *Relation values with their tags.
+// Scanning stops at the end of the stream or at the first error.  For example:
+//
+//	s, err := osm.NewScanner(r, "pbf")
+//	if err != nil {
+//	  return err
+//	}
+//	for s.Scan() {
+//	  if n := s.Node(); n != nil {
+//	    fmt.Println(n.Id, n.Longitude, n.Latitude, n.Tags)
+//	  }
+//	}
+//	if err := s.Err(); err != nil {
+//	  return err
+//	}
+type Scanner struct {
+	decoder ElementDecoder
+	element interface{}
+	err     error
+}
+
+// NewScanner returns a new Scanner reading from r.  The format is one of osm, osc, pbf, o5m, o5c, osmjson, geojson, or geojsonl.
+// Compressed streams must be decompressed before they are passed to the Scanner.
+func NewScanner(r io.Reader, format string) (*Scanner, error) {
+	decoder, err := NewElementDecoder(r, format)
+	if err != nil {
+		return nil, err
+	}
+	return &Scanner{decoder: decoder}, nil
+}
+
+// Scan advances the Scanner to the next element, which will then be available through the Element, Node, Way, and Relation methods.
+// Returns false when the scan stops, either by reaching the end of the stream or an error.
+func (s *Scanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	element, err := s.decoder.Decode()
+	if err != nil {
+		s.element = nil
+		s.err = err
+		return false
+	}
+	s.element = element
+	return true
+}
+
+// Element returns the most recent element scanned as a *Node, *Way, or *Relation.
+func (s *Scanner) Element() interface{} {
+	return s.element
+}
+
+// Node returns the most recent element scanned if it is a node, otherwise nil.
+func (s *Scanner) Node() *Node {
+	n, _ := s.element.(*Node)
+	return n
+}
+
+// Way returns the most recent element scanned if it is a way, otherwise nil.
+func (s *Scanner) Way() *Way {
+	w, _ := s.element.(*Way)
+	return w
+}
+
+// Relation returns the most recent element scanned if it is a relation, otherwise nil.
+func (s *Scanner) Relation() *Relation {
+	r, _ := s.element.(*Relation)
+	return r
+}
+
+// Deleted returns true if the most recent element scanned is a deletion in an osc or o5c change file.
+// A deleted element only has its id and version set.
+func (s *Scanner) Deleted() bool {
+	return IsDeletion(s.decoder)
+}
+
+// Header sets the planet's version, generator, timestamp, and bounds from the header of the stream.
+// The header is available after the first call to Scan.
+func (s *Scanner) Header(p *Planet) {
+	p.ImportHeader(s.decoder)
+}
+
+// Err returns the first error that was encountered by the Scanner, except for io.EOF.
+func (s *Scanner) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
@@ -0,0 +1,109 @@
+package osm
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+// encodeTestPlanet returns the test planet encoded in the given format.
+func encodeTestPlanet(t *testing.T, format string) io.Reader {
+	p := newTestPlanet(t)
+	buf := new(bytes.Buffer)
+	e, err := NewElementEncoder(buf, p, newTestOutput(), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = MarshalElements(e, p.Nodes, p.Ways, p.Relations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return buf
+}
+
+func TestScanner(t *testing.T) {
+	testCases := []struct {
+		format string
+		reader func(t *testing.T, format string) io.Reader
+	}{
+		{format: "osm", reader: encodeTestPlanet},
+		{format: "pbf", reader: encodeTestPlanet},
+		{format: "o5m", reader: encodeTestPlanet},
+		{format: "o5c", reader: encodeTestPlanet},
+		{format: "osmjson", reader: encodeTestPlanet},
+	}
+	for _, tc := range testCases {
+		s, err := NewScanner(tc.reader(t, tc.format), tc.format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		nodes := make([]*Node, 0)
+		ways := make([]*Way, 0)
+		relations := make([]*Relation, 0)
+		for s.Scan() {
+			if n := s.Node(); n != nil {
+				nodes = append(nodes, n)
+			} else if w := s.Way(); w != nil {
+				ways = append(ways, w)
+			} else if r := s.Relation(); r != nil {
+				relations = append(relations, r)
+			}
+		}
+		if err := s.Err(); err != nil {
+			t.Fatalf("%s: %v", tc.format, err)
+		}
+
+		if len(nodes) != 2 || len(ways) != 1 || len(relations) != 1 {
+			t.Fatalf("%s: expected 2 nodes, 1 way, and 1 relation, got %d nodes, %d ways, and %d relations.", tc.format, len(nodes), len(ways), len(relations))
+		}
+		if n := nodes[0]; n.Id != 1 || n.Longitude != -77.0365 || n.Latitude != 38.8977 {
+			t.Fatalf("%s: expected node 1 at -77.0365,38.8977, got node %d at %v,%v.", tc.format, n.Id, n.Longitude, n.Latitude)
+		}
+		if n := nodes[1]; len(n.Tags) != 1 || n.Tags[0].Key != "name" || n.Tags[0].Value != "Café \"Zoë\"" {
+			t.Fatalf("%s: expected node 2 to be named Café \"Zoë\", got %v.", tc.format, n.Tags)
+		}
+		if w := ways[0]; w.Id != 10 || len(w.NodeReferences) != 2 || w.NodeReferences[0].Reference != 2 {
+			t.Fatalf("%s: expected way 10 starting at node 2, got way %d with %v.", tc.format, w.Id, w.NodeReferences)
+		}
+		if r := relations[0]; r.Id != 20 || len(r.Members) != 2 || r.Members[0].Type != "way" || r.Members[0].Role != "forward" {
+			t.Fatalf("%s: expected relation 20 starting with forward way, got relation %d with %v.", tc.format, r.Id, r.Members)
+		}
+
+		if s.Scan() {
+			t.Fatalf("%s: expected the scan to stop at the end of the stream.", tc.format)
+		}
+	}
+}
+
+func TestScannerUnknownFormat(t *testing.T) {
+	_, err := NewScanner(new(bytes.Buffer), "shp")
+	if err == nil {
+		t.Fatal("Expected an error for an unknown format.")
+	}
+}
+
+func TestScannerChange(t *testing.T) {
+	s, err := NewScanner(bytes.NewReader([]byte(testChange)), "osc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted := make([]bool, 0)
+	for s.Scan() {
+		deleted = append(deleted, s.Deleted())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []bool{false, false, false, false, false, false, true, true}
+	if len(deleted) != len(expected) {
+		t.Fatalf("Expected %d elements, got %d.", len(expected), len(deleted))
+	}
+	for i := range expected {
+		if deleted[i] != expected[i] {
+			t.Fatalf("Expected deletion of element %d to be %t, got %t.", i, expected[i], deleted[i])
+		}
+	}
+}
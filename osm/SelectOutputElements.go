@@ -0,0 +1,90 @@
+package osm
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+// SelectOutputElements returns the nodes, ways, and relations in the planet that are written to the output.
+// Nodes and ways referenced by a kept relation and nodes referenced by a kept way are always selected,
+// so the output remains referentially complete.
+// If the output converts ways to nodes, then the converted nodes are appended to the selected nodes.
+// Ways referenced by a kept relation are never converted.
+// Returns the selected nodes, ways, and relations, and an error if any.
+func SelectOutputElements(planet *Planet, output *Output, dfl_cache *dfl.Cache) ([]*Node, []*Way, []*Relation, error) {
+
+	nodes := make([]*Node, 0)
+	ways := make([]*Way, 0)
+	relations := make([]*Relation, 0)
+
+	set_relation_nodes := NewUInt64Set()
+	set_relation_ways := NewUInt64Set()
+	if !output.DropRelations {
+		var err error
+		relations, set_relation_nodes, set_relation_ways, err = SelectRelations(planet.Relations, func(r *Relation) (bool, error) {
+			return KeepRelation(planet, output.Filter, r, dfl_cache)
+		})
+		if err != nil {
+			return nodes, ways, relations, err
+		}
+	}
+
+	uid := planet.maxId
+	set_way_nodes := NewUInt64Set()
+	way_nodes := make([]*Node, 0)
+	for _, w := range planet.Ways {
+		referenced := set_relation_ways.Contains(w.Id)
+		if output.DropWays && !referenced {
+			continue
+		}
+		keep := referenced
+		if !keep {
+			var err error
+			keep, err = KeepWay(planet, output.Filter, w, dfl_cache)
+			if err != nil {
+				return nodes, ways, relations, err
+			}
+		}
+		if keep {
+			if output.WaysToNodes && !referenced {
+				uid += 1
+				n, err := planet.ConvertWayToNode(w, uid)
+				if err != nil {
+					return nodes, ways, relations, errors.Wrap(err, "Error converting way to node.")
+				}
+				way_nodes = append(way_nodes, n)
+			} else {
+				ways = append(ways, w)
+				for _, nr := range w.NodeReferences {
+					set_way_nodes.Add(nr.Reference)
+				}
+			}
+		}
+	}
+	slice_way_nodes := set_way_nodes.Slice(true)
+
+	for _, n := range planet.Nodes {
+		keep := true
+		if !slice_way_nodes.Contains(n.Id) && !set_relation_nodes.Contains(n.Id) {
+			if output.DropNodes {
+				keep = false
+			} else {
+				var err error
+				keep, err = KeepNode(planet, output.Filter, n, dfl_cache)
+				if err != nil {
+					return nodes, ways, relations, err
+				}
+			}
+		}
+		if keep {
+			nodes = append(nodes, n)
+		}
+	}
+
+	nodes = append(nodes, way_nodes...)
+
+	return nodes, ways, relations, nil
+}
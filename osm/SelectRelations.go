@@ -0,0 +1,57 @@
+package osm
+
+// SelectRelations returns the relations that are kept and the relations nested within them, in their original order.
+// Also returns the sets of node and way ids referenced by the selected relations,
+// so the members can be kept with the relations and the output remains referentially complete.
+// Returns an error if any.
+func SelectRelations(relations []*Relation, keep func(r *Relation) (bool, error)) ([]*Relation, UInt64Set, UInt64Set, error) {
+
+	set_relation_nodes := NewUInt64Set()
+	set_relation_ways := NewUInt64Set()
+
+	relationsIndex := map[uint64]int{}
+	for i, r := range relations {
+		relationsIndex[r.Id] = i
+	}
+
+	set_relations := NewUInt64Set()
+	queue := make([]*Relation, 0)
+	for _, r := range relations {
+		ok, err := keep(r)
+		if err != nil {
+			return make([]*Relation, 0), set_relation_nodes, set_relation_ways, err
+		}
+		if ok {
+			set_relations.Add(r.Id)
+			queue = append(queue, r)
+		}
+	}
+
+	for i := 0; i < len(queue); i++ {
+		for _, m := range queue[i].Members {
+			switch m.Type {
+			case "node":
+				set_relation_nodes.Add(m.Reference)
+			case "way":
+				set_relation_ways.Add(m.Reference)
+			case "relation":
+				if set_relations.Contains(m.Reference) {
+					continue
+				}
+				if j, ok := relationsIndex[m.Reference]; ok {
+					set_relations.Add(m.Reference)
+					queue = append(queue, relations[j])
+				}
+			}
+		}
+	}
+
+	selected := make([]*Relation, 0, len(set_relations))
+	for _, r := range relations {
+		if set_relations.Contains(r.Id) {
+			selected = append(selected, r)
+		}
+	}
+
+	return selected, set_relation_nodes, set_relation_ways, nil
+}
@@ -0,0 +1,155 @@
+package osm
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+// Server is an http.Handler that answers queries against a planet loaded into memory.
+// The planet must not be modified while the server is running.
+//
+// GET /features returns the elements that pass a filter built from the query parameters:
+//	- bbox: minx,miny,maxx,maxy.  Only returns elements whose bounding boxes intersect the bbox, and the members of relations that intersect it.
+//	- dfl: DFL filter expression, e.g., @amenity like cafe
+//	- keys_keep: comma-separated list of keys.  Only returns elements with at least one of the keys.
+//	- keys_drop: comma-separated list of keys.  Only returns elements without any of the keys.
+//	- relation_types: comma-separated list of the types of relations converted into features.  Defaults to the server's relation types.
+//	- format: geojson (default), geojsonl, or osm
+type Server struct {
+	Planet        *Planet          // the planet to query
+	Functions     *dfl.FunctionMap // functions available to DFL expressions
+	RelationTypes []string         // default types of relations converted into features
+}
+
+// NewServer returns a new Server for the planet and builds the planet's R-tree.
+func NewServer(planet *Planet, funcs *dfl.FunctionMap, relation_types []string) *Server {
+	planet.BuildRtree()
+	return &Server{
+		Planet:        planet,
+		Functions:     funcs,
+		RelationTypes: relation_types,
+	}
+}
+
+// ServeHTTP answers a request.  Returns status 400 for invalid query parameters and 404 for unknown paths.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	if r.URL.Path != "/features" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method "+r.Method+" is not allowed.", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+
+	format := q.Get("format")
+	if len(format) == 0 {
+		format = "geojson"
+	}
+	if format != "geojson" && format != "geojsonl" && format != "osm" {
+		http.Error(w, "Unknown format "+format+".  Expecting geojson, geojsonl, or osm.", http.StatusBadRequest)
+		return
+	}
+
+	bbox, err := ParseSliceFloat64(q.Get("bbox"))
+	if err != nil || (len(bbox) != 0 && len(bbox) != 4) {
+		http.Error(w, "Invalid bbox "+q.Get("bbox")+".  Expecting minx,miny,maxx,maxy.", http.StatusBadRequest)
+		return
+	}
+
+	keys_keep := ParseSliceString(q.Get("keys_keep"))
+	keys_drop := ParseSliceString(q.Get("keys_drop"))
+	if len(keys_keep) > 0 && len(keys_drop) > 0 {
+		http.Error(w, "keys_keep and keys_drop are mutually exclusive.", http.StatusBadRequest)
+		return
+	}
+
+	fi := NewFilter(keys_keep, keys_drop, q.Get("dfl"), false, bbox, "", "")
+	err = fi.Init(map[string]interface{}{}, s.Functions)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	relation_types := s.RelationTypes
+	if v := q.Get("relation_types"); len(v) > 0 {
+		relation_types = ParseSliceString(v)
+	}
+
+	planet := s.Planet
+	if fi.HasMaxExtent() {
+		nodes, ways, relations, err := planet.QueryBBox(*fi.MaxExtent)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		planet = planet.SubPlanet(nodes, ways, relations)
+	}
+
+	output := &Output{
+		PlanetResource: &PlanetResource{
+			FilteredResource: &FilteredResource{
+				Resource: &Resource{Uri: r.URL.String()},
+				Filter:   fi,
+			},
+		},
+		RelationTypes: relation_types,
+	}
+
+	switch format {
+	case "geojson":
+		fc, _, _, err := planet.GetFeatureCollection(output)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		b, err := json.Marshal(fc)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/geo+json")
+		w.Write(b)
+	case "geojsonl":
+		features, _, _, err := planet.GetFeatures(output)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		lines := make([]string, 0, len(features))
+		for _, f := range features {
+			b, err := json.Marshal(f)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			lines = append(lines, string(b)+"\n")
+		}
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Write([]byte(strings.Join(lines, "")))
+	case "osm":
+		nodes, ways, relations, err := SelectOutputElements(planet, output, nil)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		encoder, err := NewElementEncoder(w, planet, output, "osm")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// Once the header is written, errors can no longer change the status code.
+		MarshalElements(encoder, nodes, ways, relations)
+	}
+}
@@ -0,0 +1,88 @@
+package osm
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+// newTestServer returns a test server for a planet with a cafe at 0.5,0.5 and a park at 5,5.
+func newTestServer(t *testing.T) *httptest.Server {
+	p := NewPlanet()
+	for _, x := range []struct {
+		id    uint64
+		lon   float64
+		lat   float64
+		key   string
+		value string
+	}{
+		{id: 1, lon: 0.5, lat: 0.5, key: "amenity", value: "cafe"},
+		{id: 2, lon: 5, lat: 5, key: "leisure", value: "park"},
+	} {
+		n := &Node{Longitude: x.lon, Latitude: x.lat}
+		n.Id = x.id
+		n.TagsIndex = p.AddTags([]Tag{Tag{Key: x.key, Value: x.value}})
+		err := p.AddNode(n)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	funcs := dfl.NewFuntionMapWithDefaults()
+	return httptest.NewServer(NewServer(p, &funcs, DEFAULT_RELATION_TYPES))
+}
+
+func TestServerBBox(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/features?bbox=0,0,1,1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d.", http.StatusOK, res.StatusCode)
+	}
+	if ct := res.Header.Get("Content-Type"); ct != "application/geo+json" {
+		t.Fatalf("Expected content type application/geo+json, got %s.", ct)
+	}
+
+	fc := struct {
+		Features []struct {
+			Properties map[string]interface{} `json:"properties"`
+		} `json:"features"`
+	}{}
+	err = json.NewDecoder(res.Body).Decode(&fc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fc.Features) != 1 {
+		t.Fatalf("Expected 1 feature in the bbox, got %d.", len(fc.Features))
+	}
+	if v := fc.Features[0].Properties["amenity"]; v != "cafe" {
+		t.Fatalf("Expected the cafe, got amenity %v.", v)
+	}
+}
+
+func TestServerInvalidBBox(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	res, err := http.Get(ts.URL + "/features?bbox=0,0,1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusBadRequest {
+		b, _ := ioutil.ReadAll(res.Body)
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusBadRequest, res.StatusCode, string(b))
+	}
+}
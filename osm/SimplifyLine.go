@@ -0,0 +1,57 @@
+package osm
+
+import (
+	"math"
+)
+
+// simplifyLine simplifies a line or closed ring with the Douglas-Peucker algorithm.
+// Points closer than tolerance to the simplified line are removed.  The first and last points are always kept.
+// If tolerance is not positive, then returns the line unchanged.
+func simplifyLine(line [][]float64, tolerance float64) [][]float64 {
+	if tolerance <= 0 || len(line) < 3 {
+		return line
+	}
+
+	keep := make([]bool, len(line))
+	keep[0] = true
+	keep[len(line)-1] = true
+
+	stack := [][]int{[]int{0, len(line) - 1}}
+	for len(stack) > 0 {
+		first, last := stack[len(stack)-1][0], stack[len(stack)-1][1]
+		stack = stack[:len(stack)-1]
+
+		max_distance := 0.0
+		index := -1
+		for i := first + 1; i < last; i++ {
+			d := segmentDistance(line[i], line[first], line[last])
+			if d > max_distance {
+				max_distance = d
+				index = i
+			}
+		}
+		if index != -1 && max_distance > tolerance {
+			keep[index] = true
+			stack = append(stack, []int{first, index}, []int{index, last})
+		}
+	}
+
+	simplified := make([][]float64, 0, len(line))
+	for i, c := range line {
+		if keep[i] {
+			simplified = append(simplified, c)
+		}
+	}
+	return simplified
+}
+
+// segmentDistance returns the distance from point p to the segment from a to b.
+func segmentDistance(p []float64, a []float64, b []float64) float64 {
+	dx := b[0] - a[0]
+	dy := b[1] - a[1]
+	if dx == 0 && dy == 0 {
+		return math.Hypot(p[0]-a[0], p[1]-a[1])
+	}
+	t := math.Max(0, math.Min(1, ((p[0]-a[0])*dx+(p[1]-a[1])*dy)/(dx*dx+dy*dy)))
+	return math.Hypot(p[0]-(a[0]+t*dx), p[1]-(a[1]+t*dy))
+}
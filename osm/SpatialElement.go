@@ -0,0 +1,32 @@
+package osm
+
+import (
+	"github.com/dhconnelly/rtreego"
+)
+
+// SpatialElement is a way or relation in the planet's R-tree, indexed by its bounding box.
+// Nodes are inserted into the R-tree directly.
+type SpatialElement struct {
+	Element interface{}   // the *Way or *Relation
+	Extent  *Bounds       // the bounding box of the element
+	rect    *rtreego.Rect // the bounding box as a rectangle for the R-tree
+}
+
+// Bounds returns the bounding box of the element as a rectangle for the R-tree.
+func (e *SpatialElement) Bounds() *rtreego.Rect {
+	return e.rect
+}
+
+// NewSpatialElement returns a new SpatialElement for the *Way or *Relation with the given bounding box.
+// Returns an error if the bounding box cannot be a rectangle.  See Bounds.Rect.
+func NewSpatialElement(element interface{}, extent *Bounds) (*SpatialElement, error) {
+	rect, err := extent.Rect()
+	if err != nil {
+		return nil, err
+	}
+	return &SpatialElement{
+		Element: element,
+		Extent:  extent,
+		rect:    rect,
+	}, nil
+}
@@ -0,0 +1,222 @@
+package osm
+
+import (
+	"io"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/spatialcurrent/go-composite-logger/compositelogger"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+// STREAM_CACHE_SIZE is the number of elements streamed before the tags and user names cached in the scratch planet are cleared.
+const STREAM_CACHE_SIZE = 10000
+
+// StreamPlanet decodes the elements from the inputs one at a time and pushes them through the filters directly to the encoders of the outputs,
+// without loading the planet into memory.  Tags and user names are cached in a scratch planet that is cleared periodically, so memory use is constant.
+// The inputs must already be open.  Elements are not deduplicated across inputs.
+// Returns an error if any input cannot be streamed to any output.  See ValidateStream.
+func StreamPlanet(inputs []*Input, outputs []*Output, logger *compositelogger.CompositeLogger) error {
+
+	for _, input := range inputs {
+		for _, output := range outputs {
+			err := ValidateStream(input, output)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	p := NewPlanet()
+
+	writers := make([]*OutputWriter, 0, len(outputs))
+	encoders := make([]ElementEncoder, 0, len(outputs))
+	output_caches := make([]*dfl.Cache, 0, len(outputs))
+	for _, output := range outputs {
+		w, format, err := OpenOutputWriter(output)
+		if err != nil {
+			return err
+		}
+		e, err := NewElementEncoder(w, p, output, format)
+		if err != nil {
+			return err
+		}
+		writers = append(writers, w)
+		encoders = append(encoders, e)
+		if output.Filter != nil && output.Filter.HasExpression() && output.Filter.UseCache {
+			output_caches = append(output_caches, dfl.NewCache())
+		} else {
+			output_caches = append(output_caches, nil)
+		}
+	}
+
+	writeHeaders := func() error {
+		for i, e := range encoders {
+			err := e.WriteHeader()
+			if err != nil {
+				return errors.Wrap(err, "Error encoding header for output "+outputs[i].Uri)
+			}
+		}
+		return nil
+	}
+
+	header := false
+	count := 0
+	max_id := uint64(0) // largest id of the elements streamed, so the ids of GeoJSON inputs do not collide with previous inputs
+	for _, input := range inputs {
+
+		var dfl_cache *dfl.Cache
+		if input.Filter != nil && input.Filter.HasExpression() && input.Filter.UseCache {
+			dfl_cache = dfl.NewCache()
+		}
+
+		decoder, err := NewInputDecoder(input, max_id+1)
+		if err != nil {
+			return err
+		}
+
+		for {
+			element, err := decoder.Decode()
+			if err != nil {
+				if err == io.EOF {
+					break
+				}
+				return errors.Wrap(err, "Error decoding "+input.Format+" from input "+input.Uri)
+			}
+
+			if id := elementId(element); id > max_id {
+				max_id = id
+			}
+
+			if IsDeletion(decoder) {
+				continue
+			}
+
+			if !header {
+				p.ImportHeader(decoder)
+				err := writeHeaders()
+				if err != nil {
+					return err
+				}
+				header = true
+			}
+
+			count += 1
+			if count%STREAM_CACHE_SIZE == 0 {
+				p.Tags = NewTagsCache()
+				p.UserNames = map[uint64]string{}
+			}
+
+			switch e := element.(type) {
+			case *Node:
+				if input.DropNodes {
+					continue
+				}
+				p.ImportTaggedElement(input, &e.TaggedElement)
+				keep, err := KeepNode(p, input.Filter, e, dfl_cache)
+				if err != nil {
+					return err
+				}
+				if !keep {
+					continue
+				}
+				for i, output := range outputs {
+					if output.DropNodes {
+						continue
+					}
+					keep, err := KeepNode(p, output.Filter, e, output_caches[i])
+					if err != nil {
+						return err
+					}
+					if keep {
+						err := encoders[i].EncodeNode(e)
+						if err != nil {
+							return errors.Wrap(err, "Error marshalling node to output "+output.Uri)
+						}
+					}
+				}
+			case *Way:
+				if input.DropWays {
+					continue
+				}
+				p.ImportTaggedElement(input, &e.TaggedElement)
+				keep, err := KeepWay(p, input.Filter, e, dfl_cache)
+				if err != nil {
+					return err
+				}
+				if !keep {
+					continue
+				}
+				for i, output := range outputs {
+					if output.DropWays {
+						continue
+					}
+					keep, err := KeepWay(p, output.Filter, e, output_caches[i])
+					if err != nil {
+						return err
+					}
+					if keep {
+						err := encoders[i].EncodeWay(e)
+						if err != nil {
+							return errors.Wrap(err, "Error marshalling way to output "+output.Uri)
+						}
+					}
+				}
+			case *Relation:
+				if input.DropRelations {
+					continue
+				}
+				p.ImportTaggedElement(input, &e.TaggedElement)
+				keep, err := KeepRelation(p, input.Filter, e, dfl_cache)
+				if err != nil {
+					return err
+				}
+				if !keep {
+					continue
+				}
+				for i, output := range outputs {
+					if output.DropRelations {
+						continue
+					}
+					keep, err := KeepRelation(p, output.Filter, e, output_caches[i])
+					if err != nil {
+						return err
+					}
+					if keep {
+						err := encoders[i].EncodeRelation(e)
+						if err != nil {
+							return errors.Wrap(err, "Error marshalling relation to output "+output.Uri)
+						}
+					}
+				}
+			}
+		}
+	}
+
+	if !header {
+		err := writeHeaders()
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, e := range encoders {
+		err := e.Close()
+		if err != nil {
+			return errors.Wrap(err, "Error closing encoder for output "+outputs[i].Uri)
+		}
+		err = writers[i].Close()
+		if err != nil {
+			return errors.Wrap(err, "Error closing writer for output "+outputs[i].Uri)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package osm
+
+import (
+	"sort"
+)
+
+// SubPlanet returns a new planet with the given nodes, ways, and relations, and the elements they reference that are in the planet.
+// The nodes of the ways and the members of the relations, including nested relations, are added, so the new planet is referentially complete.
+// The new planet shares the tags cache and user names of the planet.
+func (p *Planet) SubPlanet(nodes []*Node, ways []*Way, relations []*Relation) *Planet {
+
+	s := NewPlanet()
+	s.Version = p.Version
+	s.Generator = p.Generator
+	s.Tags = p.Tags
+	s.UserNames = p.UserNames
+
+	set_relations := NewUInt64Set()
+	set_ways := NewUInt64Set()
+	set_nodes := NewUInt64Set()
+
+	queue := make([]*Relation, 0, len(relations))
+	for _, r := range relations {
+		if !set_relations.Contains(r.Id) {
+			set_relations.Add(r.Id)
+			queue = append(queue, r)
+		}
+	}
+	for i := 0; i < len(queue); i++ {
+		for _, m := range queue[i].Members {
+			switch m.Type {
+			case "node":
+				set_nodes.Add(m.Reference)
+			case "way":
+				set_ways.Add(m.Reference)
+			case "relation":
+				if set_relations.Contains(m.Reference) {
+					continue
+				}
+				if j, ok := p.relationsIndex[m.Reference]; ok {
+					set_relations.Add(m.Reference)
+					queue = append(queue, p.Relations[j])
+				}
+			}
+		}
+	}
+
+	for _, w := range ways {
+		set_ways.Add(w.Id)
+	}
+
+	// Elements are added in the order of the planet, so the new planet is in the same order.
+	for _, i := range sortedPositions(set_ways, p.waysIndex) {
+		w := p.Ways[i]
+		s.AddWay(w)
+		for _, nr := range w.NodeReferences {
+			set_nodes.Add(nr.Reference)
+		}
+	}
+
+	for _, n := range nodes {
+		set_nodes.Add(n.Id)
+	}
+
+	for _, i := range sortedPositions(set_nodes, p.nodesIndex) {
+		s.AddNode(p.Nodes[i])
+	}
+
+	for _, i := range sortedPositions(set_relations, p.relationsIndex) {
+		s.AddRelation(p.Relations[i])
+	}
+
+	return s
+}
+
+// sortedPositions returns the sorted positions in the index of the ids in the set.  Ids not in the index are skipped.
+func sortedPositions(set UInt64Set, index map[uint64]int) []int {
+	positions := make([]int, 0, len(set))
+	for id := range set {
+		if i, ok := index[id]; ok {
+			positions = append(positions, i)
+		}
+	}
+	sort.Ints(positions)
+	return positions
+}
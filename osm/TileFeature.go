@@ -0,0 +1,56 @@
+package osm
+
+// The geometry types of features in a Mapbox Vector Tile.
+const (
+	MVT_UNKNOWN    = 0
+	MVT_POINT      = 1
+	MVT_LINESTRING = 2
+	MVT_POLYGON    = 3
+)
+
+// The element types encoded in the last digit of the id of a feature in a vector tile.  See TileFeature.FeatureId.
+const (
+	MVT_ID_NODE     = 1
+	MVT_ID_WAY      = 2
+	MVT_ID_RELATION = 3
+)
+
+// TileFeature is a feature with the geometry as longitude and latitude coordinates, before it is cut into vector tiles.
+// Only one of Points, Lines, or Polygons is set.  Polygons are lists of rings, with the outer ring first.
+type TileFeature struct {
+	Id          uint64                 // id of the element
+	ElementType string                 // type of the element: node, way, or relation
+	Properties  map[string]interface{} // tags of the element
+	Points      [][]float64            // [longitude, latitude] of each point
+	Lines       [][][]float64          // coordinates of each line
+	Polygons    [][][][]float64        // rings of each polygon
+}
+
+// Type returns the geometry type of the feature in a vector tile: MVT_POINT, MVT_LINESTRING, MVT_POLYGON, or MVT_UNKNOWN.
+func (f *TileFeature) Type() int {
+	if len(f.Points) > 0 {
+		return MVT_POINT
+	}
+	if len(f.Lines) > 0 {
+		return MVT_LINESTRING
+	}
+	if len(f.Polygons) > 0 {
+		return MVT_POLYGON
+	}
+	return MVT_UNKNOWN
+}
+
+// FeatureId returns the id of the feature in a vector tile, which is the id of the element times 10 plus the element type,
+// e.g., 1 for nodes, 2 for ways, and 3 for relations, so nodes, ways, and relations with the same id do not collide.
+// Returns 0, which means the feature has no id, if the element type is unknown.
+func (f *TileFeature) FeatureId() uint64 {
+	switch f.ElementType {
+	case "node":
+		return f.Id*10 + MVT_ID_NODE
+	case "way":
+		return f.Id*10 + MVT_ID_WAY
+	case "relation":
+		return f.Id*10 + MVT_ID_RELATION
+	}
+	return 0
+}
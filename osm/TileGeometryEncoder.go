@@ -0,0 +1,110 @@
+package osm
+
+import (
+	"math"
+)
+
+// The commands of the geometry encoding of a Mapbox Vector Tile.
+const (
+	mvtMoveTo    = 1
+	mvtLineTo    = 2
+	mvtClosePath = 7
+)
+
+// tileGeometryEncoder encodes geometries in tile coordinates as the commands and zigzag-encoded deltas of a Mapbox Vector Tile.
+// The cursor carries over between the parts of a geometry, as required by the specification.
+// Coordinates are rounded to integers and repeated points are removed, so parts that collapse at the zoom level are dropped.
+type tileGeometryEncoder struct {
+	x        int64
+	y        int64
+	Geometry []uint64
+}
+
+// command appends a command integer for the command id and the number of times it is repeated.
+func (e *tileGeometryEncoder) command(id int, count int) {
+	e.Geometry = append(e.Geometry, uint64((id&0x7)|(count<<3)))
+}
+
+// moveCursor appends the zigzag-encoded delta from the cursor to the point and moves the cursor.
+func (e *tileGeometryEncoder) moveCursor(p []int64) {
+	dx := p[0] - e.x
+	dy := p[1] - e.y
+	e.Geometry = append(e.Geometry, uint64((dx<<1)^(dx>>63)), uint64((dy<<1)^(dy>>63)))
+	e.x = p[0]
+	e.y = p[1]
+}
+
+// round rounds a line to integer coordinates, removing consecutive repeated points.
+func (e *tileGeometryEncoder) round(line [][]float64) [][]int64 {
+	rounded := make([][]int64, 0, len(line))
+	for _, c := range line {
+		p := []int64{int64(math.Round(c[0])), int64(math.Round(c[1]))}
+		if len(rounded) > 0 && rounded[len(rounded)-1][0] == p[0] && rounded[len(rounded)-1][1] == p[1] {
+			continue
+		}
+		rounded = append(rounded, p)
+	}
+	return rounded
+}
+
+// AddPoints adds the points of a Point or MultiPoint geometry.
+func (e *tileGeometryEncoder) AddPoints(points [][]float64) {
+	if len(points) == 0 {
+		return
+	}
+	e.command(mvtMoveTo, len(points))
+	for _, c := range points {
+		e.moveCursor([]int64{int64(math.Round(c[0])), int64(math.Round(c[1]))})
+	}
+}
+
+// AddLine adds a part of a LineString or MultiLineString geometry.  Lines with less than 2 distinct points are dropped.
+func (e *tileGeometryEncoder) AddLine(line [][]float64) {
+	rounded := e.round(line)
+	if len(rounded) < 2 {
+		return
+	}
+	e.command(mvtMoveTo, 1)
+	e.moveCursor(rounded[0])
+	e.command(mvtLineTo, len(rounded)-1)
+	for _, p := range rounded[1:] {
+		e.moveCursor(p)
+	}
+}
+
+// AddRing adds a ring of a Polygon or MultiPolygon geometry.
+// Outer rings are wound to have a positive area in tile coordinates, i.e., clockwise on screen, and holes to have a negative area.
+// Rings with less than 3 distinct points or no area are dropped.  Returns true if the ring was added, otherwise false.
+func (e *tileGeometryEncoder) AddRing(ring [][]float64, outer bool) bool {
+	rounded := e.round(ring)
+	if len(rounded) > 1 && rounded[0][0] == rounded[len(rounded)-1][0] && rounded[0][1] == rounded[len(rounded)-1][1] {
+		rounded = rounded[:len(rounded)-1]
+	}
+	if len(rounded) < 3 {
+		return false
+	}
+
+	area := int64(0)
+	for i := range rounded {
+		a := rounded[i]
+		b := rounded[(i+1)%len(rounded)]
+		area += a[0]*b[1] - b[0]*a[1]
+	}
+	if area == 0 {
+		return false
+	}
+	if (area > 0) != outer {
+		for i, j := 0, len(rounded)-1; i < j; i, j = i+1, j-1 {
+			rounded[i], rounded[j] = rounded[j], rounded[i]
+		}
+	}
+
+	e.command(mvtMoveTo, 1)
+	e.moveCursor(rounded[0])
+	e.command(mvtLineTo, len(rounded)-1)
+	for _, p := range rounded[1:] {
+		e.moveCursor(p)
+	}
+	e.command(mvtClosePath, 1)
+	return true
+}
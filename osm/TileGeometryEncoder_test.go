@@ -0,0 +1,84 @@
+package osm
+
+import (
+	"testing"
+)
+
+func TestTileGeometryEncoder(t *testing.T) {
+	// The expected commands are the examples of the Mapbox Vector Tile specification, version 2.1, section 4.3.5.
+	testCases := []struct {
+		name     string
+		add      func(e *tileGeometryEncoder)
+		expected []uint64
+	}{
+		{
+			name:     "point",
+			add:      func(e *tileGeometryEncoder) { e.AddPoints([][]float64{[]float64{25, 17}}) },
+			expected: []uint64{9, 50, 34},
+		},
+		{
+			name:     "multipoint",
+			add:      func(e *tileGeometryEncoder) { e.AddPoints([][]float64{[]float64{5, 7}, []float64{3, 2}}) },
+			expected: []uint64{17, 10, 14, 3, 9},
+		},
+		{
+			name:     "linestring",
+			add:      func(e *tileGeometryEncoder) { e.AddLine([][]float64{[]float64{2, 2}, []float64{2, 10}, []float64{10, 10}}) },
+			expected: []uint64{9, 4, 4, 18, 0, 16, 16, 0},
+		},
+		{
+			name: "multilinestring",
+			add: func(e *tileGeometryEncoder) {
+				e.AddLine([][]float64{[]float64{2, 2}, []float64{2, 10}, []float64{10, 10}})
+				e.AddLine([][]float64{[]float64{1, 1}, []float64{3, 5}})
+			},
+			expected: []uint64{9, 4, 4, 18, 0, 16, 16, 0, 9, 17, 17, 10, 4, 8},
+		},
+		{
+			name: "polygon",
+			add: func(e *tileGeometryEncoder) {
+				e.AddRing([][]float64{[]float64{3, 6}, []float64{8, 12}, []float64{20, 34}, []float64{3, 6}}, true)
+			},
+			expected: []uint64{9, 6, 12, 18, 10, 12, 24, 44, 15},
+		},
+		{
+			name: "polygon wound the other way",
+			add: func(e *tileGeometryEncoder) {
+				e.AddRing([][]float64{[]float64{20, 34}, []float64{8, 12}, []float64{3, 6}, []float64{20, 34}}, true)
+			},
+			expected: []uint64{9, 6, 12, 18, 10, 12, 24, 44, 15},
+		},
+		{
+			name: "polygon with a hole",
+			add: func(e *tileGeometryEncoder) {
+				e.AddRing([][]float64{[]float64{11, 11}, []float64{20, 11}, []float64{20, 20}, []float64{11, 20}, []float64{11, 11}}, true)
+				e.AddRing([][]float64{[]float64{13, 13}, []float64{13, 17}, []float64{17, 17}, []float64{17, 13}, []float64{13, 13}}, false)
+			},
+			expected: []uint64{9, 22, 22, 26, 18, 0, 0, 18, 17, 0, 15, 9, 4, 13, 26, 0, 8, 8, 0, 0, 7, 15},
+		},
+		{
+			name:     "collapsed line",
+			add:      func(e *tileGeometryEncoder) { e.AddLine([][]float64{[]float64{2, 2}, []float64{2.2, 1.9}}) },
+			expected: []uint64{},
+		},
+		{
+			name: "collapsed ring",
+			add: func(e *tileGeometryEncoder) {
+				e.AddRing([][]float64{[]float64{1, 1}, []float64{2, 2}, []float64{3, 3}, []float64{1, 1}}, true)
+			},
+			expected: []uint64{},
+		},
+	}
+	for _, tc := range testCases {
+		e := &tileGeometryEncoder{}
+		tc.add(e)
+		if len(e.Geometry) != len(tc.expected) {
+			t.Fatalf("%s: expected %v, got %v.", tc.name, tc.expected, e.Geometry)
+		}
+		for i := range tc.expected {
+			if e.Geometry[i] != tc.expected[i] {
+				t.Fatalf("%s: expected %v, got %v.", tc.name, tc.expected, e.Geometry)
+			}
+		}
+	}
+}
@@ -0,0 +1,8 @@
+package osm
+
+// TileWriter is an interface for writing encoded tiles to a tileset, such as a directory tree or an MBTiles file.
+// Tiles are addressed with XYZ coordinates, where y increases to the south.
+type TileWriter interface {
+	WriteTile(z int, x int, y int, data []byte) error
+	Close() error
+}
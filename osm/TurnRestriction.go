@@ -0,0 +1,96 @@
+package osm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TurnRestriction is a restriction on turning from one way to another at a node, from a type=restriction relation.
+// A "no" restriction forbids turning from the from way onto the to way at the via node.
+// An "only" restriction forbids turning from the from way onto any way other than the to way at the via node.
+type TurnRestriction struct {
+	Id   uint64 // id of the relation
+	From uint64 // id of the way the restriction applies when arriving from
+	Via  uint64 // id of the node where the turn happens
+	To   uint64 // id of the way the restriction forbids or requires
+	Only bool   // true for an "only" restriction, false for a "no" restriction
+}
+
+// Allows returns true if the restriction allows turning at the via node from a way onto another way, otherwise false.
+// The turn arrives from the before node on the from way and leaves to the after node on the to way.
+// If the restriction is from a way onto the same way, e.g., no_u_turn, then the turn is onto the to way only if it goes back the way it came,
+// i.e., the node after the via node is the node before it, so continuing along the way in the direction of travel is not a U-turn.
+func (tr *TurnRestriction) Allows(from uint64, before uint64, to uint64, after uint64) bool {
+	if from != tr.From {
+		return true
+	}
+	onto := to == tr.To
+	if tr.From == tr.To {
+		onto = onto && after == before
+	}
+	if tr.Only {
+		return onto
+	}
+	return !onto
+}
+
+// TurnRestriction returns the turn restriction described by a type=restriction relation for the profile, and false if the relation is not a restriction that applies to the profile.
+// Uses the restriction:<mode> tag for the modes of the profile, if any, otherwise the restriction tag, and skips restrictions where the except tag lists a mode of the profile.
+// Only restrictions with a single from way, via node, and to way are supported.
+func (p *Planet) TurnRestriction(r *Relation, profile *RoutingProfile) (*TurnRestriction, bool) {
+
+	if len(profile.RestrictionModes) == 0 || p.RelationType(r) != "restriction" {
+		return nil, false
+	}
+
+	tags := p.Tags.Map(r.TagsIndex)
+
+	if except, ok := tags["except"]; ok {
+		for _, mode := range strings.Split(fmt.Sprint(except), ";") {
+			if stringSliceContains(profile.RestrictionModes, strings.TrimSpace(mode)) {
+				return nil, false
+			}
+		}
+	}
+
+	value := ""
+	if v, ok := tags["restriction"]; ok {
+		value = fmt.Sprint(v)
+	}
+	for _, mode := range profile.RestrictionModes {
+		if v, ok := tags["restriction:"+mode]; ok {
+			value = fmt.Sprint(v)
+			break
+		}
+	}
+
+	tr := &TurnRestriction{Id: r.Id}
+	if strings.HasPrefix(value, "only_") {
+		tr.Only = true
+	} else if !strings.HasPrefix(value, "no_") {
+		return nil, false
+	}
+
+	from, via, to := 0, 0, 0
+	for _, m := range r.Members {
+		switch {
+		case m.Role == "from" && m.Type == "way":
+			tr.From = m.Reference
+			from += 1
+		case m.Role == "via" && m.Type == "node":
+			tr.Via = m.Reference
+			via += 1
+		case m.Role == "via":
+			// Restrictions via ways are not supported.
+			return nil, false
+		case m.Role == "to" && m.Type == "way":
+			tr.To = m.Reference
+			to += 1
+		}
+	}
+	if from != 1 || via != 1 || to != 1 {
+		return nil, false
+	}
+
+	return tr, true
+}
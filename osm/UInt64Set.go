@@ -9,6 +9,12 @@ func (set UInt64Set) Add(x uint64) {
 	set[x] = struct{}{}
 }
 
+// Contains returns true if the set contains parameter x.
+func (set UInt64Set) Contains(x uint64) bool {
+	_, ok := set[x]
+	return ok
+}
+
 // Slice returns a slice representation of this set.
 // If parameter sorted is true, then sorts the values using natural sort order.
 func (set UInt64Set) Slice(sorted bool) UInt64Slice {
@@ -0,0 +1,69 @@
+package osm
+
+import (
+	"io"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// UnmarshalHistory reads every version of the elements in a full-history input into a new History.
+// The header is imported into the planet, but no elements are added to the planet.
+// The versions keep their tags and attributes, since the version numbers and timestamps are needed to build a snapshot.
+// Deletions in change files are kept as versions that are not visible.
+// Returns the history and an error if any.
+func UnmarshalHistory(p *Planet, input *Input) (*History, error) {
+
+	h := NewHistory()
+
+	decoder, err := NewElementDecoder(input.Reader, input.Format)
+	if err != nil {
+		return h, err
+	}
+
+	for {
+
+		element, err := decoder.Decode()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return h, errors.Wrap(err, "Error decoding "+input.Format)
+		}
+
+		var visible *bool
+		if IsDeletion(decoder) {
+			deleted := false
+			visible = &deleted
+		}
+
+		switch e := element.(type) {
+		case *Node:
+			if !input.DropNodes {
+				if visible != nil {
+					e.Visible = visible
+				}
+				h.AddNode(e)
+			}
+		case *Way:
+			if !input.DropWays {
+				if visible != nil {
+					e.Visible = visible
+				}
+				h.AddWay(e)
+			}
+		case *Relation:
+			if !input.DropRelations {
+				if visible != nil {
+					e.Visible = visible
+				}
+				h.AddRelation(e)
+			}
+		}
+	}
+
+	p.ImportHeader(decoder)
+
+	return h, nil
+}
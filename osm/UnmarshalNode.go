@@ -71,6 +71,12 @@ func UnmarshalNode(decoder *xml.Decoder, e xml.StartElement, input *Input) (*Nod
 				//n.UserName = attr.Value
 				user_name = attr.Value
 			}
+		case "visible":
+			visible, err := strconv.ParseBool(attr.Value)
+			if err != nil {
+				return n, user_id, user_name, tags, errors.Wrap(err, "Error parsing node visible")
+			}
+			n.Visible = &visible
 		case "lat":
 			lat, err := strconv.ParseFloat(attr.Value, 64)
 			if err != nil {
@@ -0,0 +1,106 @@
+package osm
+
+import (
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// UnmarshalPBFHeaderBlock decodes a HeaderBlock message from an OSM PBF file.
+// Returns the decoded header, and an error if any.
+func UnmarshalPBFHeaderBlock(data []byte) (*PBFHeader, error) {
+	h := &PBFHeader{
+		RequiredFeatures: make([]string, 0),
+		OptionalFeatures: make([]string, 0),
+	}
+
+	r := newProtoReader(data)
+	for r.More() {
+		field, wireType, err := r.ReadKey()
+		if err != nil {
+			return h, errors.Wrap(err, "Error decoding OSM PBF header block")
+		}
+		switch field {
+		case 1:
+			b, err := r.ReadBytes()
+			if err != nil {
+				return h, errors.Wrap(err, "Error decoding OSM PBF header bbox")
+			}
+			bounds, err := unmarshalPBFHeaderBBox(b)
+			if err != nil {
+				return h, err
+			}
+			h.Bounds = bounds
+		case 4, 5, 16, 17, 34:
+			b, err := r.ReadBytes()
+			if err != nil {
+				return h, errors.Wrap(err, "Error decoding OSM PBF header string")
+			}
+			switch field {
+			case 4:
+				h.RequiredFeatures = append(h.RequiredFeatures, string(b))
+			case 5:
+				h.OptionalFeatures = append(h.OptionalFeatures, string(b))
+			case 16:
+				h.WritingProgram = string(b)
+			case 17:
+				h.Source = string(b)
+			case 34:
+				h.ReplicationBaseUrl = string(b)
+			}
+		case 32:
+			x, err := r.ReadVarint()
+			if err != nil {
+				return h, errors.Wrap(err, "Error decoding OSM PBF replication timestamp")
+			}
+			ts := time.Unix(int64(x), 0).UTC()
+			h.ReplicationTimestamp = &ts
+		case 33:
+			x, err := r.ReadVarint()
+			if err != nil {
+				return h, errors.Wrap(err, "Error decoding OSM PBF replication sequence number")
+			}
+			h.ReplicationSequence = int64(x)
+		default:
+			err = r.Skip(wireType)
+			if err != nil {
+				return h, errors.Wrap(err, "Error decoding OSM PBF header block")
+			}
+		}
+	}
+
+	return h, nil
+}
+
+// unmarshalPBFHeaderBBox decodes a HeaderBBox message.  Coordinates are in nanodegrees.
+func unmarshalPBFHeaderBBox(data []byte) (*Bounds, error) {
+	values := make([]int64, 4)
+	r := newProtoReader(data)
+	for r.More() {
+		field, wireType, err := r.ReadKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "Error decoding OSM PBF header bbox")
+		}
+		if field >= 1 && field <= 4 {
+			x, err := r.ReadSint64()
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding OSM PBF header bbox")
+			}
+			values[field-1] = x
+		} else {
+			err = r.Skip(wireType)
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding OSM PBF header bbox")
+			}
+		}
+	}
+	// left, right, top, bottom
+	return NewBounds(
+		float64(values[0])/1e9,
+		float64(values[3])/1e9,
+		float64(values[1])/1e9,
+		float64(values[2])/1e9,
+	), nil
+}
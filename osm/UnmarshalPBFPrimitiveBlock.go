@@ -0,0 +1,607 @@
+package osm
+
+import (
+	"strconv"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// pbfBlock holds the string table and coordinate and date precision of a PrimitiveBlock.
+type pbfBlock struct {
+	strings         []string
+	granularity     int64 // granularity of coordinates in nanodegrees
+	latOffset       int64 // latitude offset in nanodegrees
+	lonOffset       int64 // longitude offset in nanodegrees
+	dateGranularity int64 // granularity of timestamps in milliseconds
+}
+
+func (b *pbfBlock) Latitude(lat int64) float64 {
+	return float64(b.latOffset+(b.granularity*lat)) / 1e9
+}
+
+func (b *pbfBlock) Longitude(lon int64) float64 {
+	return float64(b.lonOffset+(b.granularity*lon)) / 1e9
+}
+
+func (b *pbfBlock) Timestamp(ts int64) *time.Time {
+	t := time.Unix(0, ts*b.dateGranularity*int64(time.Millisecond)).UTC()
+	return &t
+}
+
+func (b *pbfBlock) String(i uint64) (string, error) {
+	if i >= uint64(len(b.strings)) {
+		return "", errors.New("String index " + strconv.FormatUint(i, 10) + " is out of range of the block string table with length " + strconv.Itoa(len(b.strings)) + ".")
+	}
+	return b.strings[i], nil
+}
+
+func (b *pbfBlock) Tags(keys []uint64, values []uint64) ([]Tag, error) {
+	if len(keys) != len(values) {
+		return nil, errors.New("Number of tag keys does not match number of tag values.")
+	}
+	tags := make([]Tag, 0, len(keys))
+	for i := range keys {
+		k, err := b.String(keys[i])
+		if err != nil {
+			return tags, err
+		}
+		v, err := b.String(values[i])
+		if err != nil {
+			return tags, err
+		}
+		tags = append(tags, Tag{Key: k, Value: v})
+	}
+	return tags, nil
+}
+
+// UnmarshalPBFPrimitiveBlock decodes a PrimitiveBlock message from an OSM PBF file.
+// Returns a slice of *Node, *Way, and *Relation in the order they appear in the block, and an error if any.
+func UnmarshalPBFPrimitiveBlock(data []byte) ([]interface{}, error) {
+
+	block := &pbfBlock{
+		strings:         make([]string, 0),
+		granularity:     100,
+		latOffset:       0,
+		lonOffset:       0,
+		dateGranularity: 1000,
+	}
+	groups := make([][]byte, 0)
+
+	r := newProtoReader(data)
+	for r.More() {
+		field, wireType, err := r.ReadKey()
+		if err != nil {
+			return nil, errors.Wrap(err, "Error decoding OSM PBF primitive block")
+		}
+		switch field {
+		case 1:
+			b, err := r.ReadBytes()
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding OSM PBF string table")
+			}
+			st := newProtoReader(b)
+			for st.More() {
+				field, wireType, err := st.ReadKey()
+				if err != nil {
+					return nil, errors.Wrap(err, "Error decoding OSM PBF string table")
+				}
+				if field != 1 {
+					err = st.Skip(wireType)
+					if err != nil {
+						return nil, errors.Wrap(err, "Error decoding OSM PBF string table")
+					}
+					continue
+				}
+				s, err := st.ReadBytes()
+				if err != nil {
+					return nil, errors.Wrap(err, "Error decoding OSM PBF string table")
+				}
+				block.strings = append(block.strings, string(s))
+			}
+		case 2:
+			b, err := r.ReadBytes()
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding OSM PBF primitive group")
+			}
+			groups = append(groups, b)
+		case 17, 18, 19, 20:
+			x, err := r.ReadVarint()
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding OSM PBF block granularity")
+			}
+			switch field {
+			case 17:
+				block.granularity = int64(x)
+			case 18:
+				block.dateGranularity = int64(x)
+			case 19:
+				block.latOffset = int64(x)
+			case 20:
+				block.lonOffset = int64(x)
+			}
+		default:
+			err = r.Skip(wireType)
+			if err != nil {
+				return nil, errors.Wrap(err, "Error decoding OSM PBF primitive block")
+			}
+		}
+	}
+
+	elements := make([]interface{}, 0, 8000)
+	for _, g := range groups {
+		gr := newProtoReader(g)
+		for gr.More() {
+			field, wireType, err := gr.ReadKey()
+			if err != nil {
+				return elements, errors.Wrap(err, "Error decoding OSM PBF primitive group")
+			}
+			if field < 1 || field > 4 {
+				err = gr.Skip(wireType)
+				if err != nil {
+					return elements, errors.Wrap(err, "Error decoding OSM PBF primitive group")
+				}
+				continue
+			}
+			b, err := gr.ReadBytes()
+			if err != nil {
+				return elements, errors.Wrap(err, "Error decoding OSM PBF primitive group")
+			}
+			switch field {
+			case 1:
+				n, err := unmarshalPBFNode(b, block)
+				if err != nil {
+					return elements, err
+				}
+				elements = append(elements, n)
+			case 2:
+				elements, err = unmarshalPBFDenseNodes(b, block, elements)
+				if err != nil {
+					return elements, err
+				}
+			case 3:
+				w, err := unmarshalPBFWay(b, block)
+				if err != nil {
+					return elements, err
+				}
+				elements = append(elements, w)
+			case 4:
+				rel, err := unmarshalPBFRelation(b, block)
+				if err != nil {
+					return elements, err
+				}
+				elements = append(elements, rel)
+			}
+		}
+	}
+
+	return elements, nil
+}
+
+// unmarshalPBFInfo decodes an Info message into the element.
+func unmarshalPBFInfo(data []byte, block *pbfBlock, e *Element) error {
+	r := newProtoReader(data)
+	for r.More() {
+		field, wireType, err := r.ReadKey()
+		if err != nil {
+			return errors.Wrap(err, "Error decoding OSM PBF info")
+		}
+		switch field {
+		case 1:
+			x, err := r.ReadVarint()
+			if err != nil {
+				return errors.Wrap(err, "Error decoding OSM PBF info version")
+			}
+			e.Version = uint16(x)
+		case 2:
+			x, err := r.ReadVarint()
+			if err != nil {
+				return errors.Wrap(err, "Error decoding OSM PBF info timestamp")
+			}
+			e.Timestamp = block.Timestamp(int64(x))
+		case 3:
+			x, err := r.ReadVarint()
+			if err != nil {
+				return errors.Wrap(err, "Error decoding OSM PBF info changeset")
+			}
+			e.Changeset = x
+		case 4:
+			x, err := r.ReadVarint()
+			if err != nil {
+				return errors.Wrap(err, "Error decoding OSM PBF info uid")
+			}
+			e.UserId = uint64(uint32(x))
+		case 5:
+			x, err := r.ReadVarint()
+			if err != nil {
+				return errors.Wrap(err, "Error decoding OSM PBF info user")
+			}
+			s, err := block.String(x)
+			if err != nil {
+				return err
+			}
+			e.UserName = s
+		case 6:
+			x, err := r.ReadVarint()
+			if err != nil {
+				return errors.Wrap(err, "Error decoding OSM PBF info visible")
+			}
+			visible := x != 0
+			e.Visible = &visible
+		default:
+			err = r.Skip(wireType)
+			if err != nil {
+				return errors.Wrap(err, "Error decoding OSM PBF info")
+			}
+		}
+	}
+	return nil
+}
+
+// unmarshalPBFNode decodes a Node message.
+func unmarshalPBFNode(data []byte, block *pbfBlock) (*Node, error) {
+	n := NewNode()
+	keys := make([]uint64, 0)
+	values := make([]uint64, 0)
+
+	r := newProtoReader(data)
+	for r.More() {
+		field, wireType, err := r.ReadKey()
+		if err != nil {
+			return n, errors.Wrap(err, "Error decoding OSM PBF node")
+		}
+		switch field {
+		case 1:
+			x, err := r.ReadSint64()
+			if err != nil {
+				return n, errors.Wrap(err, "Error decoding OSM PBF node id")
+			}
+			n.Id = uint64(x)
+		case 2:
+			keys, err = r.ReadPackedVarints(wireType, keys)
+			if err != nil {
+				return n, errors.Wrap(err, "Error decoding OSM PBF node keys")
+			}
+		case 3:
+			values, err = r.ReadPackedVarints(wireType, values)
+			if err != nil {
+				return n, errors.Wrap(err, "Error decoding OSM PBF node values")
+			}
+		case 4:
+			b, err := r.ReadBytes()
+			if err != nil {
+				return n, errors.Wrap(err, "Error decoding OSM PBF node info")
+			}
+			err = unmarshalPBFInfo(b, block, &n.Element)
+			if err != nil {
+				return n, err
+			}
+		case 8:
+			x, err := r.ReadSint64()
+			if err != nil {
+				return n, errors.Wrap(err, "Error decoding OSM PBF node latitude")
+			}
+			n.Latitude = block.Latitude(x)
+		case 9:
+			x, err := r.ReadSint64()
+			if err != nil {
+				return n, errors.Wrap(err, "Error decoding OSM PBF node longitude")
+			}
+			n.Longitude = block.Longitude(x)
+		default:
+			err = r.Skip(wireType)
+			if err != nil {
+				return n, errors.Wrap(err, "Error decoding OSM PBF node")
+			}
+		}
+	}
+
+	tags, err := block.Tags(keys, values)
+	if err != nil {
+		return n, errors.Wrap(err, "Error decoding tags for OSM PBF node "+strconv.FormatUint(n.Id, 10))
+	}
+	n.SetTags(tags)
+
+	return n, nil
+}
+
+// unmarshalPBFDenseNodes decodes a DenseNodes message and appends the nodes to elements.
+// Ids, coordinates, and most of the metadata are delta coded.
+func unmarshalPBFDenseNodes(data []byte, block *pbfBlock, elements []interface{}) ([]interface{}, error) {
+	ids := make([]int64, 0)
+	lats := make([]int64, 0)
+	lons := make([]int64, 0)
+	keysValues := make([]uint64, 0)
+	var denseInfo []byte
+
+	r := newProtoReader(data)
+	for r.More() {
+		field, wireType, err := r.ReadKey()
+		if err != nil {
+			return elements, errors.Wrap(err, "Error decoding OSM PBF dense nodes")
+		}
+		switch field {
+		case 1:
+			ids, err = r.ReadPackedSint64s(wireType, ids)
+		case 5:
+			denseInfo, err = r.ReadBytes()
+		case 8:
+			lats, err = r.ReadPackedSint64s(wireType, lats)
+		case 9:
+			lons, err = r.ReadPackedSint64s(wireType, lons)
+		case 10:
+			keysValues, err = r.ReadPackedVarints(wireType, keysValues)
+		default:
+			err = r.Skip(wireType)
+		}
+		if err != nil {
+			return elements, errors.Wrap(err, "Error decoding OSM PBF dense nodes")
+		}
+	}
+
+	if len(lats) != len(ids) || len(lons) != len(ids) {
+		return elements, errors.New("Number of ids, latitudes, and longitudes in OSM PBF dense nodes do not match.")
+	}
+
+	versions := make([]uint64, 0)
+	timestamps := make([]int64, 0)
+	changesets := make([]int64, 0)
+	uids := make([]int64, 0)
+	userSids := make([]int64, 0)
+	visibles := make([]uint64, 0)
+	if len(denseInfo) > 0 {
+		r := newProtoReader(denseInfo)
+		for r.More() {
+			field, wireType, err := r.ReadKey()
+			if err != nil {
+				return elements, errors.Wrap(err, "Error decoding OSM PBF dense info")
+			}
+			switch field {
+			case 1:
+				versions, err = r.ReadPackedVarints(wireType, versions)
+			case 2:
+				timestamps, err = r.ReadPackedSint64s(wireType, timestamps)
+			case 3:
+				changesets, err = r.ReadPackedSint64s(wireType, changesets)
+			case 4:
+				uids, err = r.ReadPackedSint64s(wireType, uids)
+			case 5:
+				userSids, err = r.ReadPackedSint64s(wireType, userSids)
+			case 6:
+				visibles, err = r.ReadPackedVarints(wireType, visibles)
+			default:
+				err = r.Skip(wireType)
+			}
+			if err != nil {
+				return elements, errors.Wrap(err, "Error decoding OSM PBF dense info")
+			}
+		}
+	}
+
+	id := int64(0)
+	lat := int64(0)
+	lon := int64(0)
+	timestamp := int64(0)
+	changeset := int64(0)
+	uid := int64(0)
+	userSid := int64(0)
+	kv := 0
+	for i := range ids {
+		id += ids[i]
+		lat += lats[i]
+		lon += lons[i]
+
+		n := NewNode()
+		n.Id = uint64(id)
+		n.Latitude = block.Latitude(lat)
+		n.Longitude = block.Longitude(lon)
+
+		if i < len(versions) {
+			n.Version = uint16(versions[i])
+		}
+		if i < len(timestamps) {
+			timestamp += timestamps[i]
+			n.Timestamp = block.Timestamp(timestamp)
+		}
+		if i < len(changesets) {
+			changeset += changesets[i]
+			n.Changeset = uint64(changeset)
+		}
+		if i < len(uids) {
+			uid += uids[i]
+			n.UserId = uint64(uid)
+		}
+		if i < len(userSids) {
+			userSid += userSids[i]
+			s, err := block.String(uint64(userSid))
+			if err != nil {
+				return elements, err
+			}
+			n.UserName = s
+		}
+		if i < len(visibles) {
+			visible := visibles[i] != 0
+			n.Visible = &visible
+		}
+
+		// keys_vals is a list of alternating keys and values for each node, with each node delimited by 0.
+		if kv < len(keysValues) {
+			tags := make([]Tag, 0)
+			for kv < len(keysValues) && keysValues[kv] != 0 {
+				if kv+1 >= len(keysValues) {
+					return elements, errors.New("Tag key without value in OSM PBF dense nodes.")
+				}
+				k, err := block.String(keysValues[kv])
+				if err != nil {
+					return elements, err
+				}
+				v, err := block.String(keysValues[kv+1])
+				if err != nil {
+					return elements, err
+				}
+				tags = append(tags, Tag{Key: k, Value: v})
+				kv += 2
+			}
+			kv += 1
+			n.SetTags(tags)
+		}
+
+		elements = append(elements, n)
+	}
+
+	return elements, nil
+}
+
+// unmarshalPBFWay decodes a Way message.  Node references are delta coded.
+func unmarshalPBFWay(data []byte, block *pbfBlock) (*Way, error) {
+	w := NewWay()
+	keys := make([]uint64, 0)
+	values := make([]uint64, 0)
+	refs := make([]int64, 0)
+
+	r := newProtoReader(data)
+	for r.More() {
+		field, wireType, err := r.ReadKey()
+		if err != nil {
+			return w, errors.Wrap(err, "Error decoding OSM PBF way")
+		}
+		switch field {
+		case 1:
+			x, err := r.ReadVarint()
+			if err != nil {
+				return w, errors.Wrap(err, "Error decoding OSM PBF way id")
+			}
+			w.Id = x
+		case 2:
+			keys, err = r.ReadPackedVarints(wireType, keys)
+			if err != nil {
+				return w, errors.Wrap(err, "Error decoding OSM PBF way keys")
+			}
+		case 3:
+			values, err = r.ReadPackedVarints(wireType, values)
+			if err != nil {
+				return w, errors.Wrap(err, "Error decoding OSM PBF way values")
+			}
+		case 4:
+			b, err := r.ReadBytes()
+			if err != nil {
+				return w, errors.Wrap(err, "Error decoding OSM PBF way info")
+			}
+			err = unmarshalPBFInfo(b, block, &w.Element)
+			if err != nil {
+				return w, err
+			}
+		case 8:
+			refs, err = r.ReadPackedSint64s(wireType, refs)
+			if err != nil {
+				return w, errors.Wrap(err, "Error decoding OSM PBF way node references")
+			}
+		default:
+			err = r.Skip(wireType)
+			if err != nil {
+				return w, errors.Wrap(err, "Error decoding OSM PBF way")
+			}
+		}
+	}
+
+	w.NodeReferences = make([]NodeReference, len(refs))
+	ref := int64(0)
+	for i, delta := range refs {
+		ref += delta
+		w.NodeReferences[i] = NodeReference{Reference: uint64(ref)}
+	}
+
+	tags, err := block.Tags(keys, values)
+	if err != nil {
+		return w, errors.Wrap(err, "Error decoding tags for OSM PBF way "+strconv.FormatUint(w.Id, 10))
+	}
+	w.SetTags(tags)
+
+	return w, nil
+}
+
+// unmarshalPBFRelation decodes a Relation message.  Member ids are delta coded.
+func unmarshalPBFRelation(data []byte, block *pbfBlock) (*Relation, error) {
+	rel := NewRelation()
+	keys := make([]uint64, 0)
+	values := make([]uint64, 0)
+	roles := make([]uint64, 0)
+	memids := make([]int64, 0)
+	types := make([]uint64, 0)
+
+	r := newProtoReader(data)
+	for r.More() {
+		field, wireType, err := r.ReadKey()
+		if err != nil {
+			return rel, errors.Wrap(err, "Error decoding OSM PBF relation")
+		}
+		switch field {
+		case 1:
+			x, err := r.ReadVarint()
+			if err != nil {
+				return rel, errors.Wrap(err, "Error decoding OSM PBF relation id")
+			}
+			rel.Id = x
+		case 2:
+			keys, err = r.ReadPackedVarints(wireType, keys)
+		case 3:
+			values, err = r.ReadPackedVarints(wireType, values)
+		case 4:
+			b, err := r.ReadBytes()
+			if err != nil {
+				return rel, errors.Wrap(err, "Error decoding OSM PBF relation info")
+			}
+			err = unmarshalPBFInfo(b, block, &rel.Element)
+			if err != nil {
+				return rel, err
+			}
+		case 8:
+			roles, err = r.ReadPackedVarints(wireType, roles)
+		case 9:
+			memids, err = r.ReadPackedSint64s(wireType, memids)
+		case 10:
+			types, err = r.ReadPackedVarints(wireType, types)
+		default:
+			err = r.Skip(wireType)
+		}
+		if err != nil {
+			return rel, errors.Wrap(err, "Error decoding OSM PBF relation")
+		}
+	}
+
+	if len(roles) != len(memids) || len(types) != len(memids) {
+		return rel, errors.New("Number of roles, member ids, and member types in OSM PBF relation " + strconv.FormatUint(rel.Id, 10) + " do not match.")
+	}
+
+	rel.Members = make([]RelationMember, len(memids))
+	ref := int64(0)
+	for i := range memids {
+		ref += memids[i]
+		role, err := block.String(roles[i])
+		if err != nil {
+			return rel, err
+		}
+		rm := RelationMember{Reference: uint64(ref), Role: role}
+		switch types[i] {
+		case 0:
+			rm.Type = "node"
+		case 1:
+			rm.Type = "way"
+		case 2:
+			rm.Type = "relation"
+		default:
+			return rel, errors.New("Unknown member type " + strconv.FormatUint(types[i], 10) + " in OSM PBF relation " + strconv.FormatUint(rel.Id, 10) + ".")
+		}
+		rel.Members[i] = rm
+	}
+
+	tags, err := block.Tags(keys, values)
+	if err != nil {
+		return rel, errors.Wrap(err, "Error decoding tags for OSM PBF relation "+strconv.FormatUint(rel.Id, 10))
+	}
+	rel.SetTags(tags)
+
+	return rel, nil
+}
@@ -2,11 +2,10 @@ package osm
 
 import (
 	//"bytes"
-	"encoding/xml"
-	//"io"
+	//"encoding/xml"
+	"io"
 	//"fmt"
 	//"io/ioutil"
-	"time"
 )
 
 import (
@@ -22,9 +21,15 @@ import (
 )
 
 // UnmarshalPlanet reads an OSM Planet File from an io.Reader and unmarshals the data into the *Planet object.
+// The input is decoded with the ElementDecoder for the input's format.  Deletions in o5c change files are skipped.
+// Full-history inputs are read as a snapshot at the input's SnapshotAt time, or the latest version of each element if not set.
 // Returns an error if any.
 func UnmarshalPlanet(p *Planet, input *Input, logger *compositelogger.CompositeLogger) error {
 
+	if input.History || input.SnapshotAt != nil {
+		return UnmarshalPlanetSnapshot(p, input, input.SnapshotAt)
+	}
+
 	var dfl_cache *dfl.Cache
 	if input.Filter != nil && input.Filter.HasExpression() && input.Filter.UseCache {
 		dfl_cache = dfl.NewCache()
@@ -34,147 +39,52 @@ func UnmarshalPlanet(p *Planet, input *Input, logger *compositelogger.CompositeL
 	ways := make([]*Way, 0)
 	relations := make([]*Relation, 0)
 
-	decoder := xml.NewDecoder(input.Reader)
+	decoder, err := NewInputDecoder(input, p.maxId+1)
+	if err != nil {
+		return err
+	}
+
 	for {
 
-		t, _ := decoder.Token()
-		if t == nil {
-			break
+		element, err := decoder.Decode()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return errors.Wrap(err, "Error decoding "+input.Format)
 		}
 
-		switch e := t.(type) {
-		case xml.StartElement:
-			switch e.Name.Local {
-			case "osm":
-				p.XMLName = e.Name
-				for _, attr := range e.Attr {
-					switch attr.Name.Local {
-					case "version":
-						p.Version = attr.Value
-					case "generator":
-						p.Generator = attr.Value
-					case "timestamp":
-						v, err := time.Parse(time.RFC3339, attr.Value)
-						if err != nil {
-							return errors.Wrap(err, "Error parsing osm timestamp")
-						}
-						p.Timestamp = v
-					}
-				}
-			case "bounds":
-				b, err := UnmarshalBounds(decoder, e)
-				if err != nil {
-					return err
-				}
-				p.Bounds = b
-			case "node":
-				n, user_id, user_name, tags, err := UnmarshalNode(decoder, e, input)
+		if IsDeletion(decoder) {
+			continue
+		}
+
+		switch e := element.(type) {
+		case *Node:
+			p.ImportTaggedElement(input, &e.TaggedElement)
+			keep := true
+			if input.DropWays && input.DropRelations {
+				keep, err = KeepNode(p, input.Filter, e, dfl_cache)
 				if err != nil {
 					return err
 				}
-				if user_id > 0 {
-					n.UserId = user_id
-					if len(user_name) > 0 {
-						p.UserNames[n.UserId] = user_name
-					}
-				}
-				n.SetTagsIndex(p.AddTags(tags))
-				keep := true
-				if input.DropWays && input.DropRelations {
-					keep, err = KeepNode(p, input.Filter, n, dfl_cache)
-					if err != nil {
-						return err
-					}
-				}
-				if keep {
-					nodes = append(nodes, n)
-				}
-			case "way":
-				if !input.DropWays {
-					w, user_id, user_name, tags, err := UnmarshalWay(decoder, e, input)
-					if err != nil {
-						return err
-					}
-					if user_id > 0 {
-						w.UserId = user_id
-						if len(user_name) > 0 {
-							p.UserNames[w.UserId] = user_name
-						}
-					}
-					w.SetTagsIndex(p.AddTags(tags))
-					keep, err := KeepWay(p, input.Filter, w, dfl_cache)
-					if err != nil {
-						return err
-					}
-					if keep {
-						ways = append(ways, w)
-					}
-				}
-			case "relation":
-				if !input.DropRelations {
-					r, user_id, user_name, tags, err := UnmarshalRelation(decoder, e, input)
-					if err != nil {
-						return err
-					}
-					if user_id > 0 {
-						r.UserId = user_id
-						if len(user_name) > 0 {
-							p.UserNames[r.UserId] = user_name
-						}
-					}
-					r.SetTagsIndex(p.AddTags(tags))
-					keep, err := KeepRelation(p, input.Filter, r, dfl_cache)
-					if err != nil {
-						return err
-					}
-					if keep {
-						relations = append(relations, r)
-					}
-				}
-			}
-		}
-
-	}
-
-	if input.DropWays && input.DropRelations {
-		for _, n := range nodes {
-			p.AddNode(n)
-		}
-	} else {
-		set_way_nodes := NewUInt64Set()
-		for _, w := range ways {
-			for _, nr := range w.NodeReferences {
-				set_way_nodes.Add(nr.Reference)
 			}
-		}
-		slice_way_nodes := set_way_nodes.Slice(true)
-		valid_nodes := make([]*Node, 0)
-		for _, n := range nodes {
-			keep := true
-			if slice_way_nodes.Contains(n.Id) {
-				valid_nodes = append(valid_nodes, n)
-				continue
+			if keep {
+				nodes = append(nodes, e)
 			}
-			keep, err := KeepNode(p, input.Filter, n, dfl_cache)
-			if err != nil {
-				return err
+		case *Way:
+			if !input.DropWays {
+				p.ImportTaggedElement(input, &e.TaggedElement)
+				ways = append(ways, e)
 			}
-			if keep {
-				valid_nodes = append(valid_nodes, n)
+		case *Relation:
+			if !input.DropRelations {
+				p.ImportTaggedElement(input, &e.TaggedElement)
+				relations = append(relations, e)
 			}
 		}
-
-		// Add elements to planet
-		for _, n := range valid_nodes {
-			p.AddNode(n)
-		}
-		for _, w := range ways {
-			p.AddWay(w)
-		}
-		for _, r := range relations {
-			p.AddRelation(r)
-		}
 	}
 
-	return nil
+	p.ImportHeader(decoder)
+
+	return AddElementsToPlanet(p, input, nodes, ways, relations, dfl_cache)
 }
@@ -0,0 +1,51 @@
+package osm
+
+import (
+	"time"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+// UnmarshalPlanetSnapshot reads a full-history input and adds the elements to the planet as they existed at the given time.
+// Elements created after the given time or deleted at the given time are not added.  If at is nil, then adds the latest version of every element.
+// Returns an error if any.
+func UnmarshalPlanetSnapshot(p *Planet, input *Input, at *time.Time) error {
+
+	h, err := UnmarshalHistory(p, input)
+	if err != nil {
+		return err
+	}
+
+	var dfl_cache *dfl.Cache
+	if input.Filter != nil && input.Filter.HasExpression() && input.Filter.UseCache {
+		dfl_cache = dfl.NewCache()
+	}
+
+	nodes, ways, relations := h.Snapshot(at)
+
+	// Attributes are dropped after the snapshot is built, since the versions are chosen by version number and timestamp.
+	kept_nodes := make([]*Node, 0, len(nodes))
+	for _, n := range nodes {
+		p.ImportTaggedElement(input, &n.TaggedElement)
+		keep := true
+		if input.DropWays && input.DropRelations {
+			keep, err = KeepNode(p, input.Filter, n, dfl_cache)
+			if err != nil {
+				return err
+			}
+		}
+		if keep {
+			kept_nodes = append(kept_nodes, n)
+		}
+	}
+	for _, w := range ways {
+		p.ImportTaggedElement(input, &w.TaggedElement)
+	}
+	for _, r := range relations {
+		p.ImportTaggedElement(input, &r.TaggedElement)
+	}
+
+	return AddElementsToPlanet(p, input, kept_nodes, ways, relations, dfl_cache)
+}
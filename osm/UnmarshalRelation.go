@@ -62,6 +62,12 @@ func UnmarshalRelation(decoder *xml.Decoder, e xml.StartElement, input *Input) (
 			if !input.DropUserName {
 				user_name = attr.Value
 			}
+		case "visible":
+			visible, err := strconv.ParseBool(attr.Value)
+			if err != nil {
+				return r, user_id, user_name, tags, errors.Wrap(err, "Error parsing relation visible")
+			}
+			r.Visible = &visible
 		}
 	}
 
@@ -62,6 +62,12 @@ func UnmarshalWay(decoder *xml.Decoder, e xml.StartElement, input *Input) (*Way,
 			if !input.DropUserName {
 				user_name = attr.Value
 			}
+		case "visible":
+			visible, err := strconv.ParseBool(attr.Value)
+			if err != nil {
+				return w, user_id, user_name, tags, errors.Wrap(err, "Error parsing way visible")
+			}
+			w.Visible = &visible
 		}
 	}
 
@@ -0,0 +1,60 @@
+package osm
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ValidateStream returns an error if the elements from the input cannot be streamed to the output.
+// Streaming pushes one element at a time from the decoder to the encoder, so it only supports outputs
+// that need no cross-element lookups.  Ways require all their nodes and relations require all their members,
+// so filters can only be streamed if ways and relations are dropped.
+func ValidateStream(input *Input, output *Output) error {
+
+	if input.History || input.SnapshotAt != nil {
+		return errors.New("Cannot stream from input " + input.Uri + ", since building a snapshot from a full-history file requires reading every version of each element.")
+	}
+
+	if output.IsTileset() {
+		return errors.New("Cannot stream to output " + output.Uri + ", since vector tiles require the geometry of every feature.")
+	}
+
+	if output.IsTable() {
+		return errors.New("Cannot stream to output " + output.Uri + ", since csv and tsv outputs require the geometry of every feature.")
+	}
+
+	if output.IsGDAL() {
+		return errors.New("Cannot stream to output " + output.Uri + ", since GDAL layers require the geometry of every feature.")
+	}
+
+	if output.IsParquet() {
+		return errors.New("Cannot stream to output " + output.Uri + ", since parquet outputs require the geometry of every element.")
+	}
+
+	if output.IsSQL() {
+		return errors.New("Cannot stream to output " + output.Uri + ", since sql outputs require the geometry of every element.")
+	}
+
+	if output.WaysToNodes {
+		return errors.New("Cannot stream to output " + output.Uri + ", since converting ways to nodes requires looking up the way's nodes.")
+	}
+
+	keep_nodes := !input.DropNodes && !output.DropNodes
+	keep_ways := !input.DropWays && !output.DropWays
+	keep_relations := !input.DropRelations && !output.DropRelations
+
+	if keep_ways || keep_relations {
+		if (input.Filter != nil && !input.Filter.IsEmpty()) || (output.Filter != nil && !output.Filter.IsEmpty()) {
+			return errors.New("Cannot stream from input " + input.Uri + " to output " + output.Uri + " with a filter, unless ways and relations are dropped.")
+		}
+	}
+
+	if keep_ways && !keep_nodes {
+		return errors.New("Cannot stream from input " + input.Uri + " to output " + output.Uri + ", since ways are kept but nodes are dropped.")
+	}
+
+	if keep_relations && !(keep_nodes && keep_ways) {
+		return errors.New("Cannot stream from input " + input.Uri + " to output " + output.Uri + ", since relations are kept but nodes or ways are dropped.")
+	}
+
+	return nil
+}
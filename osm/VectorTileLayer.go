@@ -0,0 +1,125 @@
+package osm
+
+import (
+	"fmt"
+	"sort"
+)
+
+// VectorTileLayer is a layer of a Mapbox Vector Tile, version 2.
+// The keys and values of the feature properties are shared by all the features in the layer.
+type VectorTileLayer struct {
+	Name        string         // name of the layer
+	Extent      int            // width and height of the tile in the layer's coordinates, usually 4096
+	keys        []string       // property keys
+	keysIndex   map[string]int // map of key to position in keys
+	values      [][]byte       // encoded property values
+	valuesIndex map[string]int // map of encoded value to position in values
+	features    [][]byte       // encoded features
+}
+
+// NewVectorTileLayer returns a new empty layer with the given name and extent.
+func NewVectorTileLayer(name string, extent int) *VectorTileLayer {
+	return &VectorTileLayer{
+		Name:        name,
+		Extent:      extent,
+		keys:        make([]string, 0),
+		keysIndex:   map[string]int{},
+		values:      make([][]byte, 0),
+		valuesIndex: map[string]int{},
+		features:    make([][]byte, 0),
+	}
+}
+
+// Len returns the number of features in the layer.
+func (l *VectorTileLayer) Len() int {
+	return len(l.features)
+}
+
+// key returns the position of the key in the layer's keys, adding it if needed.
+func (l *VectorTileLayer) key(k string) int {
+	if i, ok := l.keysIndex[k]; ok {
+		return i
+	}
+	l.keys = append(l.keys, k)
+	l.keysIndex[k] = len(l.keys) - 1
+	return len(l.keys) - 1
+}
+
+// value returns the position of the value in the layer's values, adding it if needed.
+// Strings, floats, integers, and booleans are encoded with their own types and everything else is encoded as a string.
+func (l *VectorTileLayer) value(v interface{}) int {
+	pw := newProtoWriter(16)
+	switch v := v.(type) {
+	case string:
+		pw.WriteString(1, v)
+	case float32:
+		pw.WriteDouble(3, float64(v))
+	case float64:
+		pw.WriteDouble(3, v)
+	case int:
+		pw.WriteVarint(4, uint64(v))
+	case int64:
+		pw.WriteVarint(4, uint64(v))
+	case uint64:
+		pw.WriteVarint(5, v)
+	case bool:
+		if v {
+			pw.WriteVarint(7, 1)
+		} else {
+			pw.WriteVarint(7, 0)
+		}
+	default:
+		pw.WriteString(1, fmt.Sprint(v))
+	}
+	encoded := pw.Bytes()
+	if i, ok := l.valuesIndex[string(encoded)]; ok {
+		return i
+	}
+	l.values = append(l.values, encoded)
+	l.valuesIndex[string(encoded)] = len(l.values) - 1
+	return len(l.values) - 1
+}
+
+// AddFeature adds a feature to the layer with the given id, geometry type, encoded geometry, and properties.
+// If the id is 0, then the feature is written without an id.
+// Properties are added in order of their keys, so the same features always encode to the same bytes.
+func (l *VectorTileLayer) AddFeature(id uint64, geometry_type int, geometry []uint64, properties map[string]interface{}) {
+
+	keys := make([]string, 0, len(properties))
+	for k := range properties {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	tags := make([]uint64, 0, 2*len(keys))
+	for _, k := range keys {
+		tags = append(tags, uint64(l.key(k)), uint64(l.value(properties[k])))
+	}
+
+	pw := newProtoWriter(16 + 2*len(tags) + 2*len(geometry))
+	if id != 0 {
+		pw.WriteVarint(1, id)
+	}
+	pw.WritePackedVarints(2, tags)
+	pw.WriteVarint(3, uint64(geometry_type))
+	pw.WritePackedVarints(4, geometry)
+	l.features = append(l.features, pw.Bytes())
+}
+
+// Bytes returns the layer encoded as a Layer message.
+func (l *VectorTileLayer) Bytes() []byte {
+	pw := newProtoWriter(1024)
+	pw.WriteVarint(15, 2)
+	pw.WriteString(1, l.Name)
+	for _, f := range l.features {
+		pw.WriteBytes(2, f)
+	}
+	for _, k := range l.keys {
+		pw.WriteString(3, k)
+	}
+	for _, v := range l.values {
+		pw.WriteBytes(4, v)
+	}
+	pw.WriteVarint(5, uint64(l.Extent))
+	return pw.Bytes()
+}
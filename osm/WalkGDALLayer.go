@@ -0,0 +1,258 @@
+package osm
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+// WalkGDALLayer calls fn with the values and geometry of each feature of the GDAL layer with the given name, the same as the GDAL OSM driver.
+// The values are in the order of the layer's columns.  See GDALLayer.Columns.
+//	- points are nodes with significant tags, or every node if report_all_nodes is set.
+//	- lines are ways with significant tags that are not polygons.
+//	- multipolygons are closed ways with significant tags that are polygons, with osm_way_id set, and assembled multipolygon and boundary relations, with osm_id set.
+//	- multilinestrings are multilinestring and route relations, with their member ways as lines.
+//	- other_relations are all other relations, with their member nodes and ways as a GeometryCollection.
+// Ways without significant tags are only reported if report_all_ways is set.  See GDALConfig.IsPolygon for which ways are polygons.
+// Elements are also filtered by the output's filter and drop flags.
+// Ways that reference missing nodes and relations that cannot be assembled are skipped and returned as incomplete ways and relations.
+// Stops at the first error returned by fn.  Returns the incomplete ways, the incomplete relations, and an error if any.
+func (p *Planet) WalkGDALLayer(output *Output, gdal *GDALConfig, name string, fn func(values []interface{}, geometry *geojsonObject) error) ([]*IncompleteWay, []*IncompleteRelation, error) {
+
+	incomplete_ways := make([]*IncompleteWay, 0)
+	incomplete := make([]*IncompleteRelation, 0)
+
+	layer := gdal.Layer(name)
+	if layer == nil {
+		return incomplete_ways, incomplete, errors.New("Unknown GDAL layer " + name + ".  Expecting points, lines, multipolygons, multilinestrings, or other_relations.")
+	}
+
+	var dfl_cache *dfl.Cache
+	if output.Filter.HasExpression() && output.Filter.UseCache {
+		dfl_cache = dfl.NewCache()
+	}
+
+	values := func(te *TaggedElement, osm_id interface{}, osm_way_id interface{}) ([]interface{}, error) {
+		tags := FilterTags(p.Tags.Slice(te.TagsIndex), output.KeysToKeep, output.KeysToDrop)
+		values := make([]interface{}, 0, len(layer.Attributes)+8)
+		if layer.OsmId {
+			values = append(values, osm_id)
+			if layer.Name == "multipolygons" {
+				values = append(values, osm_way_id)
+			}
+		}
+		if layer.OsmVersion {
+			values = append(values, int(te.Version))
+		}
+		if layer.OsmTimestamp {
+			if te.Timestamp == nil {
+				values = append(values, nil)
+			} else {
+				values = append(values, te.Timestamp.UTC().Format(time.RFC3339))
+			}
+		}
+		if layer.OsmUid {
+			values = append(values, te.UserId)
+		}
+		if layer.OsmUser {
+			if user, ok := p.UserNames[te.UserId]; ok && len(user) > 0 {
+				values = append(values, user)
+			} else {
+				values = append(values, nil)
+			}
+		}
+		if layer.OsmChangeset {
+			values = append(values, te.Changeset)
+		}
+		for _, key := range layer.Attributes {
+			var value interface{}
+			for _, t := range tags {
+				if t.Key == key {
+					value = t.Value
+					break
+				}
+			}
+			values = append(values, value)
+		}
+		if layer.AllTags || layer.OtherTags {
+			other_tags := map[string]interface{}{}
+			for _, t := range tags {
+				if layer.IsIgnored(t.Key) {
+					continue
+				}
+				if !layer.AllTags && stringSliceContains(layer.Attributes, t.Key) {
+					continue
+				}
+				other_tags[t.Key] = t.Value
+			}
+			if len(other_tags) == 0 {
+				values = append(values, nil)
+			} else if gdal.TagsFormat == "json" {
+				b, err := json.Marshal(other_tags)
+				if err != nil {
+					return values, errors.Wrap(err, "Error marshalling tags as JSON")
+				}
+				values = append(values, string(b))
+			} else {
+				values = append(values, MarshalHStore(other_tags))
+			}
+		}
+		return values, nil
+	}
+
+	walk := func(te *TaggedElement, osm_id interface{}, osm_way_id interface{}, geometry *geojsonObject) error {
+		v, err := values(te, osm_id, osm_way_id)
+		if err != nil {
+			return err
+		}
+		return fn(v, geometry)
+	}
+
+	switch name {
+	case "points":
+		if output.DropNodes {
+			return incomplete_ways, incomplete, nil
+		}
+		for _, n := range p.Nodes {
+			if !gdal.ReportAllNodes && !layer.IsSignificant(p.Tags.Slice(n.TagsIndex)) {
+				continue
+			}
+			keep, err := KeepNode(p, output.Filter, n, dfl_cache)
+			if err != nil {
+				return incomplete_ways, incomplete, errors.Wrap(err, "Error filtering node for GDAL layer "+name)
+			}
+			if !keep {
+				continue
+			}
+			geometry, err := newGeojsonGeometry("Point", []float64{n.Longitude, n.Latitude})
+			if err != nil {
+				return incomplete_ways, incomplete, err
+			}
+			err = walk(&n.TaggedElement, fmt.Sprint(n.Id), nil, geometry)
+			if err != nil {
+				return incomplete_ways, incomplete, err
+			}
+		}
+	case "lines", "multipolygons":
+		if !output.DropWays {
+			for _, w := range p.Ways {
+				if gdal.IsPolygon(w, p.Tags.Map(w.TagsIndex)) != (name == "multipolygons") {
+					continue
+				}
+				if !gdal.ReportAllWays && !layer.IsSignificant(p.Tags.Slice(w.TagsIndex)) {
+					continue
+				}
+				keep, err := KeepWay(p, output.Filter, w, dfl_cache)
+				if err != nil {
+					return incomplete_ways, incomplete, errors.Wrap(err, "Error filtering way for GDAL layer "+name)
+				}
+				if !keep {
+					continue
+				}
+				coordinates, err := p.WayCoordinates(w)
+				if err != nil {
+					if iw, ok := err.(*IncompleteWay); ok {
+						incomplete_ways = append(incomplete_ways, iw)
+						continue
+					}
+					return incomplete_ways, incomplete, errors.Wrap(err, "Error converting way "+fmt.Sprint(w.Id)+" to feature.")
+				}
+				var geometry *geojsonObject
+				if name == "multipolygons" {
+					geometry, err = newGeojsonGeometry("Polygon", [][][]float64{coordinates})
+					if err != nil {
+						return incomplete_ways, incomplete, err
+					}
+					err = walk(&w.TaggedElement, nil, fmt.Sprint(w.Id), geometry)
+				} else {
+					geometry, err = newGeojsonGeometry("LineString", coordinates)
+					if err != nil {
+						return incomplete_ways, incomplete, err
+					}
+					err = walk(&w.TaggedElement, fmt.Sprint(w.Id), nil, geometry)
+				}
+				if err != nil {
+					return incomplete_ways, incomplete, err
+				}
+			}
+		}
+		if name == "lines" || output.DropRelations {
+			return incomplete_ways, incomplete, nil
+		}
+		for _, r := range p.Relations {
+			if t := p.RelationType(r); t != "multipolygon" && t != "boundary" {
+				continue
+			}
+			keep, err := KeepRelation(p, output.Filter, r, dfl_cache)
+			if err != nil {
+				return incomplete_ways, incomplete, errors.Wrap(err, "Error filtering relation for GDAL layer "+name)
+			}
+			if !keep {
+				continue
+			}
+			coordinates, err := p.AssembleMultiPolygon(r)
+			if err != nil {
+				if ir, ok := err.(*IncompleteRelation); ok {
+					incomplete = append(incomplete, ir)
+					continue
+				}
+				return incomplete_ways, incomplete, errors.Wrap(err, "Error assembling relation "+fmt.Sprint(r.Id)+".")
+			}
+			geometry, err := newGeojsonGeometry("MultiPolygon", coordinates)
+			if err != nil {
+				return incomplete_ways, incomplete, err
+			}
+			err = walk(&r.TaggedElement, fmt.Sprint(r.Id), nil, geometry)
+			if err != nil {
+				return incomplete_ways, incomplete, err
+			}
+		}
+	case "multilinestrings", "other_relations":
+		if output.DropRelations {
+			return incomplete_ways, incomplete, nil
+		}
+		for _, r := range p.Relations {
+			switch p.RelationType(r) {
+			case "multipolygon", "boundary":
+				continue
+			case "multilinestring", "route":
+				if name != "multilinestrings" {
+					continue
+				}
+			default:
+				if name != "other_relations" {
+					continue
+				}
+			}
+			keep, err := KeepRelation(p, output.Filter, r, dfl_cache)
+			if err != nil {
+				return incomplete_ways, incomplete, errors.Wrap(err, "Error filtering relation for GDAL layer "+name)
+			}
+			if !keep {
+				continue
+			}
+			geometry, err := p.gdalRelationGeometry(r, name == "other_relations")
+			if err != nil {
+				if ir, ok := err.(*IncompleteRelation); ok {
+					incomplete = append(incomplete, ir)
+					continue
+				}
+				return incomplete_ways, incomplete, err
+			}
+			err = walk(&r.TaggedElement, fmt.Sprint(r.Id), nil, geometry)
+			if err != nil {
+				return incomplete_ways, incomplete, err
+			}
+		}
+	}
+
+	return incomplete_ways, incomplete, nil
+}
@@ -27,6 +27,11 @@ func (w Way) NumberOfNodes() int {
 	return len(w.NodeReferences)
 }
 
+// IsClosed returns true if the way has at least 4 nodes and the first and last nodes are the same, otherwise false.
+func (w Way) IsClosed() bool {
+	return len(w.NodeReferences) >= 4 && w.NodeReferences[0].Reference == w.NodeReferences[len(w.NodeReferences)-1].Reference
+}
+
 func NewWay() *Way {
 	return &Way{TaggedElement: TaggedElement{Element: Element{}}}
 }
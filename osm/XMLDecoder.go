@@ -0,0 +1,118 @@
+package osm
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// XMLDecoder decodes OSM elements from an OSM XML (.osm) or osmChange (.osc) stream.
+//	- https://wiki.openstreetmap.org/wiki/OSM_XML
+//	- https://wiki.openstreetmap.org/wiki/OsmChange
+type XMLDecoder struct {
+	decoder   *xml.Decoder
+	input     *Input     // input used when unmarshalling elements.  Keeps all attributes and tags.
+	Version   string     // the version of the osm or osmChange element, available after the first call to Decode
+	Generator string     // the generator of the osm or osmChange element, available after the first call to Decode
+	Timestamp *time.Time // the timestamp of the osm element, if any
+	Bounds    *Bounds    // the bounds of the stream, if any
+	Action    string     // the osmChange action of the element most recently returned by Decode: create, modify, or delete.  Blank for .osm streams.
+}
+
+// NewXMLDecoder returns a new XMLDecoder reading from r.
+func NewXMLDecoder(r io.Reader) *XMLDecoder {
+	return &XMLDecoder{
+		decoder: xml.NewDecoder(r),
+		input:   &Input{PlanetResource: &PlanetResource{}},
+	}
+}
+
+// Decode returns the next element in the stream as a *Node, *Way, or *Relation.
+// The returned element's Tags, UserId, and UserName are set from the stream.
+// Returns io.EOF when there are no more elements.
+func (d *XMLDecoder) Decode() (interface{}, error) {
+	for {
+		t, err := d.decoder.Token()
+		if err != nil {
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			return nil, errors.Wrap(err, "Error decoding OSM XML")
+		}
+
+		if e, ok := t.(xml.EndElement); ok {
+			switch e.Name.Local {
+			case CHANGE_CREATE, CHANGE_MODIFY, CHANGE_DELETE:
+				d.Action = ""
+			}
+			continue
+		}
+
+		e, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch e.Name.Local {
+		case CHANGE_CREATE, CHANGE_MODIFY, CHANGE_DELETE:
+			d.Action = e.Name.Local
+		case "osm", "osmChange":
+			for _, attr := range e.Attr {
+				switch attr.Name.Local {
+				case "version":
+					d.Version = attr.Value
+				case "generator":
+					d.Generator = attr.Value
+				case "timestamp":
+					v, err := time.Parse(time.RFC3339, attr.Value)
+					if err != nil {
+						return nil, errors.Wrap(err, "Error parsing osm timestamp")
+					}
+					d.Timestamp = &v
+				}
+			}
+		case "bounds":
+			b, err := UnmarshalBounds(d.decoder, e)
+			if err != nil {
+				return nil, err
+			}
+			d.Bounds = &b
+		case "node":
+			n, user_id, user_name, tags, err := UnmarshalNode(d.decoder, e, d.input)
+			if err != nil {
+				return nil, err
+			}
+			n.UserId = user_id
+			n.UserName = user_name
+			n.SetTags(tags)
+			return n, nil
+		case "way":
+			w, user_id, user_name, tags, err := UnmarshalWay(d.decoder, e, d.input)
+			if err != nil {
+				return nil, err
+			}
+			w.UserId = user_id
+			w.UserName = user_name
+			w.SetTags(tags)
+			return w, nil
+		case "relation":
+			r, user_id, user_name, tags, err := UnmarshalRelation(d.decoder, e, d.input)
+			if err != nil {
+				return nil, err
+			}
+			r.UserId = user_id
+			r.UserName = user_name
+			r.SetTags(tags)
+			return r, nil
+		}
+	}
+}
+
+// Deleted returns true if the element most recently returned by Decode is in a delete block of an osmChange stream.
+func (d *XMLDecoder) Deleted() bool {
+	return d.Action == CHANGE_DELETE
+}
@@ -0,0 +1,116 @@
+package osm
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// XMLEncoder encodes OSM elements to an OSM XML (.osm) stream.
+// Tags and user names are resolved through the planet and the output's drop flags are applied.
+type XMLEncoder struct {
+	writer  io.Writer
+	encoder *xml.Encoder
+	planet  *Planet
+	output  *Output
+	token   xml.StartElement // the osm start element
+}
+
+// NewXMLEncoder returns a new XMLEncoder writing to w.  If the output is pretty, then indents the elements.
+func NewXMLEncoder(w io.Writer, planet *Planet, output *Output) *XMLEncoder {
+	encoder := xml.NewEncoder(w)
+	if output.Pretty {
+		encoder.Indent("", "    ")
+	}
+	return &XMLEncoder{
+		writer:  w,
+		encoder: encoder,
+		planet:  planet,
+		output:  output,
+	}
+}
+
+// WriteHeader writes the XML declaration, the osm start element, and the bounds element.
+func (e *XMLEncoder) WriteHeader() error {
+	_, err := io.WriteString(e.writer, xml.Header)
+	if err != nil {
+		return errors.Wrap(err, "Error writing xml header.")
+	}
+
+	attrs := make([]xml.Attr, 0)
+	if !e.output.DropVersion {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "version"}, Value: e.planet.Version})
+	}
+	if !e.output.DropTimestamp {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: "", Local: "timestamp"}, Value: e.planet.Timestamp.Format(time.RFC3339)})
+	}
+	e.token = xml.StartElement{
+		Name: xml.Name{Space: "", Local: "osm"},
+		Attr: attrs,
+	}
+	err = e.encoder.EncodeToken(e.token)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding osm start element.")
+	}
+
+	b := e.planet.Bounds
+	token := xml.StartElement{
+		Name: xml.Name{Space: "", Local: "bounds"},
+		Attr: []xml.Attr{
+			xml.Attr{Name: xml.Name{Space: "", Local: "minlon"}, Value: strconv.FormatFloat(b.MinimumLongitude, 'f', 6, 64)},
+			xml.Attr{Name: xml.Name{Space: "", Local: "minlat"}, Value: strconv.FormatFloat(b.MinimumLatitude, 'f', 6, 64)},
+			xml.Attr{Name: xml.Name{Space: "", Local: "maxlon"}, Value: strconv.FormatFloat(b.MaximumLongitude, 'f', 6, 64)},
+			xml.Attr{Name: xml.Name{Space: "", Local: "maxlat"}, Value: strconv.FormatFloat(b.MaximumLatitude, 'f', 6, 64)},
+		},
+	}
+	err = e.encoder.EncodeToken(token)
+	if err != nil {
+		return errors.Wrap(err, "Error encoding bounds element.")
+	}
+	err = e.encoder.EncodeToken(token.End())
+	if err != nil {
+		return errors.Wrap(err, "Error encoding bounds end element.")
+	}
+
+	return nil
+}
+
+// EncodeNode writes the node element.
+func (e *XMLEncoder) EncodeNode(n *Node) error {
+	return MarshalNode(e.encoder, e.planet, e.output, n)
+}
+
+// EncodeWay writes the way element.
+func (e *XMLEncoder) EncodeWay(w *Way) error {
+	return MarshalWay(e.encoder, e.planet, e.output, w)
+}
+
+// EncodeRelation writes the relation element.
+func (e *XMLEncoder) EncodeRelation(r *Relation) error {
+	return MarshalRelation(e.encoder, e.planet, e.output, r)
+}
+
+// Close writes the osm end element and flushes the encoder.  Does not close the underlying writer.
+func (e *XMLEncoder) Close() error {
+	err := e.encoder.EncodeToken(e.token.End())
+	if err != nil {
+		return errors.Wrap(err, "Error encoding osm end element.")
+	}
+
+	err = e.encoder.Flush()
+	if err != nil {
+		return errors.Wrap(err, "Error flushing buffered xml.")
+	}
+
+	_, err = io.WriteString(e.writer, "\n")
+	if err != nil {
+		return errors.Wrap(err, "Error writing xml.")
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package osm
+
+// versionChanged returns true if both versions are set and differ.
+// Returns false if either version is 0, e.g., because it was dropped, since the versions cannot be compared.
+func versionChanged(a *Element, b *Element) bool {
+	return a.Version != 0 && b.Version != 0 && a.Version != b.Version
+}
+
+// tagsChanged returns true if the elements do not have the same set of tags.
+// The tags are compared by their index in a shared TagsCache, so the order of the tags does not matter.
+func tagsChanged(a *TaggedElement, b *TaggedElement) bool {
+	if len(a.TagsIndex) != len(b.TagsIndex) {
+		return true
+	}
+	set := make(map[uint32]struct{}, len(a.TagsIndex))
+	for _, x := range a.TagsIndex {
+		set[x] = struct{}{}
+	}
+	for _, x := range b.TagsIndex {
+		if _, ok := set[x]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeChanged returns true if the node has a different version or, if the versions are equal or not set,
+// different coordinates or tags.
+func nodeChanged(a *Node, b *Node) bool {
+	if versionChanged(&a.Element, &b.Element) {
+		return true
+	}
+	return a.Longitude != b.Longitude || a.Latitude != b.Latitude || tagsChanged(&a.TaggedElement, &b.TaggedElement)
+}
+
+// wayChanged returns true if the way has a different version or, if the versions are equal or not set,
+// different node references or tags.
+func wayChanged(a *Way, b *Way) bool {
+	if versionChanged(&a.Element, &b.Element) {
+		return true
+	}
+	if len(a.NodeReferences) != len(b.NodeReferences) {
+		return true
+	}
+	for i := range a.NodeReferences {
+		if a.NodeReferences[i].Reference != b.NodeReferences[i].Reference {
+			return true
+		}
+	}
+	return tagsChanged(&a.TaggedElement, &b.TaggedElement)
+}
+
+// relationChanged returns true if the relation has a different version or, if the versions are equal or not set,
+// different members or tags.
+func relationChanged(a *Relation, b *Relation) bool {
+	if versionChanged(&a.Element, &b.Element) {
+		return true
+	}
+	if len(a.Members) != len(b.Members) {
+		return true
+	}
+	for i := range a.Members {
+		if a.Members[i] != b.Members[i] {
+			return true
+		}
+	}
+	return tagsChanged(&a.TaggedElement, &b.TaggedElement)
+}
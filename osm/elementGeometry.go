@@ -0,0 +1,41 @@
+package osm
+
+// elementGeometry returns the geometry of a *Node, *Way, or *Relation as a GeoJSON geometry, or nil if the element has no geometry.
+// The geometry is built directly from the coordinates of the nodes, the same as WalkGDALLayer.
+// Nodes are Points and ways are LineStrings or Polygons by the area rules.  See WayToFeature.
+// Multipolygon, boundary, and route relations are assembled into MultiPolygons and MultiLineStrings.  See RelationToFeature.
+// Other relations have no geometry.
+// Returns an *IncompleteWay as the error if a way references nodes that are missing from the planet,
+// and an *IncompleteRelation as the error if a relation cannot be assembled.
+func (p *Planet) elementGeometry(element interface{}, rules AreaRules) (*geojsonObject, error) {
+	switch e := element.(type) {
+	case *Node:
+		return newGeojsonGeometry("Point", []float64{e.Longitude, e.Latitude})
+	case *Way:
+		coordinates, err := p.WayCoordinates(e)
+		if err != nil {
+			return nil, err
+		}
+		if e.IsClosed() && rules.IsArea(p.Tags.Map(e.TagsIndex)) {
+			return newGeojsonGeometry("Polygon", [][][]float64{coordinates})
+		}
+		return newGeojsonGeometry("LineString", coordinates)
+	case *Relation:
+		switch p.RelationType(e) {
+		case "multipolygon", "boundary":
+			coordinates, err := p.AssembleMultiPolygon(e)
+			if err != nil {
+				return nil, err
+			}
+			return newGeojsonGeometry("MultiPolygon", coordinates)
+		case "route", "route_master":
+			coordinates, _, err := p.AssembleRoute(e)
+			if err != nil {
+				return nil, err
+			}
+			return newGeojsonGeometry("MultiLineString", coordinates)
+		}
+		return nil, nil
+	}
+	return nil, nil
+}
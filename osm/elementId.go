@@ -0,0 +1,14 @@
+package osm
+
+// elementId returns the id of a *Node, *Way, or *Relation, or 0 if the element is of another type.
+func elementId(element interface{}) uint64 {
+	switch e := element.(type) {
+	case *Node:
+		return e.Id
+	case *Way:
+		return e.Id
+	case *Relation:
+		return e.Id
+	}
+	return 0
+}
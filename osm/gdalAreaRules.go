@@ -0,0 +1,11 @@
+package osm
+
+// gdalAreaRules returns the area rules for the keys of closed_ways_are_polygons in an osmconf.ini file.
+// Any value of the keys makes a closed way an area, except as overridden by the area tag.
+func gdalAreaRules(keys []string) AreaRules {
+	rules := make(AreaRules, 0, len(keys))
+	for _, k := range keys {
+		rules = append(rules, AreaRule{Key: k})
+	}
+	return rules
+}
@@ -0,0 +1,16 @@
+package osm
+
+import (
+	"strings"
+)
+
+// gdalKeyMatches returns true if the tag key is in the list of keys from an osmconf.ini file.
+// A key in the list that ends with a colon matches every key with that prefix, e.g., openGeoDB: matches openGeoDB:name.
+func gdalKeyMatches(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key || (strings.HasSuffix(k, ":") && strings.HasPrefix(key, k)) {
+			return true
+		}
+	}
+	return false
+}
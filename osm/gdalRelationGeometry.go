@@ -0,0 +1,74 @@
+package osm
+
+// gdalRelationGeometry returns the geometry of a relation in the multilinestrings or other_relations layers of GDAL.
+// If collection is false, then the member ways are the lines of a MultiLineString, in the order of the members.
+// If collection is true, then the member nodes and ways are the Points and LineStrings of a GeometryCollection, and a relation without member nodes or ways has no geometry.
+// Member relations are skipped.
+// Returns an *IncompleteRelation as the error if member nodes or ways are missing from the planet, or if a MultiLineString has no member ways.
+func (p *Planet) gdalRelationGeometry(r *Relation, collection bool) (*geojsonObject, error) {
+
+	ir := &IncompleteRelation{Id: r.Id, Reason: "members are missing from the planet"}
+	lines := make([][][]float64, 0)
+	geometries := make([]*geojsonObject, 0)
+	for _, m := range r.Members {
+		switch m.Type {
+		case "node":
+			if !collection {
+				continue
+			}
+			i, ok := p.nodesIndex[m.Reference]
+			if !ok {
+				ir.MissingNodes = append(ir.MissingNodes, m.Reference)
+				continue
+			}
+			n := p.Nodes[i]
+			g, err := newGeojsonGeometry("Point", []float64{n.Longitude, n.Latitude})
+			if err != nil {
+				return nil, err
+			}
+			geometries = append(geometries, g)
+		case "way":
+			i, ok := p.waysIndex[m.Reference]
+			if !ok {
+				ir.MissingWays = append(ir.MissingWays, m.Reference)
+				continue
+			}
+			w := p.Ways[i]
+			coordinates := make([][]float64, 0, len(w.NodeReferences))
+			for _, nr := range w.NodeReferences {
+				j, ok := p.nodesIndex[nr.Reference]
+				if !ok {
+					ir.MissingNodes = append(ir.MissingNodes, nr.Reference)
+					continue
+				}
+				coordinates = append(coordinates, []float64{p.Nodes[j].Longitude, p.Nodes[j].Latitude})
+			}
+			if collection {
+				g, err := newGeojsonGeometry("LineString", coordinates)
+				if err != nil {
+					return nil, err
+				}
+				geometries = append(geometries, g)
+			} else {
+				lines = append(lines, coordinates)
+			}
+		}
+	}
+
+	if len(ir.MissingWays) > 0 || len(ir.MissingNodes) > 0 {
+		return nil, ir
+	}
+
+	if collection {
+		if len(geometries) == 0 {
+			return nil, nil
+		}
+		return &geojsonObject{Type: "GeometryCollection", Geometries: geometries}, nil
+	}
+
+	if len(lines) == 0 {
+		return nil, &IncompleteRelation{Id: r.Id, Reason: "relation has no member ways"}
+	}
+
+	return newGeojsonGeometry("MultiLineString", lines)
+}
@@ -0,0 +1,93 @@
+package osm
+
+import (
+	"math"
+)
+
+// geojsonCentroid returns the centroid of the GeoJSON geometry as [lon, lat], computed in degrees on the plane.
+// The centroid of polygons is weighted by area, with holes subtracted, and the centroid of lines is weighted by length.
+// If the geometry has no area or length, then returns the mean of its positions.  Returns nil if the geometry is empty.
+func geojsonCentroid(o *geojsonObject) ([]float64, error) {
+
+	points, lines, polygons, err := o.parts()
+	if err != nil {
+		return nil, err
+	}
+
+	area := 0.0
+	x := 0.0
+	y := 0.0
+	for _, polygon := range polygons {
+		for i, ring := range polygon {
+			a := 0.0
+			cx := 0.0
+			cy := 0.0
+			for j := 0; j+1 < len(ring); j++ {
+				if len(ring[j]) < 2 || len(ring[j+1]) < 2 {
+					continue
+				}
+				cross := ring[j][0]*ring[j+1][1] - ring[j+1][0]*ring[j][1]
+				a += cross
+				cx += (ring[j][0] + ring[j+1][0]) * cross
+				cy += (ring[j][1] + ring[j+1][1]) * cross
+			}
+			if a == 0 {
+				continue
+			}
+			// cx / (3 * a) is the centroid of the ring, whichever its winding order.
+			weight := math.Abs(a) / 2
+			if i > 0 {
+				weight = -weight
+			}
+			area += weight
+			x += weight * cx / (3 * a)
+			y += weight * cy / (3 * a)
+		}
+	}
+	if area > 0 {
+		return []float64{x / area, y / area}, nil
+	}
+
+	length := 0.0
+	x = 0.0
+	y = 0.0
+	for _, line := range lines {
+		for j := 0; j+1 < len(line); j++ {
+			if len(line[j]) < 2 || len(line[j+1]) < 2 {
+				continue
+			}
+			l := math.Hypot(line[j+1][0]-line[j][0], line[j+1][1]-line[j][1])
+			length += l
+			x += l * (line[j][0] + line[j+1][0]) / 2
+			y += l * (line[j][1] + line[j+1][1]) / 2
+		}
+	}
+	if length > 0 {
+		return []float64{x / length, y / length}, nil
+	}
+
+	positions := append(make([][]float64, 0), points...)
+	for _, line := range lines {
+		positions = append(positions, line...)
+	}
+	for _, polygon := range polygons {
+		for _, ring := range polygon {
+			positions = append(positions, ring...)
+		}
+	}
+	count := 0.0
+	x = 0.0
+	y = 0.0
+	for _, position := range positions {
+		if len(position) < 2 {
+			continue
+		}
+		count += 1
+		x += position[0]
+		y += position[1]
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	return []float64{x / count, y / count}, nil
+}
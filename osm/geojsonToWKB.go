@@ -0,0 +1,154 @@
+package osm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// WKB_SRID_FLAG is the flag in the geometry type of Extended Well-Known Binary (EWKB) that marks that an SRID follows the type.
+const WKB_SRID_FLAG = uint32(0x20000000)
+
+// wkbWriter writes little-endian Well-Known Binary to a buffer.
+type wkbWriter struct {
+	buf     *bytes.Buffer
+	project func(x float64, y float64) (float64, float64) // projects each position, if not nil
+}
+
+// header writes the byte order, the geometry type, and the SRID if greater than zero.
+func (w wkbWriter) header(t uint32, srid int) {
+	w.buf.WriteByte(1) // little endian
+	if srid > 0 {
+		w.uint32(t | WKB_SRID_FLAG)
+		w.uint32(uint32(srid))
+		return
+	}
+	w.uint32(t)
+}
+
+func (w wkbWriter) uint32(v uint32) {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, v)
+	w.buf.Write(b)
+}
+
+func (w wkbWriter) float64(v float64) {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint64(b, math.Float64bits(v))
+	w.buf.Write(b)
+}
+
+// position writes the x and y of the position.  An empty position is written as NaN, NaN.
+func (w wkbWriter) position(position []float64) {
+	if len(position) < 2 {
+		w.float64(math.NaN())
+		w.float64(math.NaN())
+		return
+	}
+	if w.project != nil {
+		x, y := w.project(position[0], position[1])
+		w.float64(x)
+		w.float64(y)
+		return
+	}
+	w.float64(position[0])
+	w.float64(position[1])
+}
+
+// positions writes the number of positions and then each position.
+func (w wkbWriter) positions(positions [][]float64) {
+	w.uint32(uint32(len(positions)))
+	for _, position := range positions {
+		w.position(position)
+	}
+}
+
+// polygon writes the number of rings and then each ring.
+func (w wkbWriter) polygon(polygon [][][]float64) {
+	w.uint32(uint32(len(polygon)))
+	for _, ring := range polygon {
+		w.positions(ring)
+	}
+}
+
+// geojsonToWKB returns the GeoJSON geometry as little-endian Well-Known Binary.
+// If srid is greater than zero, then the geometry is returned as Extended Well-Known Binary (EWKB) with the SRID, as read by PostGIS.
+// If srid is 3857, then the longitudes and latitudes are projected to Web Mercator.  See projectWebMercator.
+//	- https://en.wikipedia.org/wiki/Well-known_text_representation_of_geometry#Well-known_binary
+func geojsonToWKB(o *geojsonObject, srid int) ([]byte, error) {
+	w := wkbWriter{buf: &bytes.Buffer{}}
+	if srid == 3857 {
+		w.project = projectWebMercator
+	}
+	err := writeWKB(w, o, srid)
+	if err != nil {
+		return nil, err
+	}
+	return w.buf.Bytes(), nil
+}
+
+// writeWKB writes the GeoJSON geometry as Well-Known Binary.  Only the outermost geometry is written with the SRID.
+func writeWKB(w wkbWriter, o *geojsonObject, srid int) error {
+
+	if o.Type == "GeometryCollection" {
+		w.header(7, srid)
+		w.uint32(uint32(len(o.Geometries)))
+		for _, child := range o.Geometries {
+			err := writeWKB(w, child, 0)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	points, lines, polygons, err := o.parts()
+	if err != nil {
+		return err
+	}
+
+	switch o.Type {
+	case "Point":
+		w.header(1, srid)
+		w.position(points[0])
+		return nil
+	case "MultiPoint":
+		w.header(4, srid)
+		w.uint32(uint32(len(points)))
+		for _, point := range points {
+			w.header(1, 0)
+			w.position(point)
+		}
+		return nil
+	case "LineString":
+		w.header(2, srid)
+		w.positions(lines[0])
+		return nil
+	case "MultiLineString":
+		w.header(5, srid)
+		w.uint32(uint32(len(lines)))
+		for _, line := range lines {
+			w.header(2, 0)
+			w.positions(line)
+		}
+		return nil
+	case "Polygon":
+		w.header(3, srid)
+		w.polygon(polygons[0])
+		return nil
+	case "MultiPolygon":
+		w.header(6, srid)
+		w.uint32(uint32(len(polygons)))
+		for _, polygon := range polygons {
+			w.header(3, 0)
+			w.polygon(polygon)
+		}
+		return nil
+	}
+
+	return errors.New("GeoJSON type " + o.Type + " is not a geometry.")
+}
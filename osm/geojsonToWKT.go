@@ -0,0 +1,108 @@
+package osm
+
+import (
+	"strconv"
+	"strings"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// wktPosition returns the position as "x y" for WKT.
+func wktPosition(position []float64) string {
+	if len(position) < 2 {
+		return ""
+	}
+	return strconv.FormatFloat(position[0], 'f', -1, 64) + " " + strconv.FormatFloat(position[1], 'f', -1, 64)
+}
+
+// wktPositions returns the positions as "(x y, x y, ...)" for WKT.
+func wktPositions(positions [][]float64) string {
+	s := make([]string, 0, len(positions))
+	for _, position := range positions {
+		s = append(s, wktPosition(position))
+	}
+	return "(" + strings.Join(s, ", ") + ")"
+}
+
+// wktPolygon returns the rings of the polygon as "((x y, ...), (x y, ...))" for WKT.
+func wktPolygon(polygon [][][]float64) string {
+	s := make([]string, 0, len(polygon))
+	for _, ring := range polygon {
+		s = append(s, wktPositions(ring))
+	}
+	return "(" + strings.Join(s, ", ") + ")"
+}
+
+// geojsonToWKT returns the GeoJSON geometry as Well-Known Text, e.g., POINT(-77.03 38.9).
+//	- https://en.wikipedia.org/wiki/Well-known_text_representation_of_geometry
+func geojsonToWKT(o *geojsonObject) (string, error) {
+
+	if o.Type == "GeometryCollection" {
+		if len(o.Geometries) == 0 {
+			return "GEOMETRYCOLLECTION EMPTY", nil
+		}
+		children := make([]string, 0, len(o.Geometries))
+		for _, child := range o.Geometries {
+			wkt, err := geojsonToWKT(child)
+			if err != nil {
+				return "", err
+			}
+			children = append(children, wkt)
+		}
+		return "GEOMETRYCOLLECTION(" + strings.Join(children, ", ") + ")", nil
+	}
+
+	points, lines, polygons, err := o.parts()
+	if err != nil {
+		return "", err
+	}
+
+	switch o.Type {
+	case "Point":
+		if len(points[0]) < 2 {
+			return "POINT EMPTY", nil
+		}
+		return "POINT(" + wktPosition(points[0]) + ")", nil
+	case "MultiPoint":
+		if len(points) == 0 {
+			return "MULTIPOINT EMPTY", nil
+		}
+		s := make([]string, 0, len(points))
+		for _, point := range points {
+			s = append(s, "("+wktPosition(point)+")")
+		}
+		return "MULTIPOINT(" + strings.Join(s, ", ") + ")", nil
+	case "LineString":
+		if len(lines[0]) == 0 {
+			return "LINESTRING EMPTY", nil
+		}
+		return "LINESTRING" + wktPositions(lines[0]), nil
+	case "MultiLineString":
+		if len(lines) == 0 {
+			return "MULTILINESTRING EMPTY", nil
+		}
+		s := make([]string, 0, len(lines))
+		for _, line := range lines {
+			s = append(s, wktPositions(line))
+		}
+		return "MULTILINESTRING(" + strings.Join(s, ", ") + ")", nil
+	case "Polygon":
+		if len(polygons[0]) == 0 {
+			return "POLYGON EMPTY", nil
+		}
+		return "POLYGON" + wktPolygon(polygons[0]), nil
+	case "MultiPolygon":
+		if len(polygons) == 0 {
+			return "MULTIPOLYGON EMPTY", nil
+		}
+		s := make([]string, 0, len(polygons))
+		for _, polygon := range polygons {
+			s = append(s, wktPolygon(polygon))
+		}
+		return "MULTIPOLYGON(" + strings.Join(s, ", ") + ")", nil
+	}
+
+	return "", errors.New("GeoJSON type " + o.Type + " is not a geometry.")
+}
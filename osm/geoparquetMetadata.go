@@ -0,0 +1,73 @@
+package osm
+
+import (
+	"math"
+)
+
+// geoparquetColumn is the GeoParquet metadata of a geometry column.
+type geoparquetColumn struct {
+	Encoding      string    `json:"encoding"`
+	GeometryTypes []string  `json:"geometry_types"`
+	Bbox          []float64 `json:"bbox,omitempty"` // [minx, miny, maxx, maxy]
+}
+
+// geoparquetMetadata is the GeoParquet metadata written to the "geo" key of the metadata of a Parquet file.
+// The CRS of the columns is omitted, so it defaults to OGC:CRS84, i.e., longitude and latitude on WGS 84.
+//	- https://geoparquet.org/releases/v1.0.0/
+type geoparquetMetadata struct {
+	Version       string                       `json:"version"`
+	PrimaryColumn string                       `json:"primary_column"`
+	Columns       map[string]*geoparquetColumn `json:"columns"`
+}
+
+// newGeoparquetMetadata returns the metadata of a Parquet file with a single WKB geometry column.
+func newGeoparquetMetadata(column string) *geoparquetMetadata {
+	return &geoparquetMetadata{
+		Version:       "1.0.0",
+		PrimaryColumn: column,
+		Columns: map[string]*geoparquetColumn{
+			column: &geoparquetColumn{
+				Encoding:      "WKB",
+				GeometryTypes: make([]string, 0),
+			},
+		},
+	}
+}
+
+// extend adds the type of the geometry to the geometry types of the column and extends the bbox of the column to cover the geometry.
+func (c *geoparquetColumn) extend(o *geojsonObject) error {
+
+	if !stringSliceContains(c.GeometryTypes, o.Type) {
+		c.GeometryTypes = append(c.GeometryTypes, o.Type)
+	}
+
+	points, lines, polygons, err := o.parts()
+	if err != nil {
+		return err
+	}
+	positions := points
+	for _, line := range lines {
+		positions = append(positions, line...)
+	}
+	for _, polygon := range polygons {
+		for _, ring := range polygon {
+			positions = append(positions, ring...)
+		}
+	}
+
+	for _, position := range positions {
+		if len(position) < 2 {
+			continue
+		}
+		if len(c.Bbox) == 0 {
+			c.Bbox = []float64{position[0], position[1], position[0], position[1]}
+			continue
+		}
+		c.Bbox[0] = math.Min(c.Bbox[0], position[0])
+		c.Bbox[1] = math.Min(c.Bbox[1], position[1])
+		c.Bbox[2] = math.Max(c.Bbox[2], position[0])
+		c.Bbox[3] = math.Max(c.Bbox[3], position[1])
+	}
+
+	return nil
+}
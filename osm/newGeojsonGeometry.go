@@ -0,0 +1,18 @@
+package osm
+
+import (
+	"encoding/json"
+)
+
+import (
+	"github.com/pkg/errors"
+)
+
+// newGeojsonGeometry returns a GeoJSON geometry of the given type with the coordinates.
+func newGeojsonGeometry(t string, coordinates interface{}) (*geojsonObject, error) {
+	b, err := json.Marshal(coordinates)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error marshalling coordinates of GeoJSON "+t)
+	}
+	return &geojsonObject{Type: t, Coordinates: b}, nil
+}
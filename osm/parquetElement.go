@@ -0,0 +1,28 @@
+package osm
+
+// parquetMember is a member of a relation in a Parquet output.
+type parquetMember struct {
+	Type      string `parquet:"name=type, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Reference int64  `parquet:"name=ref, type=INT64"`
+	Role      string `parquet:"name=role, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// parquetElement is a row of a Parquet output, which is a node, way, or relation.
+// The columns are named the same as the parquet tags of Element, Node, and NodeReference.
+// Ids are signed, since Spark and most other readers do not support unsigned 64-bit integers.
+// Attributes dropped by the output are null.
+type parquetElement struct {
+	Id        int64             `parquet:"name=id, type=INT64"`
+	Type      string            `parquet:"name=type, type=BYTE_ARRAY, convertedtype=UTF8"`
+	Version   *int32            `parquet:"name=version, type=INT32, repetitiontype=OPTIONAL"`
+	Timestamp *int64            `parquet:"name=timestamp, type=INT64, convertedtype=TIMESTAMP_MICROS, repetitiontype=OPTIONAL"`
+	Changeset *int64            `parquet:"name=changeset, type=INT64, repetitiontype=OPTIONAL"`
+	UserId    *int64            `parquet:"name=uid, type=INT64, repetitiontype=OPTIONAL"`
+	UserName  *string           `parquet:"name=user, type=BYTE_ARRAY, convertedtype=UTF8, repetitiontype=OPTIONAL"`
+	Tags      map[string]string `parquet:"name=tags, type=MAP, convertedtype=MAP, keytype=BYTE_ARRAY, keyconvertedtype=UTF8, valuetype=BYTE_ARRAY, valueconvertedtype=UTF8"`
+	Longitude *float64          `parquet:"name=lon, type=DOUBLE, repetitiontype=OPTIONAL"`
+	Latitude  *float64          `parquet:"name=lat, type=DOUBLE, repetitiontype=OPTIONAL"`
+	Nodes     []int64           `parquet:"name=nodes, type=LIST, valuetype=INT64"`
+	Members   []parquetMember   `parquet:"name=members, type=LIST"`
+	Geometry  *string           `parquet:"name=geometry, type=BYTE_ARRAY, repetitiontype=OPTIONAL"` // the geometry as Well-Known Binary
+}
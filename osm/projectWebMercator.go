@@ -0,0 +1,17 @@
+package osm
+
+import (
+	"math"
+)
+
+// WEB_MERCATOR_RADIUS is the radius of the sphere of the Web Mercator projection (EPSG:3857) in meters, which is the semi-major axis of WGS 84.
+const WEB_MERCATOR_RADIUS = 6378137.0
+
+// projectWebMercator projects a longitude and latitude to Web Mercator (EPSG:3857) coordinates in meters.
+// Latitudes beyond MAX_MERCATOR_LATITUDE are clamped to the limits of the projection.
+func projectWebMercator(lon float64, lat float64) (float64, float64) {
+	lat_rad := math.Max(-MAX_MERCATOR_LATITUDE, math.Min(MAX_MERCATOR_LATITUDE, lat)) * math.Pi / 180.0
+	x := WEB_MERCATOR_RADIUS * lon * math.Pi / 180.0
+	y := WEB_MERCATOR_RADIUS * math.Log(math.Tan(math.Pi/4.0+lat_rad/2.0))
+	return x, y
+}
@@ -0,0 +1,16 @@
+package osm
+
+import (
+	"github.com/dhconnelly/rtreego"
+)
+
+// rtree returns the planet's R-tree, building it first if the planet changed since it was last built.
+// Building the R-tree is guarded by a mutex, so the planet can be queried from multiple goroutines.
+func (p *Planet) rtree() *rtreego.Rtree {
+	p.rtreeMutex.Lock()
+	defer p.rtreeMutex.Unlock()
+	if !p.rtreeValid {
+		p.buildRtree()
+	}
+	return p.Rtree
+}
@@ -0,0 +1,82 @@
+package osm
+
+import (
+	"github.com/spatialcurrent/go-dfl/dfl"
+)
+
+// selectChangeAction imports the tags of the elements created or modified by the action, like UnmarshalPlanet imports the elements of an input,
+// and returns an action with only the elements that pass the input filter.
+// As in AddElementsToPlanet, members of kept relations and nodes of kept ways are always kept.
+// Elements already in the planet are also kept, so a change never leaves a way or relation in the planet without its members.
+// Deletions are returned as is.
+// Returns an error if any.
+func (p *Planet) selectChangeAction(input *Input, a *ChangeAction, dfl_cache *dfl.Cache) (*ChangeAction, error) {
+
+	if a.Type == CHANGE_DELETE {
+		return a, nil
+	}
+
+	for _, n := range a.Nodes {
+		p.ImportTaggedElement(input, &n.TaggedElement)
+	}
+	for _, w := range a.Ways {
+		p.ImportTaggedElement(input, &w.TaggedElement)
+	}
+	for _, r := range a.Relations {
+		p.ImportTaggedElement(input, &r.TaggedElement)
+	}
+
+	if input.Filter == nil {
+		return a, nil
+	}
+
+	relations, set_relation_nodes, set_relation_ways, err := SelectRelations(a.Relations, func(r *Relation) (bool, error) {
+		if _, ok := p.relationsIndex[r.Id]; ok {
+			return true, nil
+		}
+		return KeepRelation(p, input.Filter, r, dfl_cache)
+	})
+	if err != nil {
+		return a, err
+	}
+
+	ways := make([]*Way, 0, len(a.Ways))
+	set_way_nodes := NewUInt64Set()
+	for _, w := range a.Ways {
+		_, keep := p.waysIndex[w.Id]
+		if !keep {
+			keep = set_relation_ways.Contains(w.Id)
+		}
+		if !keep {
+			keep, err = KeepWay(p, input.Filter, w, dfl_cache)
+			if err != nil {
+				return a, err
+			}
+		}
+		if keep {
+			ways = append(ways, w)
+			for _, nr := range w.NodeReferences {
+				set_way_nodes.Add(nr.Reference)
+			}
+		}
+	}
+
+	nodes := make([]*Node, 0, len(a.Nodes))
+	for _, n := range a.Nodes {
+		_, keep := p.nodesIndex[n.Id]
+		if !keep {
+			keep = set_way_nodes.Contains(n.Id) || set_relation_nodes.Contains(n.Id)
+		}
+		if !keep {
+			keep, err = KeepNode(p, input.Filter, n, dfl_cache)
+			if err != nil {
+				return a, err
+			}
+		}
+		if keep {
+			nodes = append(nodes, n)
+		}
+	}
+
+	return &ChangeAction{Type: a.Type, Nodes: nodes, Ways: ways, Relations: relations}, nil
+}
@@ -0,0 +1,7 @@
+package osm
+
+// sqlColumn is a column of a table of a sql output.
+type sqlColumn struct {
+	Name string // the name of the column, which is quoted when written
+	Type string // the PostgreSQL type of the column, e.g., bigint, text, or geometry(Point, 4326)
+}
@@ -0,0 +1,18 @@
+package osm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// sqlCopyEscaper escapes the backslashes, newlines, carriage returns, and tabs in a value of COPY in text format.
+var sqlCopyEscaper = strings.NewReplacer("\\", "\\\\", "\n", "\\n", "\r", "\\r", "\t", "\\t")
+
+// sqlCopyValue returns the value as a column of a row of COPY in text format.  Nil is written as \N, which is NULL.
+//	- https://www.postgresql.org/docs/current/sql-copy.html#id-1.9.3.55.9.2
+func sqlCopyValue(v interface{}) string {
+	if v == nil {
+		return "\\N"
+	}
+	return sqlCopyEscaper.Replace(fmt.Sprint(v))
+}
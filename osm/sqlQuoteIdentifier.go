@@ -0,0 +1,11 @@
+package osm
+
+import (
+	"strings"
+)
+
+// sqlQuoteIdentifier returns the name as a quoted PostgreSQL identifier, e.g., "addr:street", with any double quotes doubled.
+//	- https://www.postgresql.org/docs/current/sql-syntax-lexical.html#SQL-SYNTAX-IDENTIFIERS
+func sqlQuoteIdentifier(name string) string {
+	return "\"" + strings.Replace(name, "\"", "\"\"", -1) + "\""
+}
@@ -0,0 +1,10 @@
+package osm
+
+func stringSliceContains(s []string, i string) bool {
+	for _, x := range s {
+		if x == i {
+			return true
+		}
+	}
+	return false
+}
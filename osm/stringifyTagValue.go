@@ -0,0 +1,22 @@
+package osm
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// stringifyTagValue returns the value of a tag or feature property as a string.
+// Strings are returned as is, nil as a blank string, and other values as JSON.
+func stringifyTagValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
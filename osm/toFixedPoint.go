@@ -0,0 +1,10 @@
+package osm
+
+// toFixedPoint multiplies x by scale and rounds the result half away from zero to an int64.
+// Used to encode coordinates and other floating point values as integers.
+func toFixedPoint(x float64, scale float64) int64 {
+	if x < 0 {
+		return int64(x*scale - 0.5)
+	}
+	return int64(x*scale + 0.5)
+}